@@ -0,0 +1,25 @@
+package config
+
+import "github.com/spf13/viper"
+
+// DefaultAnkiConnectURL is where the AnkiConnect add-on listens by default.
+const DefaultAnkiConnectURL = "http://127.0.0.1:8765"
+
+// AnkiConnectConfig holds the `AnkiConnect` config section used by
+// `flashcard-cli sync`.
+type AnkiConnectConfig struct {
+	URL string // AnkiConnect endpoint; DefaultAnkiConnectURL when unset
+	Key string // API key, when the add-on is configured to require one
+}
+
+// GetAnkiConnectConfig reads the optional `AnkiConnect` config section.
+func GetAnkiConnectConfig() AnkiConnectConfig {
+	url := viper.GetString("AnkiConnect.url")
+	if url == "" {
+		url = DefaultAnkiConnectURL
+	}
+	return AnkiConnectConfig{
+		URL: url,
+		Key: viper.GetString("AnkiConnect.key"),
+	}
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// TestGetAnkiConnectConfig: an unset URL falls back to the add-on's default
+// endpoint; configured values are read as given.
+func TestGetAnkiConnectConfig(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	if got := GetAnkiConnectConfig(); got.URL != DefaultAnkiConnectURL || got.Key != "" {
+		t.Errorf("GetAnkiConnectConfig() with no config = %+v, want default URL, no key", got)
+	}
+
+	viper.Set("AnkiConnect.url", "http://anki.local:8765")
+	viper.Set("AnkiConnect.key", "secret")
+	if got := GetAnkiConnectConfig(); got.URL != "http://anki.local:8765" || got.Key != "secret" {
+		t.Errorf("GetAnkiConnectConfig() = %+v, want configured URL and key", got)
+	}
+}
@@ -71,7 +71,7 @@ func TestGetToolPathMissing(t *testing.T) {
 }
 
 // TestGetToolPathNotConfigured: an unset key errors before any lookup, so the
-// no-config path (relied on by ebook's locateTypst fallback) is preserved.
+// no-config path (relied on by ebook's LocateTypst fallback) is preserved.
 func TestGetToolPathNotConfigured(t *testing.T) {
 	viper.Reset()
 	t.Cleanup(viper.Reset)
@@ -1,59 +1,80 @@
 package ebook
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
 
+	"github.com/dpurge/cli-tools/pkg/config"
 	"github.com/spf13/cobra"
 )
 
 var _formats []string
+var _watch bool
+var _noCache bool
 
 var buildCmd = &cobra.Command{
 	Use:   "build",
 	Short: "Build ebook project",
 	Long:  "Build long description",
 	Run: func(cmd *cobra.Command, args []string) {
+		var cache *textCache
+		if !_noCache {
+			cache = newTextCache(_project)
+		}
+
 		// Resolve every requested format to its Exporter, and reject any
 		// unknown format, BEFORE reading the project or exporting anything
 		// (SPECS §9/FR-8): a typo in a later --format must not leave a
 		// partially-built earlier format behind.
 		exporters := make([]Exporter, 0, len(_formats))
 		for _, format := range _formats {
-			exporter, err := exporterFor(format)
+			exporter, err := exporterFor(format, cache)
 			if err != nil {
 				log.Fatal(err)
 			}
 			exporters = append(exporters, exporter)
 		}
 
-		project, err := readProject(_project)
+		if _watch {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			if err := watchBuild(ctx, _project, exporters); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+
+		project, err := ReadProject(_project)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		for _, exporter := range exporters {
-			outfile, err := exporter.Export(project)
-			if err != nil {
-				log.Fatal(err)
-			}
-			fmt.Println(outfile)
+		if !reportExports(exportAll(project, exporters)) {
+			os.Exit(config.ExitCodeError)
 		}
 	},
 }
 
 // exporterFor maps a --format value to its Exporter, or reports it as
-// unknown (SPECS §9).
-func exporterFor(format string) (Exporter, error) {
+// unknown (SPECS §9). The exporter converts text files through cache; nil
+// converts every file.
+func exporterFor(format string, cache *textCache) (Exporter, error) {
 	switch format {
 	case "epub":
-		return epubExporter{}, nil
+		return epubExporter{cache: cache}, nil
 	case "pdf":
-		return typstExporter{}, nil
+		return typstExporter{cache: cache}, nil
 	case "mdx":
-		return mdxExporter{}, nil
+		return mdxExporter{cache: cache}, nil
+	case "html":
+		return htmlExporter{cache: cache}, nil
+	case "docx":
+		return docxExporter{cache: cache}, nil
 	default:
-		return nil, fmt.Errorf("unknown format %q (want epub|pdf|mdx)", format)
+		return nil, fmt.Errorf("unknown format %q (want epub|pdf|mdx|html|docx)", format)
 	}
 }
 
@@ -61,5 +82,7 @@ func init() {
 	mainCmd.AddCommand(buildCmd)
 
 	buildCmd.Flags().StringVarP(&_project, "project", "p", "ebook.yml", "eBook project file")
-	buildCmd.Flags().StringSliceVarP(&_formats, "format", "f", []string{"epub"}, "output format(s): epub, pdf, mdx (repeatable, or comma-separated)")
+	buildCmd.Flags().StringSliceVarP(&_formats, "format", "f", []string{"epub"}, "output format(s): epub, pdf, mdx, html, docx (repeatable, or comma-separated)")
+	buildCmd.Flags().BoolVar(&_noCache, "no-cache", false, "convert every text file, ignoring and not updating the build cache ("+textCacheDir+")")
+	buildCmd.Flags().BoolVar(&_watch, "watch", false, "rebuild whenever the project file or one of its inputs changes")
 }
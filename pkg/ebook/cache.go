@@ -0,0 +1,123 @@
+package ebook
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dpurge/cli-tools/pkg/tool/markdown"
+	"github.com/dpurge/cli-tools/pkg/version"
+)
+
+// textCacheDir is the build cache directory, created next to the project
+// file.
+const textCacheDir = ".ebook-cache"
+
+// textCache stores each text file's converted HTML, Typst or MDX, so a
+// rebuild only converts the files that changed. An entry's key hashes
+// everything the conversion depends on — the format, the renderer version,
+// the project's language, script and font.css, and the source bytes — so a
+// stale entry is never found, only left behind; deleting the directory is
+// always safe.
+//
+// A nil *textCache is valid and converts every file (--no-cache). The cache
+// never fails a build: an entry that cannot be read or written is simply
+// converted again.
+type textCache struct {
+	dir string
+}
+
+// newTextCache returns the cache for the project file projectfile.
+func newTextCache(projectfile string) *textCache {
+	return &textCache{dir: filepath.Join(filepath.Dir(projectfile), textCacheDir)}
+}
+
+// rendererVersion identifies the code that converts the texts: a hash of
+// the running executable, so any change to a renderer — released or not —
+// invalidates the cache. If the executable cannot be read, the version
+// string stands in.
+var rendererVersion = sync.OnceValue(func() string {
+	if exe, err := os.Executable(); err == nil {
+		if f, err := os.Open(exe); err == nil {
+			defer f.Close()
+			h := sha256.New()
+			if _, err := io.Copy(h, f); err == nil {
+				return hex.EncodeToString(h.Sum(nil))
+			}
+		}
+	}
+	return version.Version
+})
+
+// fontCSS returns the content of the project's font.css, or nil if it has
+// none (or it cannot be read, in which case the build reports it).
+func fontCSS(project *EBookProject) []byte {
+	for _, p := range project.Stylesheet.Common {
+		if strings.EqualFold(filepath.Base(p), "font.css") {
+			data, _ := os.ReadFile(p)
+			return data
+		}
+	}
+	return nil
+}
+
+// convert returns file converted by convert into format, from the cache
+// when an entry for the same inputs exists. A conversion error names the
+// file (markdown.InFile).
+func (c *textCache) convert(project *EBookProject, format, file string, convert func(source []byte) ([]byte, error)) (string, error) {
+	source, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	if c == nil {
+		out, err := convert(source)
+		return string(out), markdown.InFile(file, err)
+	}
+
+	h := sha256.New()
+	for _, part := range [][]byte{[]byte(format), []byte(rendererVersion()), []byte(project.Language), []byte(project.Script), fontCSS(project), source} {
+		// Length-prefix each part, so no two different inputs hash alike.
+		h.Write(binary.BigEndian.AppendUint32(nil, uint32(len(part))))
+		h.Write(part)
+	}
+	entry := filepath.Join(c.dir, format, hex.EncodeToString(h.Sum(nil)))
+
+	if out, err := os.ReadFile(entry); err == nil {
+		return string(out), nil
+	}
+
+	out, err := convert(source)
+	if err != nil {
+		return "", markdown.InFile(file, err)
+	}
+	c.store(entry, out)
+	return string(out), nil
+}
+
+// store writes an entry through a temporary file and a rename, so a
+// concurrent reader (another format, another build) never sees it half
+// written.
+func (c *textCache) store(entry string, out []byte) {
+	if err := os.MkdirAll(filepath.Dir(entry), 0o755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(entry), ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(out)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), entry)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
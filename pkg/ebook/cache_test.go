@@ -0,0 +1,98 @@
+package ebook
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestTextCache: an entry is reused only while the format, the source and
+// the project's language, script and font.css are all unchanged; failed
+// conversions are not cached; a nil cache converts every time.
+func TestTextCache(t *testing.T) {
+	dir := t.TempDir()
+	chapter := writeFixture(t, dir, "chapter.md", "# One\n")
+	fontcss := writeFixture(t, dir, "font.css", "@font-face { font-family: A; }")
+	project := &EBookProject{Language: "tur", Script: "latn", Stylesheet: EBookStyles{Common: []string{fontcss}}}
+	cache := newTextCache(filepath.Join(dir, "ebook.yml"))
+
+	calls := 0
+	convert := func(source []byte) ([]byte, error) {
+		calls++
+		return append([]byte("converted:"), source...), nil
+	}
+	expect := func(step string, c *textCache, format string, wantCalls int) {
+		t.Helper()
+		got, err := c.convert(project, format, chapter, convert)
+		if err != nil {
+			t.Fatalf("%s: convert: %v", step, err)
+		}
+		source, _ := os.ReadFile(chapter)
+		if got != "converted:"+string(source) {
+			t.Fatalf("%s: convert = %q, want the converted source", step, got)
+		}
+		if calls != wantCalls {
+			t.Fatalf("%s: %d conversions, want %d", step, calls, wantCalls)
+		}
+	}
+
+	expect("first build", cache, "html", 1)
+	expect("unchanged", cache, "html", 1)
+	expect("other format", cache, "typst", 2)
+	expect("other format, unchanged", cache, "typst", 2)
+
+	os.WriteFile(chapter, []byte("# One, edited\n"), 0o644)
+	expect("edited source", cache, "html", 3)
+
+	project.Script = "cyrl"
+	expect("other script", cache, "html", 4)
+
+	project.Language = "aze"
+	expect("other language", cache, "html", 5)
+
+	os.WriteFile(fontcss, []byte("@font-face { font-family: B; }"), 0o644)
+	expect("edited font.css", cache, "html", 6)
+	expect("all unchanged again", cache, "html", 6)
+
+	expect("no cache", nil, "html", 7)
+	expect("no cache, again", nil, "html", 8)
+
+	broken := errors.New("broken block")
+	failing := func(source []byte) ([]byte, error) {
+		calls++
+		return nil, broken
+	}
+	os.WriteFile(chapter, []byte("# Broken\n"), 0o644)
+	for i := range 2 {
+		if _, err := cache.convert(project, "html", chapter, failing); !errors.Is(err, broken) || err.Error() != chapter+": broken block" {
+			t.Fatalf("failing conversion %d: err = %v, want %s: broken block", i, err, chapter)
+		}
+	}
+	if calls != 10 {
+		t.Fatalf("a failed conversion was cached: %d conversions, want 10", calls)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, textCacheDir, "html"))
+	if err != nil {
+		t.Fatalf("read cache dir: %v", err)
+	}
+	if len(entries) != 5 {
+		t.Errorf("cache holds %d html entries, want 5 (no temporary files)", len(entries))
+	}
+}
+
+// TestTextCacheUnwritable: a cache directory that cannot be created does
+// not fail the conversion.
+func TestTextCacheUnwritable(t *testing.T) {
+	dir := t.TempDir()
+	chapter := writeFixture(t, dir, "chapter.md", "# One\n")
+	// A file where the cache directory should be.
+	blocker := writeFixture(t, dir, textCacheDir, "")
+	cache := &textCache{dir: blocker}
+
+	got, err := cache.convert(&EBookProject{}, "html", chapter, func(source []byte) ([]byte, error) { return source, nil })
+	if err != nil || got != "# One\n" {
+		t.Fatalf("convert = %q, %v; want the conversion despite the unwritable cache", got, err)
+	}
+}
@@ -21,14 +21,14 @@ var doctorCmd = &cobra.Command{
 		// Typst is resolved exactly as the PDF exporter resolves it (the
 		// configured Typst.typst path, else PATH) and then actually run, so
 		// `doctor` reports what `build --format pdf` would really find and use.
-		path, err := locateTypst()
+		path, err := LocateTypst()
 		typstUsable := false
 		if err != nil {
 			healthy = false
 			fmt.Fprintln(os.Stderr, "ERR  typst  not found: needed for `build --format pdf` "+
 				"(install https://typst.app, or set Typst.typst in the config); "+
 				"EPUB and MDX export are unaffected")
-		} else if out, runErr := runTypst(path, "--version"); runErr != nil {
+		} else if out, runErr := RunTypst(path, "--version"); runErr != nil {
 			healthy = false
 			fmt.Fprintf(os.Stderr, "ERR  typst  found at %s but not runnable: %v\n", path, runErr)
 		} else {
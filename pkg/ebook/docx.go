@@ -0,0 +1,193 @@
+package ebook
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dpurge/cli-tools/pkg/tool"
+	"github.com/dpurge/cli-tools/pkg/tool/markdown"
+)
+
+// docxExporter implements Exporter, writing the book as one Word document
+// "<base>.docx" next to the EPUB: the title and author, then every section
+// and chapter converted with markdown.ToDOCX, in document order. Like
+// writeXLSX it writes the Office Open XML package by hand — the chapters'
+// body fragments need nothing from the package beyond the styles part
+// (docxStyles), so no document library is involved.
+type docxExporter struct {
+	cache *textCache
+}
+
+func (e docxExporter) Export(project *EBookProject) (string, error) {
+	lang, dir := languageInfo(project.Language, project.Script)
+
+	bodies, err := convertTexts(WalkTexts(project.Text), func(item ProjectItem) (string, error) {
+		return e.cache.convert(project, "docx", item.File, markdown.ToDOCX)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	outfile := baseOutputName(project.Filename) + ".docx"
+	if err := writeDOCX(outfile, project, lang, dir, bodies); err != nil {
+		return "", err
+	}
+	return outfile, nil
+}
+
+// writeDOCX writes the document package: the main document part and its
+// styles, plus the core properties carrying the title, author and language.
+func writeDOCX(filename string, project *EBookProject, lang, dir string, bodies []string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxRootRels},
+		{"docProps/core.xml", docxCoreProperties(project, lang)},
+		{"word/document.xml", docxDocument(project, dir, bodies)},
+		{"word/_rels/document.xml.rels", docxDocumentRels},
+		{"word/styles.xml", docxStyles(lang, dir)},
+	}
+	for _, part := range parts {
+		w, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, part.content); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+const docxContentTypes = tool.OOXMLHeader +
+	`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+	`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>` +
+	`</Types>`
+
+const docxRootRels = tool.OOXMLHeader +
+	`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>` +
+	`</Relationships>`
+
+const docxDocumentRels = tool.OOXMLHeader +
+	`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+func docxCoreProperties(project *EBookProject, lang string) string {
+	var b strings.Builder
+	b.WriteString(tool.OOXMLHeader)
+	b.WriteString(`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">`)
+	fmt.Fprintf(&b, `<dc:title>%s</dc:title>`, tool.XMLEscape(project.Title))
+	if project.Author != "" {
+		fmt.Fprintf(&b, `<dc:creator>%s</dc:creator>`, tool.XMLEscape(project.Author))
+	}
+	if project.Description != "" {
+		fmt.Fprintf(&b, `<dc:description>%s</dc:description>`, tool.XMLEscape(project.Description))
+	}
+	fmt.Fprintf(&b, `<dc:language>%s</dc:language>`, tool.XMLEscape(lang))
+	b.WriteString(`</cp:coreProperties>`)
+	return b.String()
+}
+
+// docxDocument returns the main document part: a title page (the title,
+// and the author as its subtitle), the converted bodies, and an A4 section
+// with 1-inch margins — the text width markdown.ToDOCX divides its tables
+// over — laid out right to left for an RTL book.
+func docxDocument(project *EBookProject, dir string, bodies []string) string {
+	var b strings.Builder
+	b.WriteString(tool.OOXMLHeader)
+	b.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`)
+	rtl := ""
+	if dir == "rtl" {
+		rtl = "<w:rPr><w:rtl/></w:rPr>"
+	}
+	fmt.Fprintf(&b, `<w:p><w:pPr><w:pStyle w:val="Title"/></w:pPr><w:r>%s<w:t xml:space="preserve">%s</w:t></w:r></w:p>`, rtl, tool.XMLEscape(project.Title))
+	if project.Author != "" {
+		fmt.Fprintf(&b, `<w:p><w:pPr><w:pStyle w:val="Subtitle"/></w:pPr><w:r>%s<w:t xml:space="preserve">%s</w:t></w:r></w:p>`, rtl, tool.XMLEscape(project.Author))
+	}
+	for _, body := range bodies {
+		b.WriteString(body)
+	}
+	b.WriteString(`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440" w:header="708" w:footer="708" w:gutter="0"/>`)
+	if dir == "rtl" {
+		b.WriteString(`<w:bidi/>`)
+	}
+	b.WriteString(`</w:sectPr></w:body></w:document>`)
+	return b.String()
+}
+
+// docxStyles returns the styles part defining every style markdown.ToDOCX
+// refers to. The document defaults carry the book's language and, for an
+// RTL book, its direction, which every paragraph not pinned by a block
+// inherits. Built-in styles keep Word's names, so they map onto the
+// user's own templates; Heading1 starts each chapter on a new page.
+func docxStyles(lang, dir string) string {
+	var b strings.Builder
+	b.WriteString(tool.OOXMLHeader)
+	b.WriteString(`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">`)
+
+	bidi := ""
+	if dir == "rtl" {
+		bidi = "<w:bidi/>"
+	}
+	fmt.Fprintf(&b, `<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:eastAsia="Calibri" w:cs="Arial"/><w:sz w:val="22"/><w:szCs w:val="22"/><w:lang w:val="%s" w:eastAsia="%s" w:bidi="%s"/></w:rPr></w:rPrDefault>`, lang, lang, lang)
+	fmt.Fprintf(&b, `<w:pPrDefault><w:pPr>%s<w:spacing w:after="160" w:line="259" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>`, bidi)
+
+	paragraph := func(id, name, props string) {
+		fmt.Fprintf(&b, `<w:style w:type="paragraph" w:styleId="%s"><w:name w:val="%s"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>%s</w:style>`, id, name, props)
+	}
+	b.WriteString(`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/></w:style>`)
+	paragraph("Title", "Title", `<w:pPr><w:spacing w:before="2400" w:after="240"/><w:jc w:val="center"/></w:pPr><w:rPr><w:sz w:val="56"/><w:szCs w:val="56"/></w:rPr>`)
+	paragraph("Subtitle", "Subtitle", `<w:pPr><w:jc w:val="center"/></w:pPr><w:rPr><w:sz w:val="32"/><w:szCs w:val="32"/></w:rPr>`)
+	for level, size := range []int{36, 30, 26, 24, 22, 22} {
+		pageBreak := ""
+		if level == 0 {
+			pageBreak = "<w:pageBreakBefore/>"
+		}
+		paragraph(fmt.Sprintf("Heading%d", level+1), fmt.Sprintf("heading %d", level+1),
+			fmt.Sprintf(`<w:pPr><w:keepNext/><w:keepLines/>%s<w:spacing w:before="240" w:after="120"/><w:outlineLvl w:val="%d"/></w:pPr><w:rPr><w:b/><w:bCs/><w:sz w:val="%d"/><w:szCs w:val="%d"/></w:rPr>`, pageBreak, level, size, size))
+	}
+	paragraph("Quote", "Quote", `<w:pPr><w:ind w:left="720" w:right="720"/></w:pPr><w:rPr><w:i/><w:iCs/></w:rPr>`)
+	paragraph("SourceCode", "Source Code", `<w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr><w:rPr><w:rFonts w:ascii="Courier New" w:hAnsi="Courier New" w:cs="Courier New"/><w:sz w:val="20"/><w:szCs w:val="20"/></w:rPr>`)
+	paragraph("ListParagraph", "List Paragraph", `<w:pPr><w:spacing w:after="60"/><w:ind w:left="720"/></w:pPr>`)
+	paragraph("BlockHeading", "Block Heading", `<w:pPr><w:keepNext/><w:spacing w:after="0"/></w:pPr><w:rPr><w:b/><w:bCs/></w:rPr>`)
+	paragraph("BlockNote", "Block Note", `<w:pPr><w:spacing w:after="0"/><w:jc w:val="center"/></w:pPr><w:rPr><w:i/><w:iCs/></w:rPr>`)
+	paragraph("DialogSpeaker", "Dialog Speaker", `<w:pPr><w:keepNext/><w:spacing w:after="0"/></w:pPr><w:rPr><w:b/><w:bCs/></w:rPr>`)
+	paragraph("DialogText", "Dialog Text", `<w:pPr><w:ind w:left="360"/></w:pPr>`)
+
+	b.WriteString(`<w:style w:type="character" w:default="1" w:styleId="DefaultParagraphFont"><w:name w:val="Default Paragraph Font"/><w:uiPriority w:val="1"/><w:semiHidden/><w:unhideWhenUsed/></w:style>`)
+	b.WriteString(`<w:style w:type="character" w:styleId="Hyperlink"><w:name w:val="Hyperlink"/><w:basedOn w:val="DefaultParagraphFont"/><w:rPr><w:color w:val="0563C1"/><w:u w:val="single"/></w:rPr></w:style>`)
+	b.WriteString(`<w:style w:type="character" w:styleId="VerbatimChar"><w:name w:val="Verbatim Char"/><w:basedOn w:val="DefaultParagraphFont"/><w:rPr><w:rFonts w:ascii="Courier New" w:hAnsi="Courier New" w:cs="Courier New"/><w:sz w:val="20"/><w:szCs w:val="20"/></w:rPr></w:style>`)
+
+	b.WriteString(`<w:style w:type="table" w:default="1" w:styleId="TableNormal"><w:name w:val="Normal Table"/><w:uiPriority w:val="99"/><w:semiHidden/><w:unhideWhenUsed/><w:tblPr><w:tblInd w:w="0" w:type="dxa"/><w:tblCellMar><w:top w:w="0" w:type="dxa"/><w:left w:w="108" w:type="dxa"/><w:bottom w:w="0" w:type="dxa"/><w:right w:w="108" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style>`)
+	b.WriteString(`<w:style w:type="table" w:styleId="BlockTable"><w:name w:val="Block Table"/><w:basedOn w:val="TableNormal"/><w:pPr><w:spacing w:before="40" w:after="40"/></w:pPr><w:tblPr><w:tblBorders>`)
+	for _, edge := range []string{"top", "left", "bottom", "right", "insideH", "insideV"} {
+		fmt.Fprintf(&b, `<w:%s w:val="single" w:sz="4" w:space="0" w:color="auto"/>`, edge)
+	}
+	b.WriteString(`</w:tblBorders></w:tblPr></w:style>`)
+	b.WriteString(`<w:style w:type="table" w:styleId="PlainTable"><w:name w:val="Plain Table"/><w:basedOn w:val="TableNormal"/><w:pPr><w:spacing w:after="0"/></w:pPr></w:style>`)
+
+	b.WriteString(`</w:styles>`)
+	return b.String()
+}
@@ -0,0 +1,138 @@
+package ebook
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDocxExporter builds a synthetic RTL project and asserts the document
+// package: every part is well-formed XML, the required parts are present,
+// and the document carries the title, the book's direction and each
+// chapter's converted body in order.
+func TestDocxExporter(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, dir, "section.md", "# مقدمة\n")
+	writeFixture(t, dir, "one.md", "# One\n\n{start-vocabulary lang=arb script=arab}\nبيت = house\n{end-vocabulary}\n\n{start-models lang=arb script=arab}\nكتاب = book\n{end-models}\n")
+	writeFixture(t, dir, "two.md", "# Q&A\n\n- item\n")
+	projectfile := writeFixture(t, dir, "ebook.yml", "filename: book.epub\ntitle: العربية\nauthor: A <B>\nlanguage: arb\nscript: arab\n"+
+		"text:\n  - [section.md, one.md, two.md]\n")
+
+	project, err := ReadProject(projectfile)
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	outfile, err := docxExporter{}.Export(project)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if want := filepath.Join(dir, "book.docx"); outfile != want {
+		t.Errorf("outfile = %q, want %q", outfile, want)
+	}
+
+	zr, err := zip.OpenReader(outfile)
+	if err != nil {
+		t.Fatalf("open document: %v", err)
+	}
+	defer zr.Close()
+
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		parts[f.Name] = string(data)
+
+		dec := xml.NewDecoder(strings.NewReader(string(data)))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not well-formed XML: %v", f.Name, err)
+			}
+		}
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "docProps/core.xml", "word/document.xml", "word/_rels/document.xml.rels", "word/styles.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("document is missing part %s", name)
+		}
+	}
+
+	tests := []struct {
+		part string
+		want []string
+	}{
+		{"docProps/core.xml", []string{
+			`<dc:title>العربية</dc:title>`,
+			`<dc:creator>A &lt;B&gt;</dc:creator>`,
+			`<dc:language>ar</dc:language>`,
+		}},
+		{"word/document.xml", []string{
+			`<w:pStyle w:val="Title"/></w:pPr><w:r><w:rPr><w:rtl/></w:rPr><w:t xml:space="preserve">العربية</w:t>`,
+			`<w:pStyle w:val="Subtitle"/></w:pPr><w:r><w:rPr><w:rtl/></w:rPr><w:t xml:space="preserve">A &lt;B&gt;</w:t>`,
+			`<w:t xml:space="preserve">Q&amp;A</w:t>`,
+			`</w:tbl><w:p/><w:tbl>`,
+			`<w:bidi/></w:sectPr></w:body></w:document>`,
+		}},
+		{"word/styles.xml", []string{
+			`<w:lang w:val="ar" w:eastAsia="ar" w:bidi="ar"/>`,
+			`<w:pPrDefault><w:pPr><w:bidi/>`,
+			`w:styleId="BlockTable"`,
+			`w:styleId="DialogSpeaker"`,
+		}},
+	}
+	for _, tc := range tests {
+		for _, want := range tc.want {
+			if !strings.Contains(parts[tc.part], want) {
+				t.Errorf("%s missing %q\n%s", tc.part, want, parts[tc.part])
+			}
+		}
+	}
+
+	document := parts["word/document.xml"]
+	section := strings.Index(document, "مقدمة")
+	one := strings.Index(document, ">One<")
+	two := strings.Index(document, "Q&amp;A")
+	if section < 0 || one < section || two < one {
+		t.Errorf("bodies out of document order: section at %d, chapters at %d and %d", section, one, two)
+	}
+}
+
+// TestDocxStylesDefined asserts every style markdown.ToDOCX refers to is
+// defined in the styles part: Word silently falls back to Normal for an
+// unknown style ID.
+func TestDocxStylesDefined(t *testing.T) {
+	styles := docxStyles("en", "ltr")
+	for _, id := range []string{
+		"Normal", "Title", "Subtitle", "Heading1", "Heading2", "Heading3", "Heading4", "Heading5", "Heading6",
+		"Quote", "SourceCode", "ListParagraph", "BlockHeading", "BlockNote", "DialogSpeaker", "DialogText",
+		"Hyperlink", "VerbatimChar", "TableNormal", "BlockTable", "PlainTable",
+	} {
+		if !strings.Contains(styles, `w:styleId="`+id+`"`) {
+			t.Errorf("styles part does not define %s", id)
+		}
+	}
+	if strings.Contains(styles, "<w:bidi/>") {
+		t.Errorf("LTR styles part sets bidi:\n%s", styles)
+	}
+}
+
+// TestExporterForDocx asserts "-f docx" resolves to docxExporter.
+func TestExporterForDocx(t *testing.T) {
+	exp, err := exporterFor("docx", nil)
+	if err != nil {
+		t.Fatalf("exporterFor(\"docx\") error = %v", err)
+	}
+	if _, ok := exp.(docxExporter); !ok {
+		t.Errorf("exporterFor(\"docx\") = %T, want docxExporter", exp)
+	}
+}
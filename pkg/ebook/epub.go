@@ -14,9 +14,11 @@ import (
 // section/chapter loop now consumes the shared WalkTexts (exporter.go) and
 // language/direction now comes from the shared languageInfo (exporter.go)
 // instead of the former project-local setLanguage.
-type epubExporter struct{}
+type epubExporter struct {
+	cache *textCache
+}
 
-func (epubExporter) Export(project *EBookProject) (string, error) {
+func (e epubExporter) Export(project *EBookProject) (string, error) {
 	book, err := epub.NewEpub(project.Title)
 	if err != nil {
 		return "", err
@@ -52,7 +54,7 @@ func (epubExporter) Export(project *EBookProject) (string, error) {
 		}
 	}
 
-	_, err = addTexts(book, project.Text, stylesheets)
+	_, err = addTexts(book, project, stylesheets, e.cache)
 	if err != nil {
 		return "", err
 	}
@@ -145,23 +147,32 @@ func addImages(book *epub.Epub, imagefiles []string) ([]string, error) {
 // chapter is nested under its own section with AddSubSection. WalkTexts
 // preserves the exact GLOBAL/continuous section/chapter counters the
 // pre-refactor loop used, so the generated internal
-// "section%04d.xhtml"/"chapter%04d.xhtml" filenames are unchanged.
-func addTexts(book *epub.Epub, textfiles [][]string, styles EBookStyles) ([]string, error) {
-	items := WalkTexts(textfiles)
+// "section%04d.xhtml"/"chapter%04d.xhtml" filenames are unchanged. The
+// files are converted to HTML concurrently (convertTexts); only adding them
+// to the book, which go-epub does not allow concurrently, is serial.
+func addTexts(book *epub.Epub, project *EBookProject, styles EBookStyles, cache *textCache) ([]string, error) {
+	items := WalkTexts(project.Text)
+	bodies, err := convertTexts(items, func(item ProjectItem) (string, error) {
+		return cache.convert(project, "html", item.File, markdown.ToHTML)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	texts := make([]string, 0, len(items))
 	var currentSection string
 
-	for _, item := range items {
+	for i, item := range items {
 		switch item.Kind {
 		case SectionItem:
-			section, err := addSection(book, item.File, styles.Section, item.SectionIdx)
+			section, err := addSection(book, bodies[i], styles.Section, item.SectionIdx)
 			if err != nil {
 				return nil, err
 			}
 			currentSection = section
 			texts = append(texts, section)
 		case ChapterItem:
-			chapter, err := addChapter(book, currentSection, item.File, styles.Chapter, item.ChapterIdx)
+			chapter, err := addChapter(book, currentSection, bodies[i], styles.Chapter, item.ChapterIdx)
 			if err != nil {
 				return nil, err
 			}
@@ -172,12 +183,7 @@ func addTexts(book *epub.Epub, textfiles [][]string, styles EBookStyles) ([]stri
 	return texts, nil
 }
 
-func addSection(book *epub.Epub, fileName string, stylesheet string, id int) (string, error) {
-	body, err := markdown.FileToHTML(fileName)
-	if err != nil {
-		return "", err
-	}
-
+func addSection(book *epub.Epub, body string, stylesheet string, id int) (string, error) {
 	title, err := tool.GetHtmlTitle(body)
 	if err != nil {
 		return "", err
@@ -191,12 +197,7 @@ func addSection(book *epub.Epub, fileName string, stylesheet string, id int) (st
 	return internalFile, nil
 }
 
-func addChapter(book *epub.Epub, section string, fileName string, stylesheet string, id int) (string, error) {
-	body, err := markdown.FileToHTML(fileName)
-	if err != nil {
-		return "", err
-	}
-
+func addChapter(book *epub.Epub, section string, body string, stylesheet string, id int) (string, error) {
 	title, err := tool.GetHtmlTitle(body)
 	if err != nil {
 		return "", err
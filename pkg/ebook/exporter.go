@@ -1,20 +1,22 @@
 package ebook
 
 import (
-	"path/filepath"
-	"strings"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/dpurge/cli-tools/pkg/tool"
 )
 
-// baseOutputName strips the extension from a project Filename to obtain a
-// base path suitable for appending any output extension. If the filename ends
-// in ".epub" that suffix is stripped; for any other extension the generic
-// filepath.Ext suffix is stripped. This is the single shared derivation used
-// by all exporters — EPUB, PDF/Typst, MDX, and vocabulary CSV.
+// baseOutputName strips the extension from a project Filename (".epub" or
+// any other) to obtain a base path suitable for appending any output
+// extension. This is the single shared derivation used by all exporters —
+// EPUB, PDF/Typst, MDX, and the vocabulary exports — and it is
+// tool.BaseOutputName, which flashcard-cli's exports use too.
 func baseOutputName(filename string) string {
-	if strings.HasSuffix(filename, ".epub") {
-		return strings.TrimSuffix(filename, ".epub")
-	}
-	return strings.TrimSuffix(filename, filepath.Ext(filename))
+	return tool.BaseOutputName(filename)
 }
 
 // Exporter builds one output artifact (EPUB, PDF, ...) from an already
@@ -84,6 +86,95 @@ func WalkTexts(text [][]string) []ProjectItem {
 	return items
 }
 
+// convertTexts calls convert for every item on a pool of at most
+// GOMAXPROCS workers and returns the results in items' (WalkTexts document)
+// order, so a book assembled from them is identical to a serial build. If
+// any conversion fails, the error of the first failing item in document
+// order is returned, again as a serial build would report it.
+//
+// The markdown package is safe for this: its shared goldmark instance (md,
+// converter.go) and the Typst renderer initialise once behind a sync.Once,
+// every parse gets its own parser.Context, the package's block parsers and
+// node renderers are stateless, and ToMDX builds a fresh renderer per call.
+func convertTexts(items []ProjectItem, convert func(item ProjectItem) (string, error)) ([]string, error) {
+	results := make([]string, len(items))
+	errs := make([]error, len(items))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(items)) {
+		wg.Go(func() {
+			for i := range jobs {
+				results[i], errs[i] = convert(items[i])
+			}
+		})
+	}
+	for i := range items {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// warningExporter is implemented by exporters whose external tool reports
+// non-fatal warnings (typstExporter). exportAll collects them through
+// exportWarnings rather than letting concurrent exporters write to stderr.
+type warningExporter interface {
+	exportWarnings(project *EBookProject) (outfile, warnings string, err error)
+}
+
+// exportResult is one exporter's outcome in exportAll.
+type exportResult struct {
+	outfile  string
+	warnings string
+	err      error
+}
+
+// exportAll runs every exporter on project concurrently — the formats share
+// nothing but the read-only project — and returns each one's result in
+// exporters' order.
+func exportAll(project *EBookProject, exporters []Exporter) []exportResult {
+	results := make([]exportResult, len(exporters))
+	var wg sync.WaitGroup
+	for i, exporter := range exporters {
+		wg.Go(func() {
+			r := &results[i]
+			if w, ok := exporter.(warningExporter); ok {
+				r.outfile, r.warnings, r.err = w.exportWarnings(project)
+			} else {
+				r.outfile, r.err = exporter.Export(project)
+			}
+		})
+	}
+	wg.Wait()
+	return results
+}
+
+// reportExports prints exportAll's results in order, each format's warnings
+// ahead of its output file or error, so concurrent formats never interleave
+// and every failure is reported, not just the first. It returns whether all
+// exports succeeded.
+func reportExports(results []exportResult) bool {
+	ok := true
+	for _, result := range results {
+		fmt.Fprint(os.Stderr, result.warnings)
+		if result.err != nil {
+			log.Print(result.err)
+			ok = false
+			continue
+		}
+		fmt.Println(result.outfile)
+	}
+	return ok
+}
+
 // languageInfo maps an EBookProject's ISO 639-3 Language code and ISO 15924
 // Script code to a BCP-47-ish language tag and a paragraph direction ("ltr"
 // or "rtl"). It reproduces, verbatim, every case of the pre-refactor
@@ -1,6 +1,13 @@
 package ebook
 
-import "testing"
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
 
 // TestLanguageInfoFR1PolMapping is the FR-1 AC-1 regression test:
 // languageInfo("pol", ...) must return lang="pl" after the T1 fix.
@@ -76,3 +83,104 @@ func TestLanguageInfoFR1PolMapping(t *testing.T) {
 		}
 	}
 }
+
+// TestConvertTexts: results come back in document order however the
+// workers finish, no more than GOMAXPROCS conversions run at once, and the
+// reported error is the first failing item's in document order.
+func TestConvertTexts(t *testing.T) {
+	var items []ProjectItem
+	for i := range 40 {
+		items = append(items, ProjectItem{File: fmt.Sprintf("chapter%02d.md", i), Kind: ChapterItem, ChapterIdx: i + 1})
+	}
+
+	var running, peak atomic.Int32
+	got, err := convertTexts(items, func(item ProjectItem) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		// Later items finish sooner, so completion order is reversed.
+		time.Sleep(time.Duration(len(items)-item.ChapterIdx) * 100 * time.Microsecond)
+		return item.File, nil
+	})
+	if err != nil {
+		t.Fatalf("convertTexts: %v", err)
+	}
+	for i, item := range items {
+		if got[i] != item.File {
+			t.Fatalf("convertTexts()[%d] = %q, want %q", i, got[i], item.File)
+		}
+	}
+	if limit := int32(runtime.GOMAXPROCS(0)); peak.Load() > limit {
+		t.Errorf("%d conversions ran at once, want at most %d", peak.Load(), limit)
+	}
+
+	_, err = convertTexts(items, func(item ProjectItem) (string, error) {
+		if item.ChapterIdx == 12 || item.ChapterIdx == 30 {
+			time.Sleep(time.Duration(40-item.ChapterIdx) * time.Millisecond)
+			return "", fmt.Errorf("%s: broken", item.File)
+		}
+		return item.File, nil
+	})
+	if err == nil || err.Error() != "chapter11.md: broken" {
+		t.Errorf("convertTexts error = %v, want the first failing item's (chapter11.md: broken)", err)
+	}
+
+	if got, err := convertTexts(nil, nil); err != nil || len(got) != 0 {
+		t.Errorf("convertTexts(nil) = %v, %v; want no results", got, err)
+	}
+}
+
+// stubExporter returns a fixed result after delay.
+type stubExporter struct {
+	outfile string
+	err     error
+	delay   time.Duration
+}
+
+func (e stubExporter) Export(project *EBookProject) (string, error) {
+	time.Sleep(e.delay)
+	return e.outfile, e.err
+}
+
+// stubWarningExporter is a stubExporter that also reports warnings.
+type stubWarningExporter struct {
+	stubExporter
+	warnings string
+}
+
+func (e stubWarningExporter) exportWarnings(project *EBookProject) (string, string, error) {
+	outfile, err := e.Export(project)
+	return outfile, e.warnings, err
+}
+
+// TestExportAll: results come back in exporters' order however they finish,
+// a warningExporter's warnings are collected with its result rather than
+// printed, and every failure is kept.
+func TestExportAll(t *testing.T) {
+	broken := errors.New("broken")
+	results := exportAll(&EBookProject{}, []Exporter{
+		stubWarningExporter{stubExporter{outfile: "book.pdf", delay: 20 * time.Millisecond}, "warning: font substituted\n"},
+		stubExporter{err: broken, delay: 10 * time.Millisecond},
+		stubExporter{outfile: "book.epub"},
+		stubExporter{err: broken},
+	})
+	want := []exportResult{
+		{outfile: "book.pdf", warnings: "warning: font substituted\n"},
+		{err: broken},
+		{outfile: "book.epub"},
+		{err: broken},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("exportAll returned %d results, want %d", len(results), len(want))
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("exportAll()[%d] = %+v, want %+v", i, results[i], want[i])
+		}
+	}
+}
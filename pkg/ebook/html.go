@@ -0,0 +1,173 @@
+package ebook
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dpurge/cli-tools/pkg/tool/markdown"
+)
+
+// htmlExporter implements Exporter, producing a DIRECTORY holding a static
+// website of the book that needs nothing but a web server (or a browser
+// opening index.html): index.html with the table of contents, one page per
+// section and chapter rendered with markdown.ToHTML, and copies of the
+// project's stylesheets, fonts and images.
+//
+// The layout is the preview server's (serve.go), which mirrors go-epub's
+// inside the EPUB — xhtml/ for the pages, css/, fonts/ and images/ — so
+// relative references that work in the book work on the site too. Language
+// and direction come from languageInfo, as for the EPUB.
+type htmlExporter struct {
+	cache *textCache
+}
+
+func (e htmlExporter) Export(project *EBookProject) (string, error) {
+	dir := derivedHtmlDir(project.Filename)
+	for _, sub := range []string{"xhtml", "css", "fonts", "images"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return "", err
+		}
+	}
+
+	stylesheets := append([]string{project.Stylesheet.Cover, project.Stylesheet.Section, project.Stylesheet.Chapter}, project.Stylesheet.Common...)
+	if err := copyFiles(filepath.Join(dir, "css"), stylesheets); err != nil {
+		return "", err
+	}
+	if err := copyFiles(filepath.Join(dir, "fonts"), project.Font); err != nil {
+		return "", err
+	}
+	if err := copyFiles(filepath.Join(dir, "images"), append([]string{project.Cover}, project.Image...)); err != nil {
+		return "", err
+	}
+
+	lang, textDir := languageInfo(project.Language, project.Script)
+	items := WalkTexts(project.Text)
+	bodies, err := convertTexts(items, func(item ProjectItem) (string, error) {
+		return e.cache.convert(project, "html", item.File, markdown.ToHTML)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Stylesheet lists are built from basenames' fresh slices: other
+	// exporters read the project concurrently (exportAll), so its own
+	// slices must not be appended to.
+	index := sitePage{
+		Lang: lang, Dir: textDir,
+		Title:       project.Title,
+		Stylesheets: append(basenames(project.Stylesheet.Common...), basenames(project.Stylesheet.Cover)...),
+		TOC:         tableOfContents(items),
+	}
+	if project.Cover != "" {
+		index.Cover = filepath.Base(project.Cover)
+	}
+	if err := writeSitePage(filepath.Join(dir, "index.html"), index); err != nil {
+		return "", err
+	}
+
+	for i, item := range items {
+		page := sitePage{
+			Lang: lang, Dir: textDir,
+			Root:  "../",
+			Title: textTitle(item.File),
+			Book:  project.Title,
+			Body:  template.HTML(bodies[i]),
+		}
+		if item.Kind == SectionItem {
+			page.Stylesheets = append(basenames(project.Stylesheet.Common...), basenames(project.Stylesheet.Section)...)
+		} else {
+			page.Stylesheets = append(basenames(project.Stylesheet.Common...), basenames(project.Stylesheet.Chapter)...)
+		}
+		if i > 0 {
+			page.Prev = &tocEntry{Title: textTitle(items[i-1].File), Page: pageName(items[i-1])}
+		}
+		if i < len(items)-1 {
+			page.Next = &tocEntry{Title: textTitle(items[i+1].File), Page: pageName(items[i+1])}
+		}
+		if err := writeSitePage(filepath.Join(dir, "xhtml", pageName(item)), page); err != nil {
+			return "", err
+		}
+	}
+
+	return dir, nil
+}
+
+// derivedHtmlDir derives the "-html" output directory from the project's
+// EPUB filename, as derivedMdxDir (mdx.go) does for "-mdx".
+func derivedHtmlDir(epubFilename string) string {
+	return baseOutputName(epubFilename) + "-html"
+}
+
+// sitePage is the data for siteTemplate: the index when TOC is set,
+// otherwise a section or chapter page. Root is the path from the page back
+// to the site's root directory.
+type sitePage struct {
+	Lang, Dir   string
+	Root        string
+	Title       string
+	Book        string
+	Stylesheets []string
+	Cover       string
+	Prev, Next  *tocEntry
+	Body        template.HTML
+	TOC         []tocEntry
+}
+
+var siteTemplate = template.Must(template.New("site").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}" dir="{{.Dir}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}{{if .Book}} · {{.Book}}{{end}}</title>
+{{range .Stylesheets}}<link rel="stylesheet" href="{{$.Root}}css/{{.}}">
+{{end}}<style>
+.site-nav { display: flex; gap: 1em; justify-content: center; margin: 1em 0; font-family: sans-serif; font-size: 0.9em; }
+</style>
+</head>
+<body>
+{{define "nav"}}<nav class="site-nav" dir="ltr">{{with .Prev}}<a href="{{.Page}}" rel="prev">&larr; {{.Title}}</a>{{end}}<a href="{{.Root}}index.html">Contents</a>{{with .Next}}<a href="{{.Page}}" rel="next">{{.Title}} &rarr;</a>{{end}}</nav>{{end}}
+{{- if .TOC}}{{if .Cover}}<img class="site-cover" src="images/{{.Cover}}" alt="">
+{{end}}<h1>{{.Title}}</h1>
+<nav class="site-toc">
+<ol>
+{{range .TOC}}<li><a href="xhtml/{{.Page}}">{{.Title}}</a>{{if .Chapters}}
+<ol>
+{{range .Chapters}}<li><a href="xhtml/{{.Page}}">{{.Title}}</a></li>
+{{end}}</ol>
+{{end}}</li>
+{{end}}</ol>
+</nav>
+{{else}}{{template "nav" .}}
+{{.Body}}
+{{template "nav" .}}
+{{end}}</body>
+</html>
+`))
+
+func writeSitePage(file string, page sitePage) error {
+	var b strings.Builder
+	if err := siteTemplate.Execute(&b, page); err != nil {
+		return err
+	}
+	return os.WriteFile(file, []byte(b.String()), 0o644)
+}
+
+// copyFiles copies each of files (skipping empty ones, as for an unset
+// stylesheet) into dir under its basename.
+func copyFiles(dir string, files []string) error {
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(file)), data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
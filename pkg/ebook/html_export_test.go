@@ -0,0 +1,92 @@
+package ebook
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestHtmlExporter builds a synthetic 1-section/2-chapter RTL project and
+// asserts the site's layout, the copied assets, the index's table of
+// contents, and each page's lang/dir, stylesheets, body and navigation.
+func TestHtmlExporter(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, dir, "base.css", "body { margin: 0; }")
+	writeFixture(t, dir, "chapter.css", "h1 { color: red; }")
+	writeFixture(t, dir, "naskh.ttf", "font")
+	writeFixture(t, dir, "map.png", "png")
+	writeFixture(t, dir, "section.md", "# الجزء الأول\n")
+	writeFixture(t, dir, "one.md", "# Lesson One\n\n{start-vocabulary}\nبيت = house\n{end-vocabulary}\n")
+	writeFixture(t, dir, "two.md", "# Lesson Two\n\n![map](../images/map.png)\n")
+	projectfile := writeFixture(t, dir, "ebook.yml", "filename: book.epub\ntitle: العربية\nlanguage: arb\nscript: arab\n"+
+		"stylesheet:\n  common: [base.css]\n  chapter: chapter.css\nfont: [naskh.ttf]\nimage: [map.png]\n"+
+		"text:\n  - [section.md, one.md, two.md]\n")
+
+	project, err := ReadProject(projectfile)
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	outdir, err := htmlExporter{}.Export(project)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if want := filepath.Join(dir, "book-html"); outdir != want {
+		t.Errorf("Export dir = %q, want %q", outdir, want)
+	}
+	for _, name := range []string{"css/base.css", "css/chapter.css", "fonts/naskh.ttf", "images/map.png"} {
+		assertFileExists(t, filepath.Join(outdir, name))
+	}
+
+	tests := []struct {
+		page string
+		want []string
+	}{
+		{"index.html", []string{
+			`<html lang="ar" dir="rtl">`,
+			`<title>العربية</title>`,
+			`<link rel="stylesheet" href="css/base.css">`,
+			`<li><a href="xhtml/section0001.html">الجزء الأول</a>`,
+			`<li><a href="xhtml/chapter0001.html">Lesson One</a></li>`,
+			`<li><a href="xhtml/chapter0002.html">Lesson Two</a></li>`,
+		}},
+		{"xhtml/section0001.html", []string{
+			`<html lang="ar" dir="rtl">`,
+			`<link rel="stylesheet" href="../css/base.css">`,
+			`<a href="../index.html">Contents</a><a href="chapter0001.html" rel="next">Lesson One &rarr;</a>`,
+		}},
+		{"xhtml/chapter0001.html", []string{
+			`<title>Lesson One · العربية</title>`,
+			`<link rel="stylesheet" href="../css/base.css">`,
+			`<link rel="stylesheet" href="../css/chapter.css">`,
+			`<span class="vocabulary-phrase">بيت</span>`,
+			`<a href="section0001.html" rel="prev">&larr; الجزء الأول</a><a href="../index.html">Contents</a><a href="chapter0002.html" rel="next">Lesson Two &rarr;</a>`,
+		}},
+		{"xhtml/chapter0002.html", []string{
+			`<img src="../images/map.png" alt="map" />`,
+			`<a href="chapter0001.html" rel="prev">&larr; Lesson One</a><a href="../index.html">Contents</a></nav>`,
+		}},
+	}
+	for _, tc := range tests {
+		data, err := os.ReadFile(filepath.Join(outdir, tc.page))
+		if err != nil {
+			t.Fatalf("read %s: %v", tc.page, err)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("%s missing %q\n%s", tc.page, want, data)
+			}
+		}
+	}
+}
+
+// TestExporterForHtml asserts "-f html" resolves to htmlExporter.
+func TestExporterForHtml(t *testing.T) {
+	exp, err := exporterFor("html", nil)
+	if err != nil {
+		t.Fatalf("exporterFor(\"html\") error = %v", err)
+	}
+	if _, ok := exp.(htmlExporter); !ok {
+		t.Errorf("exporterFor(\"html\") = %T, want htmlExporter", exp)
+	}
+}
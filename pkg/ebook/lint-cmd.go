@@ -0,0 +1,39 @@
+package ebook
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/dpurge/cli-tools/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check the ebook project's text files for markup mistakes",
+	Long: "Parse every text file of the project and report all problems at once, as file:line:column: " +
+		"unknown or malformed block attributes, unterminated or orphaned {start-…}/{end-…} markers, " +
+		"unknown script= codes, vocabulary lines without a phrase, and chapters without an H1 title. " +
+		"Exits non-zero if anything was reported.",
+	Run: func(cmd *cobra.Command, args []string) {
+		project, err := ReadProject(_project)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		problems := lintProject(project)
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+		if len(problems) > 0 {
+			os.Exit(config.ExitCodeError)
+		}
+	},
+}
+
+func init() {
+	mainCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().StringVarP(&_project, "project", "p", "ebook.yml", "eBook project file")
+}
@@ -0,0 +1,41 @@
+package ebook
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dpurge/cli-tools/pkg/tool/markdown"
+)
+
+// lintProject lints every text file of project with markdown.Lint and
+// returns the problems in document order as "file:line:column: message",
+// the form editors and CI logs link to. A file that cannot be read is one
+// more problem, so a single run reports everything.
+func lintProject(project *EBookProject) []string {
+	var problems []string
+	for _, item := range WalkTexts(project.Text) {
+		name := lintPath(item.File)
+		source, err := os.ReadFile(item.File)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		for _, p := range markdown.Lint(source) {
+			problems = append(problems, fmt.Sprintf("%s:%s", name, p))
+		}
+	}
+	return problems
+}
+
+// lintPath shortens file (absolute, as ReadProject resolves it) to a path
+// relative to the working directory when it lies below it.
+func lintPath(file string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, file); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return file
+}
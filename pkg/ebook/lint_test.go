@@ -0,0 +1,34 @@
+package ebook
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestLintProject: every text file is linted in document order, with paths
+// shown relative to the working directory.
+func TestLintProject(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, dir, "section.md", "# Part One\n")
+	writeFixture(t, dir, "clean.md", "# Clean\n\n{start-vocabulary}\nev = house\n{end-vocabulary}\n")
+	writeFixture(t, dir, "broken.md", "Intro\n\n{start-vocabulary scrpt=arab}\n= house\n{end-vocabulary}\n{end-dialog}\n")
+	projectfile := writeFixture(t, dir, "ebook.yml", "filename: book.epub\ntitle: Test\nlanguage: tur\nscript: latn\n"+
+		"text:\n  - [section.md, clean.md, broken.md]\n")
+
+	project, err := ReadProject(projectfile)
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	t.Chdir(dir)
+
+	got := lintProject(project)
+	want := []string{
+		"broken.md:1:1: no H1 title: the chapter title is taken from the first '# ' heading",
+		`broken.md:3:1: unknown attribute "scrpt" on {start-vocabulary}`,
+		"broken.md:4:1: vocabulary item has no phrase",
+		"broken.md:6:1: {end-dialog} without a matching {start-dialog}",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("lintProject =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -21,9 +21,11 @@ import (
 // phraseforge use the project's raw ISO-639-3 Language + lowercase
 // ISO-15924 Script verbatim (SPECS §7.1) — languageInfo's BCP-47 mapping
 // ("tur"->"tr") is EPUB/Typst-only and would be wrong here.
-type mdxExporter struct{}
+type mdxExporter struct {
+	cache *textCache
+}
 
-func (mdxExporter) Export(project *EBookProject) (string, error) {
+func (e mdxExporter) Export(project *EBookProject) (string, error) {
 	dir := derivedMdxDir(project.Filename)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return "", err
@@ -37,8 +39,11 @@ func (mdxExporter) Export(project *EBookProject) (string, error) {
 	// ChapterItem that follows a SectionItem in WalkTexts' document order
 	// belongs under that section's directory (dir itself in the flat/
 	// single-section layout, or a per-section subfolder when multiSection,
-	// SPECS §7.2/Decision D7).
+	// SPECS §7.2/Decision D7). The section directories are created first, so
+	// the chapters can then be converted and written concurrently
+	// (convertTexts).
 	currentDir := dir
+	chapterDirs := make(map[int]string)
 	for _, item := range items {
 		switch item.Kind {
 		case SectionItem:
@@ -48,10 +53,18 @@ func (mdxExporter) Export(project *EBookProject) (string, error) {
 			}
 			currentDir = sectionDir
 		case ChapterItem:
-			if err := writeChapterMDX(currentDir, item.File, project); err != nil {
-				return "", err
-			}
+			chapterDirs[item.ChapterIdx] = currentDir
+		}
+	}
+
+	_, err := convertTexts(items, func(item ProjectItem) (string, error) {
+		if item.Kind != ChapterItem {
+			return "", nil
 		}
+		return "", writeChapterMDX(chapterDirs[item.ChapterIdx], item.File, project, e.cache)
+	})
+	if err != nil {
+		return "", err
 	}
 
 	return dir, nil
@@ -155,12 +168,12 @@ func writeCategoryJSON(rootDir string, item ProjectItem, project *EBookProject,
 	return sectionDir, nil
 }
 
-// writeChapterMDX reads chapterFile once, derives its title (first H1 via
+// writeChapterMDX reads chapterFile, derives its title (first H1 via
 // markdown.Title, falling back to the file's basename when absent, SPECS
 // §7.4/§9), converts its body with markdown.ToMDX using the project's RAW
-// Language/Script (SPECS §7.1 - NOT languageInfo), and writes
+// Language/Script (SPECS §7.1 - NOT languageInfo) through cache, and writes
 // "<basename>.mdx" (frontmatter + body) into dir.
-func writeChapterMDX(dir, chapterFile string, project *EBookProject) error {
+func writeChapterMDX(dir, chapterFile string, project *EBookProject, cache *textCache) error {
 	src, err := os.ReadFile(chapterFile)
 	if err != nil {
 		return err
@@ -175,7 +188,9 @@ func writeChapterMDX(dir, chapterFile string, project *EBookProject) error {
 		title = base
 	}
 
-	body, err := markdown.ToMDX(src, project.Language, project.Script)
+	body, err := cache.convert(project, "mdx", chapterFile, func(source []byte) ([]byte, error) {
+		return markdown.ToMDX(source, project.Language, project.Script)
+	})
 	if err != nil {
 		return err
 	}
@@ -185,13 +200,13 @@ func writeChapterMDX(dir, chapterFile string, project *EBookProject) error {
 	doc.WriteString("title: " + mdxYamlString(title) + "\n")
 	doc.WriteString("description: " + mdxYamlString(project.Description) + "\n")
 	doc.WriteString("---\n\n")
-	doc.Write(body)
+	doc.WriteString(body)
 
 	return os.WriteFile(filepath.Join(dir, base+".mdx"), []byte(doc.String()), 0o644)
 }
 
 // mdxYamlString double-quotes s as a single-line YAML scalar for chapter
-// frontmatter (SPECS §5.4, mirrors typstStringLiteral, typst.go:157-178,
+// frontmatter (SPECS §5.4, mirrors TypstStringLiteral, typst.go:157-178,
 // for the analogous string-context escaping need): "\\" and '"' are
 // escaped; any newline/tab is collapsed to a single space and the result is
 // then trimmed, so a multi-line YAML block-scalar project.Description
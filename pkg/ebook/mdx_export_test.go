@@ -279,7 +279,7 @@ func TestMdxExporterMultiSection(t *testing.T) {
 // --- build-cmd.go --format mdx dispatch (SPECS §8, FR-11) -----------------
 
 func TestExporterForMdx(t *testing.T) {
-	exp, err := exporterFor("mdx")
+	exp, err := exporterFor("mdx", nil)
 	if err != nil {
 		t.Fatalf("exporterFor(\"mdx\") error = %v", err)
 	}
@@ -287,7 +287,7 @@ func TestExporterForMdx(t *testing.T) {
 		t.Errorf("exporterFor(\"mdx\") = %T, want mdxExporter", exp)
 	}
 
-	if _, err := exporterFor("bogus"); err == nil {
+	if _, err := exporterFor("bogus", nil); err == nil {
 		t.Error("exporterFor(\"bogus\") expected an error, got nil")
 	} else if !strings.Contains(err.Error(), "epub|pdf|mdx") {
 		t.Errorf("exporterFor(\"bogus\") error = %v, want it to mention epub|pdf|mdx", err)
@@ -307,7 +307,7 @@ func TestExporterForMdx(t *testing.T) {
 // every file this test produces lands under the temp dir and the
 // epub-public checkout is never written to. project.Text/Cover/Stylesheet
 // keep pointing at the real sample files, which mdxExporter only reads
-// (never writes) - so loading the project via the normal readProject (which
+// (never writes) - so loading the project via the normal ReadProject (which
 // validates Cover/Stylesheet/Font/Image paths against the real, intact
 // on-disk layout) is safe and avoids having to replicate the sample's
 // relative-path asset tree (stylesheets reach three directories up to a
@@ -318,9 +318,9 @@ func TestMdxExporterIntegrationTurSample(t *testing.T) {
 		t.Skip("tur sample project not found (expected a sibling epub-public checkout at ../../../epub-public); skipping MDX integration test")
 	}
 
-	project, err := readProject(projectFile)
+	project, err := ReadProject(projectFile)
 	if err != nil {
-		t.Fatalf("readProject(%q) error = %v", projectFile, err)
+		t.Fatalf("ReadProject(%q) error = %v", projectFile, err)
 	}
 
 	tmp := t.TempDir()
@@ -32,7 +32,10 @@ type EBookStyles struct {
 	Common  []string `yaml:"common,omitempty"`
 }
 
-func readProject(filename string) (*EBookProject, error) {
+// ReadProject loads the ebook project file and resolves every path in it
+// (output, cover, stylesheets, fonts, images and texts) relative to the
+// project file's directory.
+func ReadProject(filename string) (*EBookProject, error) {
 
 	buf, err := os.ReadFile(filename)
 	if err != nil {
@@ -92,5 +95,35 @@ func readProject(filename string) (*EBookProject, error) {
 		}
 	}
 
+	// Stylesheets, fonts and images each land in one flat directory of the
+	// book (and of the preview server), named by their basename.
+	stylesheets := append([]string{project.Stylesheet.Cover, project.Stylesheet.Section, project.Stylesheet.Chapter}, project.Stylesheet.Common...)
+	if err = checkBasenames("stylesheet", stylesheets); err != nil {
+		return nil, err
+	}
+	if err = checkBasenames("font", project.Font); err != nil {
+		return nil, err
+	}
+	if err = checkBasenames("image", append([]string{project.Cover}, project.Image...)); err != nil {
+		return nil, err
+	}
+
 	return project, err
 }
+
+// checkBasenames reports two different files among paths that share a
+// basename; listing the same file twice is fine.
+func checkBasenames(kind string, paths []string) error {
+	seen := map[string]string{}
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		name := filepath.Base(path)
+		if other, ok := seen[name]; ok && other != path {
+			return fmt.Errorf("%s %q and %q have the same file name %q", kind, other, path, name)
+		}
+		seen[name] = path
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package ebook
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestReadProjectBasenames: stylesheets, fonts and images are stored by
+// basename, so two different files with the same name are rejected, while
+// the same file listed twice is not.
+func TestReadProjectBasenames(t *testing.T) {
+	tests := []struct {
+		name    string
+		project string
+		wantErr string
+	}{
+		{"distinct", "stylesheet:\n  common: [a/style.css, b/other.css]\nimage: [a/pic.png]\n", ""},
+		{"same file twice", "cover: a/pic.png\nimage: [a/pic.png]\n", ""},
+		{"stylesheets", "stylesheet:\n  chapter: a/style.css\n  common: [b/style.css]\n", `stylesheet "`},
+		{"fonts", "font: [a/font.ttf, b/font.ttf]\n", `font "`},
+		{"cover and image", "cover: a/pic.png\nimage: [b/pic.png]\n", `same file name "pic.png"`},
+		{"other kinds", "stylesheet:\n  common: [a/pic.png]\nimage: [b/pic.png]\n", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, sub := range []string{"a", "b"} {
+				if err := os.Mkdir(filepath.Join(dir, sub), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				for _, name := range []string{"style.css", "other.css", "font.ttf", "pic.png"} {
+					writeFixture(t, dir, filepath.Join(sub, name), "")
+				}
+			}
+			projectfile := writeFixture(t, dir, "ebook.yml", "filename: book.epub\ntitle: T\n"+tc.project)
+
+			_, err := ReadProject(projectfile)
+			switch {
+			case tc.wantErr == "" && err != nil:
+				t.Errorf("ReadProject: %v", err)
+			case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+				t.Errorf("ReadProject error = %v, want one containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
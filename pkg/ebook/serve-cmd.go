@@ -0,0 +1,33 @@
+package ebook
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+)
+
+var _address string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Preview ebook project in a browser",
+	Long: "Serve a live HTML preview of the project: a table of contents and one page per section and chapter, " +
+		"rendered with the project's stylesheets. Open pages reload when a source file changes.",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		if err := servePreview(ctx, _project, _address); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	mainCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVarP(&_project, "project", "p", "ebook.yml", "eBook project file")
+	serveCmd.Flags().StringVarP(&_address, "address", "a", "localhost:8080", "address to listen on")
+}
@@ -0,0 +1,302 @@
+package ebook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dpurge/cli-tools/pkg/tool/markdown"
+)
+
+// previewServer serves a live HTML preview of an ebook project: an index
+// with the table of contents, one page per section or chapter rendered with
+// markdown.ToHTML, and the project's stylesheets, fonts and images. The
+// project and its texts are re-read on every request, so a page is always
+// current; the reload channel only tells open pages when to ask again.
+//
+// URLs mirror go-epub's layout inside the EPUB (xhtml/, css/, fonts/,
+// images/), so relative references that work in the book — url(../fonts/…)
+// in a stylesheet, ../images/… in a chapter — work in the preview too.
+type previewServer struct {
+	projectfile string
+
+	mu     sync.Mutex
+	reload chan struct{} // closed, and replaced, on every change
+}
+
+func newPreviewServer(projectfile string) *previewServer {
+	return &previewServer{projectfile: projectfile, reload: make(chan struct{})}
+}
+
+// changed wakes every page waiting on /_reload.
+func (s *previewServer) changed() {
+	s.mu.Lock()
+	close(s.reload)
+	s.reload = make(chan struct{})
+	s.mu.Unlock()
+}
+
+func (s *previewServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.serveIndex)
+	mux.HandleFunc("GET /xhtml/{page}", s.servePage)
+	mux.HandleFunc("GET /css/{name}", s.serveAsset(func(p *EBookProject) []string {
+		return append([]string{p.Stylesheet.Cover, p.Stylesheet.Section, p.Stylesheet.Chapter}, p.Stylesheet.Common...)
+	}))
+	mux.HandleFunc("GET /fonts/{name}", s.serveAsset(func(p *EBookProject) []string { return p.Font }))
+	mux.HandleFunc("GET /images/{name}", s.serveAsset(func(p *EBookProject) []string { return append([]string{p.Cover}, p.Image...) }))
+	mux.HandleFunc("GET /_reload", s.serveReload)
+	return mux
+}
+
+// previewPage is the data for previewTemplate. Exactly one of Body, TOC and
+// Error is set.
+type previewPage struct {
+	Lang, Dir   string
+	Title       string
+	Stylesheets []string
+	Prev, Next  string
+	Body        template.HTML
+	TOC         []tocEntry
+	Error       string
+}
+
+// tocEntry is one section of the table of contents, with its chapters.
+type tocEntry struct {
+	Title    string
+	Page     string
+	Chapters []tocEntry
+}
+
+// tableOfContents lists items' sections, each with its chapters, titled by
+// textTitle and linked by pageName.
+func tableOfContents(items []ProjectItem) []tocEntry {
+	var toc []tocEntry
+	for _, item := range items {
+		entry := tocEntry{Title: textTitle(item.File), Page: pageName(item)}
+		if item.Kind == SectionItem {
+			toc = append(toc, entry)
+		} else {
+			section := &toc[len(toc)-1]
+			section.Chapters = append(section.Chapters, entry)
+		}
+	}
+	return toc
+}
+
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}" dir="{{.Dir}}">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+{{range .Stylesheets}}<link rel="stylesheet" href="/css/{{.}}">
+{{end}}<style>
+.preview-nav { display: flex; gap: 1em; justify-content: center; margin: 1em 0; font-family: sans-serif; font-size: 0.9em; }
+.preview-error { color: #a00; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+{{define "nav"}}<nav class="preview-nav" dir="ltr">{{if .Prev}}<a href="/xhtml/{{.Prev}}">&larr; Previous</a>{{end}}<a href="/">Contents</a>{{if .Next}}<a href="/xhtml/{{.Next}}">Next &rarr;</a>{{end}}</nav>{{end}}
+{{- if .TOC}}<h1>{{.Title}}</h1>
+<nav class="preview-toc">
+<ol>
+{{range .TOC}}<li><a href="/xhtml/{{.Page}}">{{.Title}}</a>{{if .Chapters}}
+<ol>
+{{range .Chapters}}<li><a href="/xhtml/{{.Page}}">{{.Title}}</a></li>
+{{end}}</ol>
+{{end}}</li>
+{{end}}</ol>
+</nav>
+{{else if .Error}}{{template "nav" .}}
+<pre class="preview-error">{{.Error}}</pre>
+{{else}}{{template "nav" .}}
+{{.Body}}
+{{template "nav" .}}
+{{end}}<script>new EventSource("/_reload").onmessage = function () { location.reload(); };</script>
+</body>
+</html>
+`))
+
+// pageName is the file name of a section or chapter page in the preview
+// and the HTML site (html.go): the EPUB's internal file name (epub.go) with
+// an .html extension.
+func pageName(item ProjectItem) string {
+	if item.Kind == SectionItem {
+		return fmt.Sprintf("section%04d.html", item.SectionIdx)
+	}
+	return fmt.Sprintf("chapter%04d.html", item.ChapterIdx)
+}
+
+// textTitle is a text file's first H1, or its basename when it has none
+// or cannot be read, so the index lists every file even while one is broken.
+func textTitle(file string) string {
+	if src, err := os.ReadFile(file); err == nil {
+		if title, err := markdown.Title(src); err == nil && title != "" {
+			return title
+		}
+	}
+	return strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+}
+
+func basenames(files ...string) []string {
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		if file != "" {
+			names = append(names, filepath.Base(file))
+		}
+	}
+	return names
+}
+
+func (s *previewServer) render(w http.ResponseWriter, status int, page previewPage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := previewTemplate.Execute(w, page); err != nil {
+		log.Print(err)
+	}
+}
+
+func (s *previewServer) serveIndex(w http.ResponseWriter, r *http.Request) {
+	project, err := ReadProject(s.projectfile)
+	if err != nil {
+		s.render(w, http.StatusInternalServerError, previewPage{Lang: "en", Dir: "ltr", Title: "Preview", Error: err.Error()})
+		return
+	}
+
+	page := previewPage{Title: project.Title, Stylesheets: basenames(project.Stylesheet.Common...)}
+	page.Lang, page.Dir = languageInfo(project.Language, project.Script)
+	page.TOC = tableOfContents(WalkTexts(project.Text))
+	if len(page.TOC) == 0 {
+		page.Error = "the project lists no text files"
+	}
+	s.render(w, http.StatusOK, page)
+}
+
+func (s *previewServer) servePage(w http.ResponseWriter, r *http.Request) {
+	project, err := ReadProject(s.projectfile)
+	if err != nil {
+		s.render(w, http.StatusInternalServerError, previewPage{Lang: "en", Dir: "ltr", Title: "Preview", Error: err.Error()})
+		return
+	}
+
+	items := WalkTexts(project.Text)
+	for i, item := range items {
+		if pageName(item) != r.PathValue("page") {
+			continue
+		}
+
+		page := previewPage{Title: textTitle(item.File)}
+		page.Lang, page.Dir = languageInfo(project.Language, project.Script)
+		if item.Kind == SectionItem {
+			page.Stylesheets = basenames(append(project.Stylesheet.Common, project.Stylesheet.Section)...)
+		} else {
+			page.Stylesheets = basenames(append(project.Stylesheet.Common, project.Stylesheet.Chapter)...)
+		}
+		if i > 0 {
+			page.Prev = pageName(items[i-1])
+		}
+		if i < len(items)-1 {
+			page.Next = pageName(items[i+1])
+		}
+
+		body, err := markdown.FileToHTML(item.File)
+		if err != nil {
+			page.Error = err.Error()
+			s.render(w, http.StatusInternalServerError, page)
+			return
+		}
+		page.Body = template.HTML(body)
+		s.render(w, http.StatusOK, page)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// serveAsset serves a file listed in the project (as picked by files) by
+// its basename, which ReadProject keeps unique within each kind; anything
+// the project does not list is not found.
+func (s *previewServer) serveAsset(files func(*EBookProject) []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		project, err := ReadProject(s.projectfile)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, file := range files(project) {
+			if file != "" && filepath.Base(file) == r.PathValue("name") {
+				w.Header().Set("Cache-Control", "no-store")
+				http.ServeFile(w, r, file)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}
+}
+
+// serveReload is the Server-Sent Events stream every page listens to: it
+// sends one "reload" event at the next change, then ends.
+func (s *previewServer) serveReload(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	reload := s.reload
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	io.WriteString(w, ": waiting for changes\n\n")
+	http.NewResponseController(w).Flush()
+
+	select {
+	case <-reload:
+		io.WriteString(w, "data: reload\n\n")
+		http.NewResponseController(w).Flush()
+	case <-r.Context().Done():
+	}
+}
+
+// servePreview serves the project's live preview on addr until ctx is
+// done, telling open pages to reload whenever one of the project's input
+// files changes (watchProject).
+func servePreview(ctx context.Context, projectfile, addr string) error {
+	projectfile, err := filepath.Abs(projectfile)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	s := newPreviewServer(projectfile)
+	server := &http.Server{Handler: s.handler()}
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+	fmt.Printf("http://%s/\n", listener.Addr())
+
+	err = watchProject(ctx, projectfile, func() *EBookProject {
+		s.changed()
+		project, err := ReadProject(projectfile)
+		if err != nil {
+			log.Print(err)
+			return nil
+		}
+		return project
+	})
+
+	// Close rather than Shutdown: open /_reload streams never go idle.
+	server.Close()
+	if serveErr := <-served; !errors.Is(serveErr, http.ErrServerClosed) {
+		return serveErr
+	}
+	return err
+}
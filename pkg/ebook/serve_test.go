@@ -0,0 +1,129 @@
+package ebook
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newPreviewFixture serves a one-section, two-chapter project whose second
+// chapter has a malformed block.
+func newPreviewFixture(t *testing.T) (*previewServer, *httptest.Server) {
+	t.Helper()
+	dir := t.TempDir()
+	writeFixture(t, dir, "base.css", "body { margin: 0; }")
+	writeFixture(t, dir, "chapter.css", "h1 { color: red; }")
+	writeFixture(t, dir, "section.md", "# Part One\n")
+	writeFixture(t, dir, "dialog.md", "# Dialog\n\n{start-dialog}\n@Ali:\n  Merhaba\n{end-dialog}\n")
+	writeFixture(t, dir, "broken.md", "# Broken\n\n{start-vocabulary as=translation}\nev = house\n{end-vocabulary}\n")
+	projectfile := writeFixture(t, dir, "ebook.yml", "filename: book.epub\ntitle: Türkçe\nlanguage: tur\nscript: latn\n"+
+		"stylesheet:\n  common: [base.css]\n  chapter: chapter.css\n"+
+		"text:\n  - [section.md, dialog.md, broken.md]\n")
+
+	s := newPreviewServer(projectfile)
+	server := httptest.NewServer(s.handler())
+	t.Cleanup(server.Close)
+	return s, server
+}
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read %s: %v", url, err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestPreviewServer(t *testing.T) {
+	_, server := newPreviewFixture(t)
+
+	tests := []struct {
+		path   string
+		status int
+		want   []string
+	}{
+		{"/", http.StatusOK, []string{
+			`<html lang="tr" dir="ltr">`,
+			`<link rel="stylesheet" href="/css/base.css">`,
+			`<li><a href="/xhtml/section0001.html">Part One</a>`,
+			`<li><a href="/xhtml/chapter0001.html">Dialog</a></li>`,
+			`<li><a href="/xhtml/chapter0002.html">Broken</a></li>`,
+			`new EventSource("/_reload")`,
+		}},
+		{"/xhtml/chapter0001.html", http.StatusOK, []string{
+			`<title>Dialog</title>`,
+			`<link rel="stylesheet" href="/css/base.css">` + "\n" + `<link rel="stylesheet" href="/css/chapter.css">`,
+			`<a href="/xhtml/section0001.html">&larr; Previous</a><a href="/">Contents</a><a href="/xhtml/chapter0002.html">Next &rarr;</a>`,
+			`<div class="dialog`,
+			`Merhaba`,
+		}},
+		{"/xhtml/chapter0002.html", http.StatusInternalServerError, []string{
+			`<pre class="preview-error">`,
+			`broken.md:3: as= not applicable to {start-vocabulary}`,
+			`new EventSource("/_reload")`,
+		}},
+		{"/css/chapter.css", http.StatusOK, []string{"h1 { color: red; }"}},
+		{"/xhtml/chapter0003.html", http.StatusNotFound, nil},
+		{"/css/ebook.yml", http.StatusNotFound, nil},
+		{"/fonts/base.css", http.StatusNotFound, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			status, body := get(t, server.URL+tc.path)
+			if status != tc.status {
+				t.Fatalf("status = %d, want %d\n%s", status, tc.status, body)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("response is missing %q\n%s", want, body)
+				}
+			}
+		})
+	}
+}
+
+// TestPreviewServerReload: a page's /_reload stream gets one reload event
+// when the project changes.
+func TestPreviewServerReload(t *testing.T) {
+	s, server := newPreviewFixture(t)
+
+	resp, err := http.Get(server.URL + "/_reload")
+	if err != nil {
+		t.Fatalf("GET /_reload: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	events := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if strings.HasPrefix(scanner.Text(), "data: ") {
+				events <- scanner.Text()
+			}
+		}
+		close(events)
+	}()
+
+	s.changed()
+	select {
+	case event := <-events:
+		if event != "data: reload" {
+			t.Fatalf("event = %q, want data: reload", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload event after a change")
+	}
+}
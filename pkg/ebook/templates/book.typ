@@ -79,13 +79,13 @@
 // _baseRoleFor mirrors typst.go's baseRoleForField (SPECS §4's "BaseRole(F)
 // map"): Source/Content/Main/Question/Answer/Phrase -> Body (or Translation
 // when as-translation); Transcription -> Transcription; Translation/
-// Secondary/Grammar -> Translation; Tag/Header -> Header.
+// Secondary/Grammar/Gloss -> Translation; Tag/Header -> Header.
 #let _baseRoleFor(field, as-translation) = {
   if field in ("source", "content", "main", "question", "answer", "phrase") {
     if as-translation { "translation" } else { "body" }
   } else if field == "transcription" {
     "transcription"
-  } else if field in ("translation", "secondary", "grammar") {
+  } else if field in ("translation", "secondary", "grammar", "gloss") {
     "translation"
   } else if field in ("tag", "header") {
     "header"
@@ -631,6 +631,49 @@
   ))
 }
 
+// interlinear(): Leipzig-style glossed examples. Each word is its own
+// unbreakable box stacking the source token over the optional
+// transliteration over the gloss, so the columns stay aligned wherever the
+// line wraps between words. dir orders the words (an RTL source runs right
+// to left); transliteration, gloss and free translation are pinned ltr. The
+// transliteration key is present only when the example has that line
+// (dict-key-presence idiom, mirrors parallel()'s "transcription" in r).
+// _glossLabels sets category labels (all-uppercase runs: PL, 3SG, ERG) in
+// small caps — the same pattern the Go side's glossLabelRe wraps in EPUB.
+#let _glossLabels(gloss) = {
+  show regex("\b[0-9]*[A-Z][0-9A-Z]*\b"): it => smallcaps(lower(it.text))
+  [#gloss]
+}
+
+#let interlinear(dir: ltr, script: "", ..items) = {
+  set par(first-line-indent: 0pt)
+  for it in items.pos() {
+    let k = it.at("kind", default: "data")
+    if k == "header" {
+      _blockheading(it.at("level"), it.at("text"))
+    } else if k == "note" {
+      _blocknote(it.at("text"))
+    } else {
+      block(above: 0.8em, below: 0.8em, breakable: false, {
+        block(below: 0.5em, {
+          set text(dir: dir)
+          set par(justify: false)
+          it.at("words").map(w => box(stack(
+            dir: ttb,
+            spacing: 0.3em,
+            context text(font: _resolveFont(script: script, ext: "interlinear", field: "source"), size: _foreignSize(script), w.at("source")),
+            ..if "transliteration" in w {
+              (emph(context text(font: _resolveFont(script: "latn", ext: "interlinear", field: "transcription"), dir: ltr, w.at("transliteration"))),)
+            } else { () },
+            context text(font: _resolveFont(script: "", ext: "interlinear", field: "gloss"), dir: ltr, size: 0.9 * _baseSize(), _glossLabels(w.at("gloss"))),
+          ))).join(h(1em))
+        })
+        context text(font: _resolveFont(script: "", ext: "interlinear", field: "translation"), dir: ltr, size: _baseSize(), it.at("translation"))
+      })
+    }
+  }
+}
+
 #let book(
   title: none,
   author: none,
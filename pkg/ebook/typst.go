@@ -21,21 +21,39 @@ import (
 //go:embed templates/book.typ
 var bookTemplate string
 
+// BookTemplate returns the embedded book.typ. Besides book() it defines the
+// functions markdown.ToTypst output calls (#vocabulary, #dialog, #_ctbadge,
+// ...), whose state all has defaults, so any other Typst document that
+// embeds converted markdown — flashcard-cli's printable cards — includes it
+// ahead of its own template.
+func BookTemplate() string {
+	return bookTemplate
+}
+
 // typstExporter implements Exporter by assembling one self-contained Typst
 // document and compiling it with the `typst` binary.
-type typstExporter struct{}
+type typstExporter struct {
+	cache *textCache
+}
 
-func (typstExporter) Export(project *EBookProject) (string, error) {
+// Export compiles the book and prints Typst's warnings, if any, to stderr.
+func (e typstExporter) Export(project *EBookProject) (string, error) {
+	pdfPath, warnings, err := e.exportWarnings(project)
+	fmt.Fprint(os.Stderr, warnings)
+	return pdfPath, err
+}
+
+// exportWarnings compiles the book and returns Typst's warnings (e.g.
+// missing-font substitutions) from a successful compile instead of printing
+// them, for exportAll to report with the other formats' results.
+func (e typstExporter) exportWarnings(project *EBookProject) (string, string, error) {
 	lang, dir := languageInfo(project.Language, project.Script)
 
-	items := WalkTexts(project.Text)
-	bodies := make([]string, 0, len(items))
-	for _, item := range items {
-		content, err := markdown.FileToTypst(item.File)
-		if err != nil {
-			return "", err
-		}
-		bodies = append(bodies, content)
+	bodies, err := convertTexts(WalkTexts(project.Text), func(item ProjectItem) (string, error) {
+		return e.cache.convert(project, "typst", item.File, markdown.ToTypst)
+	})
+	if err != nil {
+		return "", "", err
 	}
 
 	pdfPath, typPath := derivedTypstPaths(project.Filename)
@@ -43,23 +61,23 @@ func (typstExporter) Export(project *EBookProject) (string, error) {
 
 	cover, err := typstAssetPath(rootDir, project.Cover)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	document, err := assembleTypstDocument(project, lang, dir, cover, bodies, config.GetPdfConfig())
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	// Left in place (not a temp file) so it survives for debugging a compile
 	// failure (SPECS §9).
 	if err := os.WriteFile(typPath, []byte(document), 0o644); err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	typstPath, err := locateTypst()
+	typstPath, err := LocateTypst()
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	args := []string{"compile", typPath, pdfPath, "--root", rootDir}
@@ -67,17 +85,16 @@ func (typstExporter) Export(project *EBookProject) (string, error) {
 		args = append(args, "--font-path", fontDir)
 	}
 
-	output, err := runTypst(typstPath, args...)
+	output, err := RunTypst(typstPath, args...)
 	if err != nil {
-		return "", fmt.Errorf("typst compile failed: %s", output)
+		return "", "", fmt.Errorf("typst compile failed: %s", output)
 	}
-	// Surface Typst's warnings (e.g. missing-font substitutions) on a successful
-	// compile; otherwise they are silently discarded.
-	if strings.TrimSpace(output) != "" {
-		fmt.Fprint(os.Stderr, output)
+	// Typst's warnings on a successful compile; otherwise they would be
+	// silently discarded.
+	if strings.TrimSpace(output) == "" {
+		output = ""
 	}
-
-	return pdfPath, nil
+	return pdfPath, output, nil
 }
 
 // assembleTypstDocument builds the full `.typ` source: the embedded book.typ
@@ -90,17 +107,17 @@ func assembleTypstDocument(project *EBookProject, lang, dir, cover string, bodie
 
 	doc.WriteString(bookTemplate)
 	doc.WriteString("\n#show: book.with(\n")
-	doc.WriteString("  title: " + typstStringLiteral(project.Title) + ",\n")
+	doc.WriteString("  title: " + TypstStringLiteral(project.Title) + ",\n")
 	// author is ALWAYS passed, even as "": book.typ does `set document(author:
 	// author)`, and Typst's document() rejects `none` (compile error), so an
 	// omitted author would break every author-less build.
-	doc.WriteString("  author: " + typstStringLiteral(project.Author) + ",\n")
+	doc.WriteString("  author: " + TypstStringLiteral(project.Author) + ",\n")
 	if project.Description != "" {
-		doc.WriteString("  description: " + typstStringLiteral(project.Description) + ",\n")
+		doc.WriteString("  description: " + TypstStringLiteral(project.Description) + ",\n")
 	}
 	// lang is emitted as the bare primary subtag (typstLang): `set text(lang:)`
 	// wants the ISO 639 subtag, not the full BCP-47 tag languageInfo returns.
-	doc.WriteString("  lang: " + typstStringLiteral(typstLang(lang)) + ",\n")
+	doc.WriteString("  lang: " + TypstStringLiteral(typstLang(lang)) + ",\n")
 	// dir MUST be emitted unquoted: book.typ's `dir` is Typst's `direction`
 	// type (bare ltr/rtl keywords), not a string.
 	doc.WriteString("  dir: " + dir + ",\n")
@@ -112,22 +129,22 @@ func assembleTypstDocument(project *EBookProject, lang, dir, cover string, bodie
 	// FR-7: explicit per-book override wins; catalog lookup provides a language-
 	// specific default; empty result leaves book.typ's [Contents] default in place.
 	if ct := resolveContentsTitle(project.ContentsTitle, lang); ct != "" {
-		doc.WriteString("  contents-title: " + typstStringLiteral(ct) + ",\n")
+		doc.WriteString("  contents-title: " + TypstStringLiteral(ct) + ",\n")
 	}
 	if cover != "" {
-		doc.WriteString("  cover: " + typstStringLiteral(cover) + ",\n")
+		doc.WriteString("  cover: " + TypstStringLiteral(cover) + ",\n")
 	}
 
 	// Optional Pdf overrides. paper/font are quoted (injection-safe); lengths
-	// are emitted unquoted so each is validated first (typstLength).
+	// are emitted unquoted so each is validated first (TypstLength).
 	if cfg.Paper != "" {
-		doc.WriteString("  paper: " + typstStringLiteral(cfg.Paper) + ",\n")
+		doc.WriteString("  paper: " + TypstStringLiteral(cfg.Paper) + ",\n")
 	}
 	writeLen := func(configKey, value, arg string) error {
 		if value == "" {
 			return nil
 		}
-		v, err := typstLength(configKey, value)
+		v, err := TypstLength(configKey, value)
 		if err != nil {
 			return err
 		}
@@ -182,7 +199,7 @@ func assembleTypstDocument(project *EBookProject, lang, dir, cover string, bodie
 		doc.WriteString("  font-slots: " + typstFontSlotsDict(slots) + ",\n")
 	}
 	if project.Script != "" {
-		doc.WriteString("  book-script: " + typstStringLiteral(strings.ToLower(project.Script)) + ",\n")
+		doc.WriteString("  book-script: " + TypstStringLiteral(strings.ToLower(project.Script)) + ",\n")
 	}
 
 	doc.WriteString(")\n\n")
@@ -198,9 +215,9 @@ func assembleTypstDocument(project *EBookProject, lang, dir, cover string, bodie
 // unquoted into Typst source.
 var typstLengthRe = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(pt|mm|cm|in|em)$`)
 
-// typstLength validates a configured Typst length (e.g. "12pt") and returns it
+// TypstLength validates a configured Typst length (e.g. "12pt") and returns it
 // trimmed, ready to emit unquoted. configKey names the field in the error.
-func typstLength(configKey, value string) (string, error) {
+func TypstLength(configKey, value string) (string, error) {
 	v := strings.TrimSpace(value)
 	if !typstLengthRe.MatchString(v) {
 		return "", fmt.Errorf("invalid Typst length for %s: %q (want e.g. 12pt, 1cm, 1.5in)", configKey, value)
@@ -227,7 +244,7 @@ func typstMarginDict(m config.PdfMargin) (string, error) {
 		if strings.TrimSpace(s.value) == "" {
 			continue
 		}
-		v, err := typstLength("Pdf.margin."+s.name, s.value)
+		v, err := TypstLength("Pdf.margin."+s.name, s.value)
 		if err != nil {
 			return "", err
 		}
@@ -246,7 +263,7 @@ func typstMarginDict(m config.PdfMargin) (string, error) {
 func typstFontArray(fonts []string) string {
 	lits := make([]string, len(fonts))
 	for i, f := range fonts {
-		lits[i] = typstStringLiteral(f)
+		lits[i] = TypstStringLiteral(f)
 	}
 	joined := strings.Join(lits, ", ")
 	if len(lits) == 1 {
@@ -268,7 +285,7 @@ func typstFontSlotsDict(slots map[string]string) string {
 	sort.Strings(keys)
 	parts := make([]string, 0, len(keys))
 	for _, k := range keys {
-		parts = append(parts, typstStringLiteral(k)+": "+typstStringLiteral(slots[k]))
+		parts = append(parts, TypstStringLiteral(k)+": "+TypstStringLiteral(slots[k]))
 	}
 	return "(" + strings.Join(parts, ", ") + ")"
 }
@@ -304,13 +321,14 @@ var (
 var fontExtensions = map[string]bool{
 	"text": true, "dialog": true, "questions": true,
 	"vocabulary": true, "models": true, "parallel": true,
+	"interlinear": true,
 }
 
 var fontFields = map[string]bool{
 	"source": true, "transcription": true, "translation": true, "grammar": true,
 	"phrase": true, "question": true, "answer": true, "content": true,
 	"main": true, "secondary": true, "tag": true, "header": true,
-	"note": true,
+	"note": true, "gloss": true,
 }
 
 var fontStyles = map[string]bool{"strong": true, "emphasis": true}
@@ -421,8 +439,8 @@ func (t FontTable) Slots() map[string]string {
 
 // baseRoleForField implements SPECS §4's "BaseRole(F) map": Source/Content/
 // Main/Question/Answer/Phrase -> Body; Transcription -> Transcription;
-// Translation/Secondary/Grammar -> Translation; Tag/Header -> Header. When
-// asTranslation is true (a block's as=translation, mirroring
+// Translation/Secondary/Grammar/Gloss -> Translation; Tag/Header -> Header.
+// When asTranslation is true (a block's as=translation, mirroring
 // {start-text as=translation}), the primary-text fields resolve Translation
 // instead of Body.
 func baseRoleForField(field string, asTranslation bool) string {
@@ -434,7 +452,7 @@ func baseRoleForField(field string, asTranslation bool) string {
 		return "body"
 	case "transcription":
 		return "transcription"
-	case "translation", "secondary", "grammar":
+	case "translation", "secondary", "grammar", "gloss":
 		return "translation"
 	case "tag", "header":
 		return "header"
@@ -582,7 +600,7 @@ func typstLang(tag string) string {
 // lowercased — the same view a real build resolves against, so `doctor` checks
 // against exactly that.
 func typstFontFamilies(typstPath string) (map[string]bool, error) {
-	out, err := runTypst(typstPath, "fonts")
+	out, err := RunTypst(typstPath, "fonts")
 	if err != nil {
 		return nil, fmt.Errorf("listing typst fonts: %s", strings.TrimSpace(out))
 	}
@@ -632,10 +650,10 @@ func typstAssetPath(rootDir, path string) (string, error) {
 	return "/" + filepath.ToSlash(rel), nil
 }
 
-// typstStringLiteral quotes s as a Typst string literal, delegating body
+// TypstStringLiteral quotes s as a Typst string literal, delegating body
 // escaping to the shared tool.EscapeQuoted (also used by scanbook's JS writer,
 // which differs only in quote character).
-func typstStringLiteral(s string) string {
+func TypstStringLiteral(s string) string {
 	return `"` + tool.EscapeQuoted(s, '"') + `"`
 }
 
@@ -661,10 +679,10 @@ func fontPathDirs(fontFiles []string) []string {
 	return dirs
 }
 
-// locateTypst finds the typst binary via config (Typst.typst) then PATH. The
+// LocateTypst finds the typst binary via config (Typst.typst) then PATH. The
 // explicit exec.LookPath covers an entirely absent config file, where
 // GetToolPath returns an error rather than resolving via PATH.
-func locateTypst() (string, error) {
+func LocateTypst() (string, error) {
 	if path, err := config.GetToolPath("Typst", "typst"); err == nil {
 		return path, nil
 	}
@@ -674,9 +692,9 @@ func locateTypst() (string, error) {
 	return "", fmt.Errorf("typst not found: set Typst.typst in config or install on PATH (https://typst.app)")
 }
 
-// runTypst runs the resolved typst binary with args, mirroring tool.RunCmd but
+// RunTypst runs the resolved typst binary with args, mirroring tool.RunCmd but
 // taking an already-resolved path instead of doing a config lookup.
-func runTypst(path string, args ...string) (string, error) {
+func RunTypst(path string, args ...string) (string, error) {
 	cmd := exec.Command(path, args...)
 	buf, err := cmd.CombinedOutput()
 	return string(buf), err
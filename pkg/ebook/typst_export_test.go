@@ -275,36 +275,36 @@ func TestEPUBExporterGlobalChapterNumbering(t *testing.T) {
 // --- build-cmd.go --format dispatch (FR-8) --------------------------------
 
 func TestExporterFor(t *testing.T) {
-	if exp, err := exporterFor("epub"); err != nil {
+	if exp, err := exporterFor("epub", nil); err != nil {
 		t.Errorf("exporterFor(\"epub\") error = %v", err)
 	} else if _, ok := exp.(epubExporter); !ok {
 		t.Errorf("exporterFor(\"epub\") = %T, want epubExporter", exp)
 	}
 
-	if exp, err := exporterFor("pdf"); err != nil {
+	if exp, err := exporterFor("pdf", nil); err != nil {
 		t.Errorf("exporterFor(\"pdf\") error = %v", err)
 	} else if _, ok := exp.(typstExporter); !ok {
 		t.Errorf("exporterFor(\"pdf\") = %T, want typstExporter", exp)
 	}
 
-	if _, err := exporterFor("bogus"); err == nil {
+	if _, err := exporterFor("bogus", nil); err == nil {
 		t.Error("exporterFor(\"bogus\") expected an error, got nil")
 	}
 }
 
-// --- locateTypst fallback (SPECS §8.5) ------------------------------------
+// --- LocateTypst fallback (SPECS §8.5) ------------------------------------
 
 func TestLocateTypstFallsBackToPath(t *testing.T) {
 	// No config file is loaded in this test binary (config.ReadConfig is
 	// never called - see the package doc note in this file), so
 	// config.GetToolPath("Typst", "typst") always errors here and
-	// locateTypst must fall back to exec.LookPath. This only proves the
+	// LocateTypst must fall back to exec.LookPath. This only proves the
 	// fallback branch executes; whether it actually finds a binary depends
 	// on the host, which is why every other test that needs a real typst
 	// skips cleanly when it doesn't.
-	_, err := locateTypst()
+	_, err := LocateTypst()
 	if err != nil && !strings.Contains(err.Error(), "typst not found") {
-		t.Errorf("locateTypst() unexpected error shape: %v", err)
+		t.Errorf("LocateTypst() unexpected error shape: %v", err)
 	}
 }
 
@@ -340,13 +340,13 @@ func TestTypstExporterIntegrationTurSample(t *testing.T) {
 		t.Skip("tur sample project not found (expected a sibling epub-public checkout at ../../../epub-public); skipping PDF integration test")
 	}
 
-	if _, err := locateTypst(); err != nil {
+	if _, err := LocateTypst(); err != nil {
 		t.Skipf("typst binary not available: %v", err)
 	}
 
-	project, err := readProject(projectFile)
+	project, err := ReadProject(projectFile)
 	if err != nil {
-		t.Fatalf("readProject(%q) error = %v", projectFile, err)
+		t.Fatalf("ReadProject(%q) error = %v", projectFile, err)
 	}
 
 	pdfPath, typPath := derivedTypstPaths(project.Filename)
@@ -518,15 +518,15 @@ func TestAssembleTypstDocumentRejectsBadLength(t *testing.T) {
 // TestTypstLength covers the length validator's accepted units and rejects.
 func TestTypstLength(t *testing.T) {
 	for _, ok := range []string{"12pt", "1cm", "1.5in", "10mm", "2em", " 12pt "} {
-		if v, err := typstLength("k", ok); err != nil {
-			t.Errorf("typstLength(%q) unexpected error: %v", ok, err)
+		if v, err := TypstLength("k", ok); err != nil {
+			t.Errorf("TypstLength(%q) unexpected error: %v", ok, err)
 		} else if v != strings.TrimSpace(ok) {
-			t.Errorf("typstLength(%q) = %q, want trimmed", ok, v)
+			t.Errorf("TypstLength(%q) = %q, want trimmed", ok, v)
 		}
 	}
 	for _, bad := range []string{"12", "pt", "12 pt", "12px", "abc", "", "-3pt"} {
-		if _, err := typstLength("k", bad); err == nil {
-			t.Errorf("typstLength(%q) expected error, got nil", bad)
+		if _, err := TypstLength("k", bad); err == nil {
+			t.Errorf("TypstLength(%q) expected error, got nil", bad)
 		}
 	}
 }
@@ -609,7 +609,7 @@ func TestAssembleTypstDocumentCJKLang(t *testing.T) {
 }
 
 // TestAssembleTypstDocumentNoCoverOmitsArg confirms an empty cover (the
-// post-fix readProject value for an unset cover) emits no `cover:` argument,
+// post-fix ReadProject value for an unset cover) emits no `cover:` argument,
 // so Typst is never handed a bogus path.
 func TestAssembleTypstDocumentNoCoverOmitsArg(t *testing.T) {
 	doc, err := assembleTypstDocument(
@@ -623,7 +623,7 @@ func TestAssembleTypstDocumentNoCoverOmitsArg(t *testing.T) {
 }
 
 // TestReadProjectNoCoverStaysEmpty guards the cover fix end to end: a project
-// file without a `cover:` must leave project.Cover == "" after readProject
+// file without a `cover:` must leave project.Cover == "" after ReadProject
 // (before the fix it became the project directory).
 func TestReadProjectNoCoverStaysEmpty(t *testing.T) {
 	dir := t.TempDir()
@@ -632,9 +632,9 @@ func TestReadProjectNoCoverStaysEmpty(t *testing.T) {
 	if err := os.WriteFile(ymlPath, []byte(yml), 0o644); err != nil {
 		t.Fatalf("write ebook.yml: %v", err)
 	}
-	project, err := readProject(ymlPath)
+	project, err := ReadProject(ymlPath)
 	if err != nil {
-		t.Fatalf("readProject error = %v", err)
+		t.Fatalf("ReadProject error = %v", err)
 	}
 	if project.Cover != "" {
 		t.Errorf("Cover = %q, want \"\" for an unset cover", project.Cover)
@@ -750,7 +750,7 @@ func TestAssembleTypstDocumentRoleFontsDeclared(t *testing.T) {
 	}
 }
 
-// --- typstStringLiteral escaping (SPECS §5.2 string context / ASR-4) ------
+// --- TypstStringLiteral escaping (SPECS §5.2 string context / ASR-4) ------
 
 func TestTypstStringLiteral(t *testing.T) {
 	cases := []struct {
@@ -769,8 +769,8 @@ func TestTypstStringLiteral(t *testing.T) {
 		{"unicode passthrough", "café 你好 —", `"café 你好 —"`},
 	}
 	for _, tc := range cases {
-		if got := typstStringLiteral(tc.in); got != tc.want {
-			t.Errorf("%s: typstStringLiteral(%q) = %q, want %q", tc.name, tc.in, got, tc.want)
+		if got := TypstStringLiteral(tc.in); got != tc.want {
+			t.Errorf("%s: TypstStringLiteral(%q) = %q, want %q", tc.name, tc.in, got, tc.want)
 		}
 	}
 }
@@ -808,10 +808,10 @@ func TestTypstAssetPath(t *testing.T) {
 	}
 }
 
-// --- readProject resolves Stylesheet.Cover (regression) -------------------
+// --- ReadProject resolves Stylesheet.Cover (regression) -------------------
 
 // TestReadProjectResolvesStylesheetCover guards the fix for a pre-existing
-// bug: readProject resolved Cover/Stylesheet.Section/Chapter/Common to
+// bug: ReadProject resolved Cover/Stylesheet.Section/Chapter/Common to
 // absolute paths but NOT Stylesheet.Cover, so `build --format epub` failed
 // (book.AddCSS resolved the raw relative path against the process CWD) when
 // invoked with -p pointing outside the project directory.
@@ -828,9 +828,9 @@ func TestReadProjectResolvesStylesheetCover(t *testing.T) {
 		t.Fatalf("write ebook.yml: %v", err)
 	}
 
-	project, err := readProject(ymlPath)
+	project, err := ReadProject(ymlPath)
 	if err != nil {
-		t.Fatalf("readProject(%q) error = %v", ymlPath, err)
+		t.Fatalf("ReadProject(%q) error = %v", ymlPath, err)
 	}
 
 	if !filepath.IsAbs(project.Stylesheet.Cover) {
@@ -62,7 +62,7 @@ const bookTypGateFixtureHeader = `
 // path, ready for `typst query`.
 func compileBookTypGateFixture(t *testing.T, body string) (typstPath, typPath string) {
 	t.Helper()
-	typstPath, err := locateTypst()
+	typstPath, err := LocateTypst()
 	if err != nil {
 		t.Skipf("typst binary not available: %v", err)
 	}
@@ -75,7 +75,7 @@ func compileBookTypGateFixture(t *testing.T, body string) (typstPath, typPath st
 		t.Fatalf("write fixture: %v", err)
 	}
 
-	if out, err := runTypst(typstPath, "compile", typPath, pdfPath); err != nil {
+	if out, err := RunTypst(typstPath, "compile", typPath, pdfPath); err != nil {
 		t.Fatalf("typst compile failed: %s", out)
 	}
 	return typstPath, typPath
@@ -468,7 +468,7 @@ const sbFixtureHeader = `
 // for `typst query`.
 func compileStructuredBlockFixture(t *testing.T, body string) (typstPath, typPath string) {
 	t.Helper()
-	typstPath, err := locateTypst()
+	typstPath, err := LocateTypst()
 	if err != nil {
 		t.Skipf("typst binary not available: %v", err)
 	}
@@ -481,7 +481,7 @@ func compileStructuredBlockFixture(t *testing.T, body string) (typstPath, typPat
 		t.Fatalf("write fixture: %v", err)
 	}
 
-	if out, err := runTypst(typstPath, "compile", typPath, pdfPath); err != nil {
+	if out, err := RunTypst(typstPath, "compile", typPath, pdfPath); err != nil {
 		t.Fatalf("typst compile failed: %s", out)
 	}
 	return typstPath, typPath
@@ -818,7 +818,7 @@ func TestBookTyp_FR5_BlockHeaderCentered(t *testing.T) {
 // the fixture .typ path, ready for typst query.
 func compileBookTypRealBookFixture(t *testing.T, body string) (typstPath, typPath string) {
 	t.Helper()
-	typstPath, err := locateTypst()
+	typstPath, err := LocateTypst()
 	if err != nil {
 		t.Skipf("typst binary not available: %v", err)
 	}
@@ -831,7 +831,7 @@ func compileBookTypRealBookFixture(t *testing.T, body string) (typstPath, typPat
 		t.Fatalf("write fixture: %v", err)
 	}
 
-	if out, err := runTypst(typstPath, "compile", typPath, pdfPath); err != nil {
+	if out, err := RunTypst(typstPath, "compile", typPath, pdfPath); err != nil {
 		t.Fatalf("typst compile failed: %s", out)
 	}
 	return typstPath, typPath
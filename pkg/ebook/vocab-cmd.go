@@ -7,16 +7,43 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var _models bool
+var _vocabFormats []string
+
 var vocabCmd = &cobra.Command{
 	Use:   "vocab",
 	Short: "Get vocabulary from ebook project",
-	Long:  "Get vocabulary long description",
+	Long: "Export the items of the project's vocabulary blocks (and, with --models, models blocks) " +
+		"as CSV, TSV, JSON or an XLSX workbook next to the project's filename.",
 	Run: func(cmd *cobra.Command, args []string) {
-		epubFile, err := buildVocabulary(_project)
+		// Resolve every requested format before reading the project, so an
+		// unknown format is rejected before anything is written.
+		exporters := make([]vocabularyExporter, 0, len(_vocabFormats))
+		for _, format := range _vocabFormats {
+			exporter, err := vocabularyExporterFor(format)
+			if err != nil {
+				log.Fatal(err)
+			}
+			exporters = append(exporters, exporter)
+		}
+
+		project, err := ReadProject(_project)
 		if err != nil {
 			log.Fatal(err)
 		}
-		fmt.Println(epubFile)
+
+		records, err := readVocabulary(project, _models)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, exporter := range exporters {
+			outfile, err := exporter.Export(project, records)
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println(outfile)
+		}
 	},
 }
 
@@ -24,4 +51,6 @@ func init() {
 	mainCmd.AddCommand(vocabCmd)
 
 	vocabCmd.Flags().StringVarP(&_project, "project", "p", "ebook.yml", "eBook project file")
+	vocabCmd.Flags().StringSliceVarP(&_vocabFormats, "format", "f", []string{"csv"}, "output format(s): csv, tsv, json, xlsx (repeatable, or comma-separated)")
+	vocabCmd.Flags().BoolVar(&_models, "models", false, "also export the items of {start-models} blocks")
 }
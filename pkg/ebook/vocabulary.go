@@ -1,138 +1,283 @@
 package ebook
 
 import (
-	"bufio"
+	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+
+	gast "github.com/yuin/goldmark/ast"
+
+	"github.com/dpurge/cli-tools/pkg/tool"
+	"github.com/dpurge/cli-tools/pkg/tool/markdown"
 )
 
+// VocabularyRecord is one exported vocabulary item, with the block and text
+// file it comes from. Block is "vocabulary" or "models"; Lang and Script are
+// the block's marker attributes, falling back to the project's language and
+// script. Chapter is the title (first H1) of the text file, or its basename
+// when it has none; Section is the title of the section file that opens the
+// file's text group, and SectionIdx its 1-based position (WalkTexts).
 type VocabularyRecord struct {
 	Phrase        string
 	Grammar       string
 	Transcription string
 	Translation   string
 	Notes         string
+
+	Block      string
+	Lang       string
+	Script     string
+	Section    string
+	SectionIdx int
+	Chapter    string
+	File       string
 }
 
-func buildVocabulary(projectfile string) (string, error) {
-	project, err := readProject(projectfile)
-	if err != nil {
-		return "", err
+// vocabularyExporter writes the exported vocabulary records in one output
+// format. vocab-cmd.go reads the records once and dispatches them to every
+// requested format, the way build-cmd.go does with Exporter.
+type vocabularyExporter interface {
+	Export(project *EBookProject, records []VocabularyRecord) (outfile string, err error)
+}
+
+// vocabularyExporterFor maps a vocab --format value to its exporter, or
+// reports it as unknown.
+func vocabularyExporterFor(format string) (vocabularyExporter, error) {
+	switch format {
+	case "csv":
+		return vocabularyTableExporter{ext: ".csv", comma: ','}, nil
+	case "tsv":
+		return vocabularyTableExporter{ext: ".tsv", comma: '\t'}, nil
+	case "json":
+		return vocabularyJSONExporter{}, nil
+	case "xlsx":
+		return vocabularyXLSXExporter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown vocabulary format %q (want csv|tsv|json|xlsx)", format)
 	}
+}
+
+// vocabularyHeader is the column layout shared by the CSV, TSV and XLSX
+// exports; vocabularyRow must list the same fields in the same order.
+var vocabularyHeader = []string{"Chapter", "Block", "Lang", "Script", "Phrase", "Grammar", "Transcription", "Translation", "Notes"}
+
+func vocabularyRow(record VocabularyRecord) []string {
+	return []string{record.Chapter, record.Block, record.Lang, record.Script, record.Phrase, record.Grammar, record.Transcription, record.Translation, record.Notes}
+}
+
+// vocabularyTableExporter writes one row per record under a header row, with
+// tool.WriteTable.
+type vocabularyTableExporter struct {
+	ext   string
+	comma rune
+}
 
+func (e vocabularyTableExporter) Export(project *EBookProject, records []VocabularyRecord) (string, error) {
 	// baseOutputName strips any existing extension (".epub" or otherwise) so
 	// this derives correctly whether Filename is extensionless or not; see
-	// TestBaseOutputName's "other-extension" case in exporter_test.go for the
-	// coverage of this derivation (no separate buildVocabulary test needed).
-	outfile := baseOutputName(project.Filename) + ".csv"
+	// TestBaseOutputName's "other-extension" case in exporter_test.go.
+	outfile := baseOutputName(project.Filename) + e.ext
 
-	f, err := os.Create(outfile)
-	if err != nil {
+	rows := [][]string{vocabularyHeader}
+	for _, record := range records {
+		rows = append(rows, vocabularyRow(record))
+	}
+	if err := tool.WriteTable(outfile, rows, e.comma); err != nil {
 		return "", err
 	}
-	defer f.Close()
+	return outfile, nil
+}
 
-	w := bufio.NewWriter(f)
-	defer w.Flush()
+// vocabularyJSON is the document the JSON export writes: the book's
+// sections, each with its chapters, each with its items, in book order.
+// Sections and chapters without vocabulary are left out.
+type vocabularyJSON struct {
+	Title    string                  `json:"title"`
+	Language string                  `json:"language,omitempty"`
+	Script   string                  `json:"script,omitempty"`
+	Sections []vocabularySectionJSON `json:"sections"`
+}
 
-	w.WriteString("Phrase\tGrammar\tTranscription\tTranslation\tNotes\n")
+type vocabularySectionJSON struct {
+	Title    string                  `json:"title"`
+	Chapters []vocabularyChapterJSON `json:"chapters"`
+}
 
-	for _, items := range project.Text {
-		for _, filename := range items {
-			lines, err := getVocabulary(filename)
-			if err != nil {
-				return "", err
-			}
+type vocabularyChapterJSON struct {
+	Title string               `json:"title"`
+	File  string               `json:"file"`
+	Items []vocabularyItemJSON `json:"items"`
+}
 
-			w.WriteString("\n# " + filename + "\n")
+type vocabularyItemJSON struct {
+	Block         string `json:"block"`
+	Lang          string `json:"lang,omitempty"`
+	Script        string `json:"script,omitempty"`
+	Phrase        string `json:"phrase"`
+	Grammar       string `json:"grammar,omitempty"`
+	Transcription string `json:"transcription,omitempty"`
+	Translation   string `json:"translation,omitempty"`
+	Notes         string `json:"notes,omitempty"`
+}
 
-			for _, line := range lines {
-				record := parseRecord(line)
-				w.WriteString(
-					record.Phrase + "\t" +
-						record.Grammar + "\t" +
-						record.Transcription + "\t" +
-						record.Translation + "\t" +
-						record.Notes + "\n")
-			}
+// vocabularyJSONExporter writes the records nested by section and chapter.
+type vocabularyJSONExporter struct{}
+
+func (vocabularyJSONExporter) Export(project *EBookProject, records []VocabularyRecord) (string, error) {
+	outfile := baseOutputName(project.Filename) + ".json"
+
+	doc := vocabularyJSON{Title: project.Title, Language: project.Language, Script: project.Script, Sections: []vocabularySectionJSON{}}
+	sectionIdx := 0
+	for _, record := range records {
+		// Records come grouped by file in book order, so a new section or
+		// chapter always starts at the end of the list.
+		if record.SectionIdx != sectionIdx {
+			sectionIdx = record.SectionIdx
+			doc.Sections = append(doc.Sections, vocabularySectionJSON{Title: record.Section})
+		}
+		section := &doc.Sections[len(doc.Sections)-1]
+		if len(section.Chapters) == 0 || section.Chapters[len(section.Chapters)-1].File != record.File {
+			section.Chapters = append(section.Chapters, vocabularyChapterJSON{Title: record.Chapter, File: record.File})
 		}
+		chapter := &section.Chapters[len(section.Chapters)-1]
+		chapter.Items = append(chapter.Items, vocabularyItemJSON{
+			Block:         record.Block,
+			Lang:          record.Lang,
+			Script:        record.Script,
+			Phrase:        record.Phrase,
+			Grammar:       record.Grammar,
+			Transcription: record.Transcription,
+			Translation:   record.Translation,
+			Notes:         record.Notes,
+		})
 	}
 
-	return outfile, nil
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return outfile, os.WriteFile(outfile, append(data, '\n'), 0o644)
 }
 
-func getVocabulary(filename string) ([]string, error) {
-	readFile, err := os.Open(filename)
-	if err != nil {
-		return nil, err
+// vocabularyXLSXExporter writes a workbook with one sheet per section,
+// named after the section's title, each with the table export's columns.
+// A book with no vocabulary gets a single empty "Vocabulary" sheet, since a
+// workbook must have at least one.
+type vocabularyXLSXExporter struct{}
+
+func (vocabularyXLSXExporter) Export(project *EBookProject, records []VocabularyRecord) (string, error) {
+	outfile := baseOutputName(project.Filename) + ".xlsx"
+
+	var sheets []xlsxSheet
+	sectionIdx := 0
+	for _, record := range records {
+		if record.SectionIdx != sectionIdx {
+			sectionIdx = record.SectionIdx
+			sheets = append(sheets, xlsxSheet{Name: record.Section, Rows: [][]string{vocabularyHeader}})
+		}
+		sheet := &sheets[len(sheets)-1]
+		sheet.Rows = append(sheet.Rows, vocabularyRow(record))
+	}
+	if len(sheets) == 0 {
+		sheets = []xlsxSheet{{Name: "Vocabulary", Rows: [][]string{vocabularyHeader}}}
 	}
 
-	fileScanner := bufio.NewScanner(readFile)
-	fileScanner.Split(bufio.ScanLines)
+	return outfile, writeXLSX(outfile, sheets)
+}
 
-	var lines []string
-	inVocabulary := false
-	for fileScanner.Scan() {
-		line := strings.TrimSpace(fileScanner.Text())
-		if line == "" {
-			continue
+// readVocabulary collects the items of every {start-vocabulary} block — and,
+// with models, every {start-models} block — in the project's text files, in
+// book order, those in {start-text} bodies included. Blocks are read from
+// the parsed markdown, so attributed markers count like bare ones; headings
+// and notes inside the blocks are skipped. A block with malformed markers fails with the file name.
+func readVocabulary(project *EBookProject, models bool) ([]VocabularyRecord, error) {
+	var records []VocabularyRecord
+	section := ""
+	for _, item := range WalkTexts(project.Text) {
+		source, err := os.ReadFile(item.File)
+		if err != nil {
+			return nil, err
 		}
 
-		if line == "{end-vocabulary}" {
-			inVocabulary = false
+		chapter, err := markdown.Title(source)
+		if err != nil {
+			return nil, err
+		}
+		if chapter == "" {
+			chapter = strings.TrimSuffix(filepath.Base(item.File), filepath.Ext(item.File))
+		}
+		if item.Kind == SectionItem {
+			section = chapter
 		}
 
-		if inVocabulary {
-			// Skip ATX header lines (# through ######) — SPECS §9 / PLAN S8.
-			// Implements ^#{1,6}(\s|$): count leading '#', accept only 1-6,
-			// followed by whitespace or end-of-line.
-			n := 0
-			for n < len(line) && line[n] == '#' {
-				n++
+		add := func(record VocabularyRecord, lang, script string) {
+			record.Translation, record.Notes = splitVocabularyNotes(record.Translation)
+			record.Lang, record.Script = lang, script
+			if record.Lang == "" {
+				record.Lang = project.Language
 			}
-			if n >= 1 && n <= 6 && (n == len(line) || line[n] == ' ' || line[n] == '\t') {
-				continue
+			if record.Script == "" {
+				record.Script = project.Script
 			}
-			lines = append(lines, line)
+			record.Section, record.SectionIdx = section, item.SectionIdx
+			record.Chapter = chapter
+			record.File = item.File
+			records = append(records, record)
 		}
 
-		if line == "{start-vocabulary}" {
-			inVocabulary = true
+		if err := WalkVocabulary(source, models, add); err != nil {
+			return nil, markdown.InFile(item.File, err)
 		}
 	}
-
-	readFile.Close()
-
-	return lines, nil
+	return records, nil
 }
 
-func parseRecord(line string) VocabularyRecord {
-	var record VocabularyRecord
-
-	i := strings.LastIndex(line, "=")
-	if i != -1 {
-		record.Translation = strings.TrimSpace(line[i+1:])
-		if record.Translation[len(record.Translation)-1:] == ")" {
-			j := strings.LastIndex(record.Translation, "(")
-			record.Notes = strings.TrimSpace(record.Translation[j+1 : len(record.Translation)-1])
-			record.Translation = strings.TrimSpace(record.Translation[:j])
+// walkVocabulary calls fn with a record for every data item of the
+// vocabulary blocks in source, and of the models blocks when models is set,
+// in document order, blocks nested in a {start-text} body included. The
+// record carries the block name and the item's fields, its translation not
+// yet split (splitVocabularyNotes); lang and script are the block's own.
+func WalkVocabulary(source []byte, models bool, fn func(record VocabularyRecord, lang, script string)) error {
+	return markdown.WalkBlocks(source, func(n gast.Node) error {
+		switch block := n.(type) {
+		case *markdown.Vocabulary:
+			if block.Err != nil {
+				return block.Err
+			}
+			for _, v := range block.Items {
+				if v.Kind == markdown.ItemData {
+					fn(VocabularyRecord{Block: "vocabulary", Phrase: v.Phrase, Grammar: v.Grammar, Transcription: v.Transcription, Translation: v.Translation}, block.Lang, block.Script)
+				}
+			}
+		case *markdown.Models:
+			if !models {
+				return nil
+			}
+			if block.Err != nil {
+				return block.Err
+			}
+			for _, m := range block.Items {
+				if m.Kind == markdown.ItemData {
+					fn(VocabularyRecord{Block: "models", Phrase: m.Phrase, Transcription: m.Transcription, Translation: m.Translation}, block.Lang, block.Script)
+				}
+			}
 		}
+		return nil
+	})
+}
 
-		line = strings.TrimSpace(line[:i])
-	}
-
-	if line[len(line)-1:] == "]" {
-		i = strings.LastIndex(line, "[")
-		record.Transcription = strings.TrimSpace(line[i+1 : len(line)-1])
-		line = strings.TrimSpace(line[:i])
+// splitVocabularyNotes splits a trailing "(note)" off a translation, as in
+// "book (also: volume)". A translation that is only a parenthesis is kept
+// as the translation.
+func splitVocabularyNotes(translation string) (string, string) {
+	if !strings.HasSuffix(translation, ")") {
+		return translation, ""
 	}
-
-	if line[len(line)-1:] == "}" {
-		i = strings.LastIndex(line, "{")
-		record.Grammar = strings.TrimSpace(line[i+1 : len(line)-1])
-		line = strings.TrimSpace(line[:i])
+	i := strings.LastIndex(translation, "(")
+	if i <= 0 {
+		return translation, ""
 	}
-
-	record.Phrase = line
-	return record
+	return strings.TrimSpace(translation[:i]), strings.TrimSpace(translation[i+1 : len(translation)-1])
 }
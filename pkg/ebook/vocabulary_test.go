@@ -1,82 +1,239 @@
-package ebook
-
-import (
-	"os"
-	"path/filepath"
-	"testing"
-)
-
-// TestGetVocabularySkipsHeader verifies SPECS §9 / D6: getVocabulary drops
-// ATX header lines (# through ######) from inside a {start-vocabulary} block
-// so they produce no CSV row. Data rows are returned unchanged.
-//
-// Edge cases covered:
-//   - ## (level 2) and ### (level 3) and ###### (level 6) are all skipped.
-//   - ####### (7 hashes) is NOT a valid ATX header and must NOT be skipped.
-func TestGetVocabularySkipsHeader(t *testing.T) {
-	dir := t.TempDir()
-	path := filepath.Join(dir, "vocab.md")
-	content := "{start-vocabulary}\n" +
-		"phrase1 = translation1\n" +
-		"## Section Heading\n" +
-		"phrase2 = translation2\n" +
-		"### Sub-heading\n" +
-		"###### Deepest heading\n" +
-		"####### NOT a header\n" +
-		"phrase3 = translation3\n" +
-		"{end-vocabulary}\n"
-	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
-		t.Fatalf("write fixture: %v", err)
-	}
-	lines, err := getVocabulary(path)
-	if err != nil {
-		t.Fatalf("getVocabulary: %v", err)
-	}
-	// The three ATX header lines (##, ###, ######) must be absent.
-	// The 7-hash line (NOT a header per ATX) and the data lines must be present.
-	want := []string{
-		"phrase1 = translation1",
-		"phrase2 = translation2",
-		"####### NOT a header",
-		"phrase3 = translation3",
-	}
-	if len(lines) != len(want) {
-		t.Fatalf("getVocabulary returned %d lines %v, want %d %v", len(lines), lines, len(want), want)
-	}
-	for i, w := range want {
-		if lines[i] != w {
-			t.Errorf("getVocabulary[%d] = %q, want %q", i, lines[i], w)
-		}
-	}
-}
-
-// TestGetVocabularyDataOnlyUnchanged verifies ASR-3: a vocabulary block with
-// no header lines returns the data lines byte-identical to the pre-change
-// output. The header-skip path must be a no-op when no headers are present.
-func TestGetVocabularyDataOnlyUnchanged(t *testing.T) {
-	dir := t.TempDir()
-	path := filepath.Join(dir, "vocab.md")
-	content := "{start-vocabulary}\n" +
-		"phrase1 = translation1\n" +
-		"phrase2 = translation2\n" +
-		"{end-vocabulary}\n"
-	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
-		t.Fatalf("write fixture: %v", err)
-	}
-	lines, err := getVocabulary(path)
-	if err != nil {
-		t.Fatalf("getVocabulary: %v", err)
-	}
-	want := []string{
-		"phrase1 = translation1",
-		"phrase2 = translation2",
-	}
-	if len(lines) != len(want) {
-		t.Fatalf("getVocabulary returned %d lines %v, want %d %v", len(lines), lines, len(want), want)
-	}
-	for i, w := range want {
-		if lines[i] != w {
-			t.Errorf("getVocabulary[%d] = %q, want %q", i, lines[i], w)
-		}
-	}
-}
+package ebook
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeVocabularyFixture writes each named markdown file into a temp dir and
+// returns a project whose single text group lists them in order.
+func writeVocabularyFixture(t *testing.T, files ...[2]string) *EBookProject {
+	t.Helper()
+	dir := t.TempDir()
+	group := make([]string, 0, len(files))
+	for _, file := range files {
+		path := filepath.Join(dir, file[0])
+		if err := os.WriteFile(path, []byte(file[1]), 0o644); err != nil {
+			t.Fatalf("write fixture: %v", err)
+		}
+		group = append(group, path)
+	}
+	return &EBookProject{Language: "lat", Script: "latn", Text: [][]string{group}}
+}
+
+// TestReadVocabulary verifies that the export reads the parsed blocks:
+// attributed markers count, headers inside a block produce no row, a
+// trailing "(note)" moves to Notes, and every row carries its block's
+// lang/script (or the project's) and its chapter title.
+func TestReadVocabulary(t *testing.T) {
+	project := writeVocabularyFixture(t,
+		[2]string{"section.md", "# Part One\n\n{start-vocabulary}\nliber {m} [ˈli.ber] = book (also: volume)\n{end-vocabulary}\n"},
+		[2]string{"chapter.md", "# Greek\n\n{start-vocabulary lang=grc script=grek}\n## Nouns\nλόγος = word\n{end-vocabulary}\n\n{start-models}\nvale [ˈwa.leː] = farewell\n{end-models}\n"},
+		[2]string{"untitled.md", "{start-vocabulary}\n= stray\n{end-vocabulary}\n"},
+	)
+	section, chapter, untitled := project.Text[0][0], project.Text[0][1], project.Text[0][2]
+
+	got, err := readVocabulary(project, false)
+	if err != nil {
+		t.Fatalf("readVocabulary: %v", err)
+	}
+	want := []VocabularyRecord{
+		{Phrase: "liber", Grammar: "m", Transcription: "ˈli.ber", Translation: "book", Notes: "also: volume", Block: "vocabulary", Lang: "lat", Script: "latn", Section: "Part One", SectionIdx: 1, Chapter: "Part One", File: section},
+		{Phrase: "λόγος", Translation: "word", Block: "vocabulary", Lang: "grc", Script: "grek", Section: "Part One", SectionIdx: 1, Chapter: "Greek", File: chapter},
+		{Translation: "stray", Block: "vocabulary", Lang: "lat", Script: "latn", Section: "Part One", SectionIdx: 1, Chapter: "untitled", File: untitled},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("readVocabulary mismatch\n got: %+v\nwant: %+v", got, want)
+	}
+}
+
+// TestReadVocabularyModels verifies that {start-models} items are exported
+// only on request, after the vocabulary rows of the same file.
+func TestReadVocabularyModels(t *testing.T) {
+	project := writeVocabularyFixture(t,
+		[2]string{"chapter.md", "# Phrases\n\n{start-models}\nvale [ˈwa.leː] = farewell\n{end-models}\n\n{start-vocabulary}\nliber = book\n{end-vocabulary}\n"},
+	)
+	file := project.Text[0][0]
+
+	got, err := readVocabulary(project, true)
+	if err != nil {
+		t.Fatalf("readVocabulary: %v", err)
+	}
+	want := []VocabularyRecord{
+		{Phrase: "vale", Transcription: "ˈwa.leː", Translation: "farewell", Block: "models", Lang: "lat", Script: "latn", Section: "Phrases", SectionIdx: 1, Chapter: "Phrases", File: file},
+		{Phrase: "liber", Translation: "book", Block: "vocabulary", Lang: "lat", Script: "latn", Section: "Phrases", SectionIdx: 1, Chapter: "Phrases", File: file},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("readVocabulary mismatch\n got: %+v\nwant: %+v", got, want)
+	}
+}
+
+// TestReadVocabularyNestedInText verifies that blocks inside a
+// {start-text} body are exported in document order, taking their own
+// lang/script, and that an error in one names the file's line.
+func TestReadVocabularyNestedInText(t *testing.T) {
+	project := writeVocabularyFixture(t,
+		[2]string{"chapter.md", "# Reading\n\n{start-vocabulary}\nliber = book\n{end-vocabulary}\n\n" +
+			"{start-text as=translation}\nIntro.\n\n{start-vocabulary lang=grc script=grek}\nλόγος = word\n{end-vocabulary}\n{end-text}\n\n" +
+			"{start-models}\nvale = farewell\n{end-models}\n"},
+	)
+	file := project.Text[0][0]
+
+	got, err := readVocabulary(project, true)
+	if err != nil {
+		t.Fatalf("readVocabulary: %v", err)
+	}
+	want := []VocabularyRecord{
+		{Phrase: "liber", Translation: "book", Block: "vocabulary", Lang: "lat", Script: "latn", Section: "Reading", SectionIdx: 1, Chapter: "Reading", File: file},
+		{Phrase: "λόγος", Translation: "word", Block: "vocabulary", Lang: "grc", Script: "grek", Section: "Reading", SectionIdx: 1, Chapter: "Reading", File: file},
+		{Phrase: "vale", Translation: "farewell", Block: "models", Lang: "lat", Script: "latn", Section: "Reading", SectionIdx: 1, Chapter: "Reading", File: file},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("readVocabulary mismatch\n got: %+v\nwant: %+v", got, want)
+	}
+
+	project = writeVocabularyFixture(t,
+		[2]string{"chapter.md", "# T\n\n{start-text}\nIntro.\n\n{start-vocabulary as=source}\nliber = book\n{end-vocabulary}\n{end-text}\n"},
+	)
+	if _, err := readVocabulary(project, false); err == nil || !strings.HasPrefix(err.Error(), project.Text[0][0]+":6: ") {
+		t.Fatalf("readVocabulary error = %v, want one at %s:6", err, project.Text[0][0])
+	}
+}
+
+// TestReadVocabularyBlockError verifies that a malformed marker fails the
+// export with the offending file and line named, rather than being skipped.
+func TestReadVocabularyBlockError(t *testing.T) {
+	project := writeVocabularyFixture(t,
+		[2]string{"chapter.md", "{start-vocabulary as=translation}\nliber = book\n{end-vocabulary}\n"},
+	)
+
+	_, err := readVocabulary(project, false)
+	if err == nil {
+		t.Fatalf("readVocabulary: expected an error for as= on {start-vocabulary}, got nil")
+	}
+	if !strings.HasPrefix(err.Error(), project.Text[0][0]+":1: ") {
+		t.Fatalf("readVocabulary error %q does not name the file and line", err)
+	}
+}
+
+func TestSplitVocabularyNotes(t *testing.T) {
+	tests := []struct {
+		in, translation, notes string
+	}{
+		{"book", "book", ""},
+		{"book (also: volume)", "book", "also: volume"},
+		{"(idiom)", "(idiom)", ""},
+		{"a (b) c", "a (b) c", ""},
+	}
+	for _, tc := range tests {
+		translation, notes := splitVocabularyNotes(tc.in)
+		if translation != tc.translation || notes != tc.notes {
+			t.Errorf("splitVocabularyNotes(%q) = %q, %q; want %q, %q", tc.in, translation, notes, tc.translation, tc.notes)
+		}
+	}
+}
+
+// vocabularyExportRecords is a two-section book: a note with a tab and a
+// line break, and a phrase with a comma, to exercise quoting and flattening.
+var vocabularyExportRecords = []VocabularyRecord{
+	{Phrase: "liber", Translation: "book", Notes: "also:\tvolume\nor tome", Block: "vocabulary", Lang: "lat", Script: "latn", Section: "Part One", SectionIdx: 1, Chapter: "Nouns", File: "a.md"},
+	{Phrase: "vale, amice", Translation: "farewell, friend", Block: "models", Lang: "lat", Script: "latn", Section: "Part Two", SectionIdx: 2, Chapter: "Phrases", File: "b.md"},
+}
+
+func TestVocabularyExporterFor(t *testing.T) {
+	for _, format := range []string{"csv", "tsv", "json", "xlsx"} {
+		if _, err := vocabularyExporterFor(format); err != nil {
+			t.Errorf("vocabularyExporterFor(%q): %v", format, err)
+		}
+	}
+	if _, err := vocabularyExporterFor("ods"); err == nil {
+		t.Error("vocabularyExporterFor(\"ods\"): expected an error, got nil")
+	}
+}
+
+// TestVocabularyTextExporters: each text export is written next to the
+// project's filename with the expected content.
+func TestVocabularyTextExporters(t *testing.T) {
+	tests := []struct {
+		format string
+		file   string
+		want   string
+	}{
+		{"csv", "book.csv", "Chapter,Block,Lang,Script,Phrase,Grammar,Transcription,Translation,Notes\n" +
+			"Nouns,vocabulary,lat,latn,liber,,,book,\"also:\tvolume\nor tome\"\n" +
+			"Phrases,models,lat,latn,\"vale, amice\",,,\"farewell, friend\",\n"},
+		{"tsv", "book.tsv", "Chapter\tBlock\tLang\tScript\tPhrase\tGrammar\tTranscription\tTranslation\tNotes\n" +
+			"Nouns\tvocabulary\tlat\tlatn\tliber\t\t\tbook\talso: volume or tome\n" +
+			"Phrases\tmodels\tlat\tlatn\tvale, amice\t\t\tfarewell, friend\t\n"},
+		{"json", "book.json", `{
+  "title": "Book",
+  "language": "lat",
+  "sections": [
+    {
+      "title": "Part One",
+      "chapters": [
+        {
+          "title": "Nouns",
+          "file": "a.md",
+          "items": [
+            {
+              "block": "vocabulary",
+              "lang": "lat",
+              "script": "latn",
+              "phrase": "liber",
+              "translation": "book",
+              "notes": "also:\tvolume\nor tome"
+            }
+          ]
+        }
+      ]
+    },
+    {
+      "title": "Part Two",
+      "chapters": [
+        {
+          "title": "Phrases",
+          "file": "b.md",
+          "items": [
+            {
+              "block": "models",
+              "lang": "lat",
+              "script": "latn",
+              "phrase": "vale, amice",
+              "translation": "farewell, friend"
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.format, func(t *testing.T) {
+			project := &EBookProject{Title: "Book", Language: "lat", Filename: filepath.Join(t.TempDir(), "book.epub")}
+			exporter, err := vocabularyExporterFor(tc.format)
+			if err != nil {
+				t.Fatalf("vocabularyExporterFor: %v", err)
+			}
+			outfile, err := exporter.Export(project, vocabularyExportRecords)
+			if err != nil {
+				t.Fatalf("Export: %v", err)
+			}
+			if filepath.Base(outfile) != tc.file {
+				t.Errorf("outfile = %q, want %s", outfile, tc.file)
+			}
+			got, err := os.ReadFile(outfile)
+			if err != nil {
+				t.Fatalf("read output: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("output mismatch\n got: %q\nwant: %q", got, tc.want)
+			}
+		})
+	}
+}
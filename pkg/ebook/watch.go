@@ -0,0 +1,126 @@
+package ebook
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long a burst of file events has to settle before
+// build --watch rebuilds: editors typically write, rename and chmod a file
+// in quick succession on every save.
+const watchDebounce = 300 * time.Millisecond
+
+// watchInputs returns the set of files a build of project reads: the
+// project file itself, the cover, every stylesheet (font.css is one of the
+// common ones), font, image and text file. Paths are absolute, as
+// ReadProject resolves them.
+func watchInputs(projectfile string, project *EBookProject) map[string]bool {
+	inputs := map[string]bool{projectfile: true}
+	add := func(files ...string) {
+		for _, file := range files {
+			if file != "" {
+				inputs[filepath.Clean(file)] = true
+			}
+		}
+	}
+	add(project.Cover, project.Stylesheet.Cover, project.Stylesheet.Section, project.Stylesheet.Chapter)
+	add(project.Stylesheet.Common...)
+	add(project.Font...)
+	add(project.Image...)
+	for _, group := range project.Text {
+		add(group...)
+	}
+	return inputs
+}
+
+// watchBuild builds the project with every exporter, then rebuilds whenever
+// one of its input files changes, until ctx is done. A failed read or export
+// is logged and the watch goes on, so a broken chapter can be fixed without
+// restarting it; only a failure to watch at all is returned.
+func watchBuild(ctx context.Context, projectfile string, exporters []Exporter) error {
+	return watchProject(ctx, projectfile, func() *EBookProject {
+		project, err := ReadProject(projectfile)
+		if err != nil {
+			log.Print(err)
+			return nil
+		}
+		reportExports(exportAll(project, exporters))
+		return project
+	})
+}
+
+// watchProject calls changed once, then again whenever one of the
+// project's input files changes (after watchDebounce), until ctx is done.
+// changed reads the project itself and returns it, or nil if it could not,
+// in which case the previous input set stays watched; re-reading on every
+// change picks up files newly listed in ebook.yml. Only a failure to watch
+// at all is returned.
+//
+// fsnotify watches the inputs' directories rather than the files: editors
+// that save by writing a temporary file and renaming it over the original
+// would otherwise drop the watch on the first save.
+func watchProject(ctx context.Context, projectfile string, changed func() *EBookProject) error {
+	projectfile, err := filepath.Abs(projectfile)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	inputs := map[string]bool{projectfile: true}
+	rebuild := func() {
+		if project := changed(); project != nil {
+			inputs = watchInputs(projectfile, project)
+		}
+
+		dirs := map[string]bool{}
+		for file := range inputs {
+			dirs[filepath.Dir(file)] = true
+		}
+		for _, dir := range watcher.WatchList() {
+			if !dirs[dir] {
+				watcher.Remove(dir)
+			}
+		}
+		for dir := range dirs {
+			if err := watcher.Add(dir); err != nil {
+				log.Print(err)
+			}
+		}
+	}
+
+	rebuild()
+	log.Printf("watching %d files for changes", len(inputs))
+
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod || !inputs[filepath.Clean(event.Name)] {
+				continue
+			}
+			settled = time.After(watchDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Print(err)
+		case <-settled:
+			settled = nil
+			rebuild()
+		}
+	}
+}
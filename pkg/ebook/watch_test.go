@@ -0,0 +1,106 @@
+package ebook
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchInputs(t *testing.T) {
+	project := &EBookProject{
+		Cover:      "/b/cover.svg",
+		Stylesheet: EBookStyles{Chapter: "/b/css/chapter.css", Common: []string{"/b/css/base.css", "/b/css/font.css"}},
+		Font:       []string{"/b/fonts/a.ttf"},
+		Text:       [][]string{{"/b/text/s1.md", "/b/text/c1.md"}, {"/b/text/s2.md"}},
+	}
+	got := watchInputs("/b/ebook.yml", project)
+	for _, file := range []string{"/b/ebook.yml", "/b/cover.svg", "/b/css/chapter.css", "/b/css/base.css", "/b/css/font.css", "/b/fonts/a.ttf", "/b/text/s1.md", "/b/text/c1.md", "/b/text/s2.md"} {
+		if !got[file] {
+			t.Errorf("watchInputs is missing %s", file)
+		}
+	}
+	if len(got) != 9 {
+		t.Errorf("watchInputs returned %d files, want 9 (no empty paths): %v", len(got), got)
+	}
+}
+
+// countingExporter reports each Export on builds and fails while fail is
+// set, standing in for a real format.
+type countingExporter struct {
+	builds chan string
+	fail   *atomic.Bool
+}
+
+func (e countingExporter) Export(project *EBookProject) (string, error) {
+	e.builds <- project.Title
+	if e.fail.Load() {
+		return "", errors.New("broken chapter")
+	}
+	return project.Filename, nil
+}
+
+// TestWatchBuild: the watcher builds once, rebuilds after a burst of writes
+// to an input settles (once, not per write), ignores files the project does
+// not use, and keeps watching after a failed export.
+func TestWatchBuild(t *testing.T) {
+	dir := t.TempDir()
+	projectfile := writeFixture(t, dir, "ebook.yml", "filename: book.epub\ntitle: Book\ntext:\n  - [chapter.md]\n")
+	chapter := writeFixture(t, dir, "chapter.md", "# Chapter\n")
+
+	var fail atomic.Bool
+	builds := make(chan string, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- watchBuild(ctx, projectfile, []Exporter{countingExporter{builds, &fail}}) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("watchBuild: %v", err)
+		}
+	}()
+
+	expectBuild := func(what string) {
+		t.Helper()
+		select {
+		case <-builds:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no build after %s", what)
+		}
+	}
+	expectNoBuild := func(what string) {
+		t.Helper()
+		select {
+		case <-builds:
+			t.Fatalf("unexpected build after %s", what)
+		case <-time.After(3 * watchDebounce):
+		}
+	}
+
+	expectBuild("start")
+	// The watch is set up after the first build; give fsnotify a moment.
+	time.Sleep(50 * time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		if err := os.WriteFile(chapter, []byte("# Chapter\n\nedit\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectBuild("editing the chapter")
+	expectNoBuild("a burst of writes")
+
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("unrelated"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	expectNoBuild("writing an unrelated file")
+
+	fail.Store(true)
+	os.WriteFile(chapter, []byte("# Chapter\n\nbroken\n"), 0o644)
+	expectBuild("breaking the chapter")
+	fail.Store(false)
+	os.WriteFile(chapter, []byte("# Chapter\n\nfixed\n"), 0o644)
+	expectBuild("fixing the chapter")
+}
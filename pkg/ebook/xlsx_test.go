@@ -0,0 +1,122 @@
+package ebook
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestVocabularyXLSXExport: the workbook has one well-formed sheet per
+// section, named after it, and every cell value round-trips through XML.
+func TestVocabularyXLSXExport(t *testing.T) {
+	project := &EBookProject{Filename: filepath.Join(t.TempDir(), "book.epub")}
+	outfile, err := vocabularyXLSXExporter{}.Export(project, vocabularyExportRecords)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if filepath.Base(outfile) != "book.xlsx" {
+		t.Errorf("outfile = %q, want book.xlsx", outfile)
+	}
+
+	zr, err := zip.OpenReader(outfile)
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	defer zr.Close()
+
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		parts[f.Name] = string(data)
+
+		dec := xml.NewDecoder(strings.NewReader(string(data)))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not well-formed XML: %v", f.Name, err)
+			}
+		}
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("workbook is missing part %s", name)
+		}
+	}
+	if _, ok := parts["xl/worksheets/sheet3.xml"]; ok {
+		t.Errorf("workbook has a third sheet, want one per section")
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal([]byte(parts["xl/workbook.xml"]), &workbook); err != nil {
+		t.Fatalf("parse workbook.xml: %v", err)
+	}
+	if len(workbook.Sheets) != 2 || workbook.Sheets[0].Name != "Part One" || workbook.Sheets[1].Name != "Part Two" {
+		t.Errorf("sheets = %+v, want Part One, Part Two", workbook.Sheets)
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref  string `xml:"r,attr"`
+				Text string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal([]byte(parts["xl/worksheets/sheet1.xml"]), &sheet); err != nil {
+		t.Fatalf("parse sheet1.xml: %v", err)
+	}
+	if len(sheet.Rows) != 2 {
+		t.Fatalf("sheet1 has %d rows, want header + 1", len(sheet.Rows))
+	}
+	if got := sheet.Rows[0].Cells[0].Text; got != "Chapter" {
+		t.Errorf("A1 = %q, want Chapter", got)
+	}
+	last := sheet.Rows[1].Cells[len(sheet.Rows[1].Cells)-1]
+	if last.Ref != "I2" || last.Text != "also:\tvolume\nor tome" {
+		t.Errorf("last cell = %s %q, want I2 with the note unchanged", last.Ref, last.Text)
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	for c, want := range map[int]string{0: "A", 8: "I", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumn(c); got != want {
+			t.Errorf("xlsxColumn(%d) = %q, want %q", c, got, want)
+		}
+	}
+}
+
+func TestXLSXSheetName(t *testing.T) {
+	used := map[string]bool{}
+	tests := []struct {
+		in, want string
+	}{
+		{"Part One", "Part One"},
+		{"part one", "part one (2)"},
+		{"Q&A: what? [1/2]", "Q&A_ what_ _1_2_"},
+		{"'quoted'", "quoted"},
+		{"", "Sheet"},
+		{"A very long section title that keeps going", "A very long section title that "},
+		{"A very long section title that keeps on", "A very long section title t (2)"},
+	}
+	for _, tc := range tests {
+		if got := xlsxSheetName(tc.in, used); got != tc.want {
+			t.Errorf("xlsxSheetName(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
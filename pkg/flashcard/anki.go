@@ -0,0 +1,763 @@
+package flashcard
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dpurge/cli-tools/pkg/types"
+
+	_ "modernc.org/sqlite" // registers the pure-Go "sqlite" database/sql driver
+)
+
+// ankiSchema is the legacy (schema 11) collection layout every Anki release
+// still imports from an .apkg's collection.anki2.
+const ankiSchema = `
+CREATE TABLE col (
+    id     integer primary key,
+    crt    integer not null,
+    mod    integer not null,
+    scm    integer not null,
+    ver    integer not null,
+    dty    integer not null,
+    usn    integer not null,
+    ls     integer not null,
+    conf   text not null,
+    models text not null,
+    decks  text not null,
+    dconf  text not null,
+    tags   text not null
+);
+CREATE TABLE notes (
+    id    integer primary key,
+    guid  text not null,
+    mid   integer not null,
+    mod   integer not null,
+    usn   integer not null,
+    tags  text not null,
+    flds  text not null,
+    sfld  integer not null,
+    csum  integer not null,
+    flags integer not null,
+    data  text not null
+);
+CREATE TABLE cards (
+    id     integer primary key,
+    nid    integer not null,
+    did    integer not null,
+    ord    integer not null,
+    mod    integer not null,
+    usn    integer not null,
+    type   integer not null,
+    queue  integer not null,
+    due    integer not null,
+    ivl    integer not null,
+    factor integer not null,
+    reps   integer not null,
+    lapses integer not null,
+    left   integer not null,
+    odue   integer not null,
+    odid   integer not null,
+    flags  integer not null,
+    data   text not null
+);
+CREATE TABLE revlog (
+    id      integer primary key,
+    cid     integer not null,
+    usn     integer not null,
+    ease    integer not null,
+    ivl     integer not null,
+    lastIvl integer not null,
+    factor  integer not null,
+    time    integer not null,
+    type    integer not null
+);
+CREATE TABLE graves (
+    usn  integer not null,
+    oid  integer not null,
+    type integer not null
+);
+CREATE INDEX ix_notes_usn ON notes (usn);
+CREATE INDEX ix_cards_usn ON cards (usn);
+CREATE INDEX ix_revlog_usn ON revlog (usn);
+CREATE INDEX ix_cards_nid ON cards (nid);
+CREATE INDEX ix_cards_sched ON cards (did, queue, due);
+CREATE INDEX ix_revlog_cid ON revlog (cid);
+CREATE INDEX ix_notes_csum ON notes (csum);
+`
+
+// Anki model types (the model's "type" key).
+const (
+	ankiModelStandard = 0
+	ankiModelCloze    = 1
+)
+
+// Default field font, used when a FlashcardField leaves font name/size unset.
+const (
+	ankiDefaultFont     = "Arial"
+	ankiDefaultFontSize = 20
+)
+
+// Anki's own default LaTeX preamble/postamble for a new note type.
+const (
+	ankiDefaultLatexPre = "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n" +
+		"\\usepackage[utf8]{inputenc}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n" +
+		"\\setlength{\\parindent}{0in}\n\\begin{document}\n"
+	ankiDefaultLatexPost = "\\end{document}"
+)
+
+// ankiField is one entry of a model's "flds" list.
+type ankiField struct {
+	Name   string   `json:"name"`
+	Ord    int      `json:"ord"`
+	Sticky bool     `json:"sticky"`
+	RTL    bool     `json:"rtl"`
+	Font   string   `json:"font"`
+	Size   uint32   `json:"size"`
+	Media  []string `json:"media"`
+}
+
+// ankiTemplate is one entry of a model's "tmpls" list (one card type). Did is
+// always null: cards go to the deck the note is added to.
+type ankiTemplate struct {
+	Name  string `json:"name"`
+	Ord   int    `json:"ord"`
+	QFmt  string `json:"qfmt"`
+	AFmt  string `json:"afmt"`
+	BQFmt string `json:"bqfmt"`
+	BAFmt string `json:"bafmt"`
+	Did   *int64 `json:"did"`
+}
+
+// ankiModel is a note type as stored in the col.models JSON map.
+type ankiModel struct {
+	ID        int64          `json:"id"`
+	Name      string         `json:"name"`
+	Type      int            `json:"type"`
+	Mod       int64          `json:"mod"`
+	Usn       int            `json:"usn"`
+	Sortf     int            `json:"sortf"`
+	Did       int64          `json:"did"`
+	Tmpls     []ankiTemplate `json:"tmpls"`
+	Flds      []ankiField    `json:"flds"`
+	CSS       string         `json:"css"`
+	LatexPre  string         `json:"latexPre"`
+	LatexPost string         `json:"latexPost"`
+	Tags      []string       `json:"tags"`
+	Vers      []int          `json:"vers"`
+	Req       [][]any        `json:"req,omitempty"`
+}
+
+// ankiDeck is a deck as stored in the col.decks JSON map.
+type ankiDeck struct {
+	ID               int64  `json:"id"`
+	Name             string `json:"name"`
+	Desc             string `json:"desc"`
+	Mod              int64  `json:"mod"`
+	Usn              int    `json:"usn"`
+	Collapsed        bool   `json:"collapsed"`
+	BrowserCollapsed bool   `json:"browserCollapsed"`
+	Dyn              int    `json:"dyn"`
+	Conf             int64  `json:"conf"`
+	ExtendNew        int    `json:"extendNew"`
+	ExtendRev        int    `json:"extendRev"`
+	NewToday         [2]int `json:"newToday"`
+	RevToday         [2]int `json:"revToday"`
+	LrnToday         [2]int `json:"lrnToday"`
+	TimeToday        [2]int `json:"timeToday"`
+}
+
+// ankiCollection is everything written into the single col row, plus the
+// project's field definitions and MathJax option (to render note values
+// with) and, for a cloze model, the ordinals of the fields its template
+// renders with the cloze filter (the fields whose deletions generate cards).
+type ankiCollection struct {
+	Model       ankiModel
+	Decks       []ankiDeck
+	Fields      []types.FlashcardField
+	ClozeFields []int
+	MathJax     bool
+}
+
+// ankiDeckConf is the default deck options group ("dconf" id 1) every deck
+// points at via its Conf key. Values mirror a fresh Anki profile.
+const ankiDeckConf = `{"1":{"id":1,"name":"Default","mod":0,"usn":0,"maxTaken":60,"autoplay":true,"timer":0,"replayq":true,"dyn":false,` +
+	`"new":{"bury":true,"delays":[1,10],"initialFactor":2500,"ints":[1,4,7],"order":1,"perDay":20,"separate":true},` +
+	`"rev":{"bury":true,"ease4":1.3,"fuzz":0.05,"ivlFct":1,"maxIvl":36500,"minSpace":1,"perDay":200},` +
+	`"lapse":{"delays":[10],"leechAction":0,"leechFails":8,"minInt":1,"mult":0}}}`
+
+// ankiFieldRefRe matches a `{{...}}` field reference in a card template.
+var ankiFieldRefRe = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+// ankiClozeRe matches the opening of a cloze deletion, `{{c1::`, capturing
+// its ordinal. Anki numbers deletions from 1.
+var ankiClozeRe = regexp.MustCompile(`\{\{c([1-9][0-9]*)::`)
+
+// ankiHTMLTagRe matches an HTML tag, stripped before checksumming a sort field.
+var ankiHTMLTagRe = regexp.MustCompile(`<[^>]*>`)
+
+// buildAnkiPackage writes notes as an Anki package (.apkg) at
+// project.Filename: a zip holding the SQLite collection.anki2 plus the media
+// files the notes reference and the media map naming them. Deck and model
+// keep the identifiers from the project file and note GUIDs come from the
+// index fields, so re-importing a rebuilt package updates the notes already
+// in Anki instead of adding duplicates; notes with duplicate index values are
+// rejected up front.
+func buildAnkiPackage(project *types.FlashcardProject, notes []Note) (string, error) {
+	if project.Deck.Identifier == 0 {
+		return "", fmt.Errorf("deck %q has no identifier", project.Deck.Name)
+	}
+	if project.Model.Identifier == 0 {
+		return "", fmt.Errorf("model %q has no identifier", project.Model.Name)
+	}
+
+	if err := checkDuplicateIndex(project.Model.Fields, notes); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	collection, err := newAnkiCollection(project, now)
+	if err != nil {
+		return "", err
+	}
+
+	tmpdir, err := os.MkdirTemp("", "flashcard-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpdir)
+
+	dbfile := filepath.Join(tmpdir, "collection.anki2")
+	media := newAnkiMedia(project.Directory)
+	if err := writeAnkiCollection(dbfile, collection, notes, media, now); err != nil {
+		return "", err
+	}
+
+	if err := writeAnkiArchive(project.Filename, dbfile, media); err != nil {
+		return "", err
+	}
+
+	return project.Filename, nil
+}
+
+// newAnkiCollection reads the project's template, stylesheet and LaTeX
+// files and assembles the model and deck JSON for the collection.
+func newAnkiCollection(project *types.FlashcardProject, now time.Time) (*ankiCollection, error) {
+	css, err := os.ReadFile(project.Model.Style.CSS)
+	if err != nil {
+		return nil, err
+	}
+	latexPre, err := readLatex(project.Model.Style.Latex.Prefix, ankiDefaultLatexPre)
+	if err != nil {
+		return nil, err
+	}
+	latexPost, err := readLatex(project.Model.Style.Latex.Postfix, ankiDefaultLatexPost)
+	if err != nil {
+		return nil, err
+	}
+
+	model := ankiModel{
+		ID:        project.Model.Identifier,
+		Name:      project.Model.Name,
+		Type:      ankiModelStandard,
+		Mod:       now.Unix(),
+		Usn:       -1,
+		Did:       project.Deck.Identifier,
+		CSS:       string(css),
+		LatexPre:  latexPre,
+		LatexPost: latexPost,
+		Tags:      []string{},
+		Vers:      []int{},
+	}
+
+	names := make([]string, len(project.Model.Fields))
+	for i, field := range project.Model.Fields {
+		names[i] = field.Name
+		font := field.Font.Name
+		if font == "" {
+			font = ankiDefaultFont
+		}
+		size := field.Font.Size
+		if size == 0 {
+			size = ankiDefaultFontSize
+		}
+		model.Flds = append(model.Flds, ankiField{
+			Name:  field.Name,
+			Ord:   i,
+			RTL:   field.RTL,
+			Font:  font,
+			Size:  size,
+			Media: []string{},
+		})
+	}
+	model.Sortf = sortFieldIndex(project.Model.Fields)
+
+	cloze := project.Model.Kind == "cloze"
+	if cloze {
+		model.Type = ankiModelCloze
+		if len(project.Model.Templates) != 1 {
+			return nil, fmt.Errorf("cloze model %q must have exactly one template, has %d", project.Model.Name, len(project.Model.Templates))
+		}
+	}
+
+	var clozeFields []int
+	for i, template := range project.Model.Templates {
+		qfmt, err := os.ReadFile(template.QFmt)
+		if err != nil {
+			return nil, err
+		}
+		afmt, err := os.ReadFile(template.AFmt)
+		if err != nil {
+			return nil, err
+		}
+		model.Tmpls = append(model.Tmpls, ankiTemplate{
+			Name: template.Name,
+			Ord:  i,
+			QFmt: string(qfmt),
+			AFmt: string(afmt),
+		})
+		if cloze {
+			// Cloze cards come from the deletions in the note, not from
+			// per-template field requirements, so a cloze model has no req.
+			if clozeFields = clozeFieldOrds(string(qfmt), names); len(clozeFields) == 0 {
+				return nil, fmt.Errorf("cloze model %q: template %q has no {{cloze:Field}} reference", project.Model.Name, template.Name)
+			}
+			continue
+		}
+		model.Req = append(model.Req, []any{i, "any", templateFieldOrds(string(qfmt), names)})
+	}
+
+	decks := []ankiDeck{
+		newAnkiDeck(1, "Default", now),
+		newAnkiDeck(project.Deck.Identifier, project.Deck.Name, now),
+	}
+
+	return &ankiCollection{Model: model, Decks: decks, Fields: project.Model.Fields, ClozeFields: clozeFields, MathJax: project.Model.Style.MathJax}, nil
+}
+
+// readLatex returns the content of a LaTeX prefix/postfix file, or fallback
+// (Anki's own default) when the file is blank.
+func readLatex(filename, fallback string) (string, error) {
+	latex, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(string(latex)) == "" {
+		return fallback, nil
+	}
+	return string(latex), nil
+}
+
+func newAnkiDeck(id int64, name string, now time.Time) ankiDeck {
+	return ankiDeck{
+		ID:        id,
+		Name:      name,
+		Mod:       now.Unix(),
+		Usn:       -1,
+		Conf:      1,
+		ExtendRev: 50,
+	}
+}
+
+// sortFieldIndex returns the ordinal of the first field marked Index, which
+// Anki sorts and checksums notes by; the first field when none is marked.
+func sortFieldIndex(fields []types.FlashcardField) int {
+	for i, field := range fields {
+		if field.Index {
+			return i
+		}
+	}
+	return 0
+}
+
+// templateFieldRefs returns the field name of every {{...}} reference in a
+// card template, in order, with conditional markers ({{#F}}, {{^F}}, {{/F}})
+// and filters ({{text:F}}) stripped. Special fields such as {{FrontSide}} are
+// included; callers match the names against the model's fields.
+func templateFieldRefs(template string) []string {
+	var refs []string
+	for _, m := range ankiFieldRefRe.FindAllStringSubmatch(template, -1) {
+		ref := strings.TrimSpace(m[1])
+		ref = strings.TrimLeft(ref, "#^/")
+		if i := strings.LastIndex(ref, ":"); i != -1 {
+			ref = ref[i+1:]
+		}
+		refs = append(refs, strings.TrimSpace(ref))
+	}
+	return refs
+}
+
+// templateFieldOrds returns the ordinals of the model fields a card template
+// references, in field order. Conditional ({{#F}}, {{^F}}, {{/F}}) and
+// filtered ({{text:F}}) references count; special fields such as
+// {{FrontSide}} do not, since they are not in names.
+func templateFieldOrds(template string, names []string) []int {
+	referenced := map[string]bool{}
+	for _, ref := range templateFieldRefs(template) {
+		referenced[ref] = true
+	}
+
+	ords := []int{}
+	for i, name := range names {
+		if referenced[name] {
+			ords = append(ords, i)
+		}
+	}
+	return ords
+}
+
+// clozeFieldOrds returns the ordinals of the model fields a cloze template
+// renders through the cloze filter ({{cloze:F}}, possibly combined with other
+// filters), in field order.
+func clozeFieldOrds(template string, names []string) []int {
+	referenced := map[string]bool{}
+	for _, m := range ankiFieldRefRe.FindAllStringSubmatch(template, -1) {
+		filters := strings.Split(strings.TrimSpace(m[1]), ":")
+		name := strings.TrimSpace(filters[len(filters)-1])
+		for _, filter := range filters[:len(filters)-1] {
+			if strings.TrimSpace(filter) == "cloze" {
+				referenced[name] = true
+			}
+		}
+	}
+
+	ords := []int{}
+	for i, name := range names {
+		if referenced[name] {
+			ords = append(ords, i)
+		}
+	}
+	return ords
+}
+
+// clozeCardOrds returns the card ordinals a cloze note produces: one per
+// distinct deletion number found in its cloze fields, {{cN::...}} giving
+// ordinal N-1, in ascending order.
+func clozeCardOrds(clozeFields []int, fields []string) []int {
+	seen := map[int]bool{}
+	for _, f := range clozeFields {
+		for _, m := range ankiClozeRe.FindAllStringSubmatch(fields[f], -1) {
+			n, err := strconv.Atoi(m[1])
+			if err == nil {
+				seen[n-1] = true
+			}
+		}
+	}
+
+	ords := make([]int, 0, len(seen))
+	for ord := range seen {
+		ords = append(ords, ord)
+	}
+	slices.Sort(ords)
+	return ords
+}
+
+// noteCardOrds returns the template ordinals a note produces cards for: every
+// template one of whose referenced fields is non-empty (the model's "any"
+// requirement), matching what Anki itself would generate on import.
+func noteCardOrds(model ankiModel, fields []string) []int {
+	var ords []int
+	for _, req := range model.Req {
+		ord := req[0].(int)
+		for _, f := range req[2].([]int) {
+			if strings.TrimSpace(fields[f]) != "" {
+				ords = append(ords, ord)
+				break
+			}
+		}
+	}
+	return ords
+}
+
+// writeAnkiCollection creates the SQLite collection at filename and fills it
+// with the col row, one notes row per note and one cards row per generated
+// card. Note and card ids are millisecond timestamps, as Anki's own are.
+// Media referenced by the rendered fields is collected into media.
+func writeAnkiCollection(filename string, collection *ankiCollection, notes []Note, media *ankiMedia, now time.Time) error {
+	db, err := sql.Open("sqlite", filename)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(ankiSchema); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertAnkiCol(tx, collection, now); err != nil {
+		return err
+	}
+
+	model := collection.Model
+	deckID := collection.Decks[len(collection.Decks)-1].ID
+	indexOrds := indexFieldOrds(collection.Fields)
+	id := now.UnixMilli()
+	for i, note := range notes {
+		noteID := id
+		id++
+
+		fields, err := renderNoteFields(collection.Fields, collection.MathJax, note)
+		if err != nil {
+			return err
+		}
+		for f := range fields {
+			if fields[f], err = media.collect(fields[f]); err != nil {
+				return fmt.Errorf("%s: field %s: %w", note.Position(), model.Flds[f].Name, err)
+			}
+		}
+		sfld := ankiHTMLTagRe.ReplaceAllString(fields[model.Sortf], "")
+		guid := note.GUID
+		if guid == "" {
+			guid = noteGUID(model.ID, noteIndex(indexOrds, note))
+		}
+		_, err = tx.Exec(
+			"INSERT INTO notes (id, guid, mid, mod, usn, tags, flds, sfld, csum, flags, data) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			noteID, guid, model.ID, now.Unix(), -1,
+			ankiTags(note.Tags), strings.Join(fields, "\x1f"), sfld, fieldChecksum(sfld), 0, "")
+		if err != nil {
+			return err
+		}
+
+		ords := noteCardOrds(model, note.Fields)
+		if model.Type == ankiModelCloze {
+			ords = clozeCardOrds(collection.ClozeFields, note.Fields)
+			if len(ords) == 0 {
+				var names []string
+				for _, f := range collection.ClozeFields {
+					names = append(names, model.Flds[f].Name)
+				}
+				return fmt.Errorf("%s: no cloze deletions ({{c1::...}}) in %s", note.Position(), strings.Join(names, ", "))
+			}
+		}
+
+		for _, ord := range ords {
+			_, err := tx.Exec(
+				"INSERT INTO cards (id, nid, did, ord, mod, usn, type, queue, due, ivl, factor, reps, lapses, left, odue, odid, flags, data) VALUES (?, ?, ?, ?, ?, ?, 0, 0, ?, 0, 0, 0, 0, 0, 0, 0, 0, '')",
+				id, noteID, deckID, ord, now.Unix(), -1, i+1)
+			if err != nil {
+				return err
+			}
+			id++
+		}
+	}
+
+	return tx.Commit()
+}
+
+// insertAnkiCol writes the single col row holding the collection-wide
+// configuration and the model/deck JSON maps (keyed by stringified id).
+func insertAnkiCol(tx *sql.Tx, collection *ankiCollection, now time.Time) error {
+	model := collection.Model
+	models, err := json.Marshal(map[string]ankiModel{strconv.FormatInt(model.ID, 10): model})
+	if err != nil {
+		return err
+	}
+
+	deckMap := make(map[string]ankiDeck, len(collection.Decks))
+	for _, deck := range collection.Decks {
+		deckMap[strconv.FormatInt(deck.ID, 10)] = deck
+	}
+	decks, err := json.Marshal(deckMap)
+	if err != nil {
+		return err
+	}
+
+	deckID := collection.Decks[len(collection.Decks)-1].ID
+	conf, err := json.Marshal(map[string]any{
+		"activeDecks":   []int64{deckID},
+		"addToCur":      true,
+		"collapseTime":  1200,
+		"curDeck":       deckID,
+		"curModel":      strconv.FormatInt(model.ID, 10),
+		"dueCounts":     true,
+		"estTimes":      true,
+		"newBury":       true,
+		"newSpread":     0,
+		"nextPos":       1,
+		"sortBackwards": false,
+		"sortType":      "noteFld",
+		"timeLim":       0,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO col (id, crt, mod, scm, ver, dty, usn, ls, conf, models, decks, dconf, tags) VALUES (1, ?, ?, ?, 11, 0, 0, 0, ?, ?, ?, ?, '{}')",
+		now.Unix(), now.UnixMilli(), now.UnixMilli(), string(conf), string(models), string(decks), ankiDeckConf)
+	return err
+}
+
+// writeAnkiArchive zips the collection database and the media into the
+// .apkg at filename. Media files are stored as "0", "1", …; the "media" JSON
+// map gives each one's name in the collection.
+func writeAnkiArchive(filename, dbfile string, media *ankiMedia) error {
+	out, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	archive := zip.NewWriter(out)
+
+	if err := addArchiveFile(archive, "collection.anki2", dbfile); err != nil {
+		return err
+	}
+
+	paths, names := media.entries()
+	mediaMap := make(map[string]string, len(names))
+	for i, path := range paths {
+		entry := strconv.Itoa(i)
+		mediaMap[entry] = names[i]
+		if err := addArchiveFile(archive, entry, path); err != nil {
+			return err
+		}
+	}
+
+	w, err := archive.Create("media")
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(w).Encode(mediaMap); err != nil {
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// addArchiveFile copies the file at path into archive as name.
+func addArchiveFile(archive *zip.Writer, name, path string) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// ankiTags formats tags as Anki stores them: space-separated, with a leading
+// and trailing space. Spaces inside a tag would split it, so they become "_".
+func ankiTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	cleaned := make([]string, len(tags))
+	for i, tag := range tags {
+		cleaned[i] = strings.ReplaceAll(strings.TrimSpace(tag), " ", "_")
+	}
+	return " " + strings.Join(cleaned, " ") + " "
+}
+
+// fieldChecksum is Anki's note csum: the first 8 hex digits of the SHA-1 of
+// the sort field with HTML stripped, as an integer. Anki uses it to find
+// duplicate first fields.
+func fieldChecksum(field string) int64 {
+	sum := sha1.Sum([]byte(ankiHTMLTagRe.ReplaceAllString(field, "")))
+	return int64(binary.BigEndian.Uint32(sum[:4]))
+}
+
+// ankiBase91 is the alphabet Anki encodes note GUIDs with.
+const ankiBase91 = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!#$%&()*+,-./:;<=>?@[]^_`{|}~"
+
+// noteGUID derives a note's GUID from the model identifier and the note's
+// index values (see noteIndex): the first 8 bytes of their SHA-256,
+// base91-encoded the way Anki encodes its own GUIDs. Editing a non-index
+// field keeps the GUID, so re-importing the package updates the note in
+// place instead of adding a duplicate. A note with a GUID of its own (an
+// imported deck's, see guidColumn) keeps that one instead.
+func noteGUID(modelID int64, index []string) string {
+	sum := sha256.Sum256([]byte(strconv.FormatInt(modelID, 10) + "\x1f" + strings.Join(index, "\x1f")))
+	return base91(binary.BigEndian.Uint64(sum[:8]))
+}
+
+// indexFieldOrds returns the ordinals of the fields marked Index, or just the
+// first field when none is marked (Anki's own notion of a note's key).
+func indexFieldOrds(fields []types.FlashcardField) []int {
+	var ords []int
+	for i, field := range fields {
+		if field.Index {
+			ords = append(ords, i)
+		}
+	}
+	if len(ords) == 0 && len(fields) > 0 {
+		ords = []int{0}
+	}
+	return ords
+}
+
+// noteIndex returns a note's raw values for the index fields, trimmed: the
+// key that identifies the note across rebuilds.
+func noteIndex(indexOrds []int, note Note) []string {
+	index := make([]string, len(indexOrds))
+	for i, ord := range indexOrds {
+		index[i] = strings.TrimSpace(note.Fields[ord])
+	}
+	return index
+}
+
+// checkDuplicateIndex reports every note whose index values repeat an
+// earlier note's, across all data files; two such notes would share a GUID
+// and overwrite each other in Anki.
+func checkDuplicateIndex(fields []types.FlashcardField, notes []Note) error {
+	indexOrds := indexFieldOrds(fields)
+	names := make([]string, len(indexOrds))
+	for i, ord := range indexOrds {
+		names[i] = fields[ord].Name
+	}
+
+	first := map[string]Note{}
+	var errs []error
+	for _, note := range notes {
+		index := noteIndex(indexOrds, note)
+		key := strings.Join(index, "\x1f")
+		if prev, ok := first[key]; ok {
+			errs = append(errs, fmt.Errorf("%s: duplicate index %s = %q (first at %s)",
+				note.Position(), strings.Join(names, ", "), strings.Join(index, ", "), prev.Position()))
+			continue
+		}
+		first[key] = note
+	}
+	return errors.Join(errs...)
+}
+
+func base91(n uint64) string {
+	if n == 0 {
+		return ankiBase91[:1]
+	}
+	var buf []byte
+	for n > 0 {
+		buf = append(buf, ankiBase91[n%91])
+		n /= 91
+	}
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	return string(buf)
+}
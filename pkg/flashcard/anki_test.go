@@ -0,0 +1,399 @@
+package flashcard
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// openTestPackage extracts collection.anki2 from the .apkg at filename and
+// opens it, returning the database and the package's media map.
+func openTestPackage(t *testing.T, filename string) (*sql.DB, map[string]string) {
+	t.Helper()
+	archive, err := zip.OpenReader(filename)
+	if err != nil {
+		t.Fatalf("open apkg: %v", err)
+	}
+	defer archive.Close()
+
+	dbfile := filepath.Join(t.TempDir(), "collection.anki2")
+	media := map[string]string{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		switch f.Name {
+		case "collection.anki2":
+			if err := os.WriteFile(dbfile, data, 0o644); err != nil {
+				t.Fatalf("write collection: %v", err)
+			}
+		case "media":
+			if err := json.Unmarshal(data, &media); err != nil {
+				t.Fatalf("media map: %v", err)
+			}
+		}
+	}
+
+	db, err := sql.Open("sqlite", dbfile)
+	if err != nil {
+		t.Fatalf("open collection: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, media
+}
+
+// buildTestPackage reads the project at projectFile and builds its package.
+func buildTestPackage(t *testing.T, projectFile string) string {
+	t.Helper()
+	project, err := ReadProject(projectFile)
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	notes, err := ReadNotes(project)
+	if err != nil {
+		t.Fatalf("ReadNotes: %v", err)
+	}
+	outfile, err := buildAnkiPackage(project, notes)
+	if err != nil {
+		t.Fatalf("buildAnkiPackage: %v", err)
+	}
+	return outfile
+}
+
+// TestBuildAnkiPackage: the package holds the project's model and deck under
+// their YAML identifiers, one note per data row and one card per note.
+func TestBuildAnkiPackage(t *testing.T) {
+	outfile := buildTestPackage(t, writeTestProject(t, testProjectYAML, nil))
+	if filepath.Base(outfile) != "deck.apkg" {
+		t.Errorf("outfile = %q, want deck.apkg", outfile)
+	}
+
+	db, media := openTestPackage(t, outfile)
+	if len(media) != 0 {
+		t.Errorf("media = %v, want empty", media)
+	}
+
+	var models, decks string
+	if err := db.QueryRow("SELECT models, decks FROM col").Scan(&models, &decks); err != nil {
+		t.Fatalf("select col: %v", err)
+	}
+	var modelMap map[string]ankiModel
+	if err := json.Unmarshal([]byte(models), &modelMap); err != nil {
+		t.Fatalf("models json: %v", err)
+	}
+	model, ok := modelMap["1700000000002"]
+	if !ok {
+		t.Fatalf("model 1700000000002 missing from %v", modelMap)
+	}
+	if model.Name != "Test Model" || len(model.Flds) != 2 || len(model.Tmpls) != 1 {
+		t.Errorf("model = %+v", model)
+	}
+	if model.LatexPre != testProjectFiles["prefix.tex"] || model.LatexPost != testProjectFiles["postfix.tex"] {
+		t.Errorf("latex = %q / %q, want the project's prefix/postfix files", model.LatexPre, model.LatexPost)
+	}
+	if model.Tmpls[0].QFmt != "{{Front}}" {
+		t.Errorf("qfmt = %q, want {{Front}}", model.Tmpls[0].QFmt)
+	}
+	if !strings.Contains(decks, `"1700000000001"`) || !strings.Contains(decks, `"Test Deck"`) {
+		t.Errorf("decks = %s, want Test Deck under 1700000000001", decks)
+	}
+
+	rows, err := db.Query("SELECT flds, tags, mid FROM notes ORDER BY id")
+	if err != nil {
+		t.Fatalf("select notes: %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var flds, tags string
+		var mid int64
+		if err := rows.Scan(&flds, &tags, &mid); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		if mid != 1700000000002 {
+			t.Errorf("note mid = %d, want 1700000000002", mid)
+		}
+		if tags != " lesson1 " {
+			t.Errorf("note tags = %q, want %q", tags, " lesson1 ")
+		}
+		got = append(got, flds)
+	}
+	want := []string{"kot\x1fcat", "pies\x1fdog"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("notes = %q, want %q", got, want)
+	}
+
+	var cards int
+	if err := db.QueryRow("SELECT count(*) FROM cards WHERE did = 1700000000001").Scan(&cards); err != nil {
+		t.Fatalf("count cards: %v", err)
+	}
+	if cards != 2 {
+		t.Errorf("cards in deck = %d, want 2", cards)
+	}
+}
+
+// TestBuildAnkiPackageStableGUIDs: rebuilding the same project yields the same
+// note GUIDs, so Anki matches the notes on re-import.
+func TestBuildAnkiPackageStableGUIDs(t *testing.T) {
+	projectFile := writeTestProject(t, testProjectYAML, nil)
+
+	guids := func() string {
+		db, _ := openTestPackage(t, buildTestPackage(t, projectFile))
+		rows, err := db.Query("SELECT guid FROM notes ORDER BY id")
+		if err != nil {
+			t.Fatalf("select guid: %v", err)
+		}
+		defer rows.Close()
+		var all []string
+		for rows.Next() {
+			var guid string
+			if err := rows.Scan(&guid); err != nil {
+				t.Fatalf("scan: %v", err)
+			}
+			all = append(all, guid)
+		}
+		return strings.Join(all, ",")
+	}
+
+	first, second := guids(), guids()
+	if first == "" || first != second {
+		t.Errorf("GUIDs differ between builds: %q vs %q", first, second)
+	}
+}
+
+// TestNoteGUIDFollowsIndex: editing a non-index field keeps a note's GUID;
+// editing the index field, or building under another model, changes it.
+func TestNoteGUIDFollowsIndex(t *testing.T) {
+	guid := func(yaml, words string) string {
+		db, _ := openTestPackage(t, buildTestPackage(t, writeTestProject(t, yaml, map[string]string{"words.tsv": words})))
+		var guid string
+		if err := db.QueryRow("SELECT guid FROM notes").Scan(&guid); err != nil {
+			t.Fatalf("select guid: %v", err)
+		}
+		return guid
+	}
+
+	base := guid(testProjectYAML, "Front\tBack\nkot\tcat\n")
+	if got := guid(testProjectYAML, "Front\tBack\nkot\ta cat\n"); got != base {
+		t.Errorf("GUID changed with a non-index edit: %q vs %q", got, base)
+	}
+	if got := guid(testProjectYAML, "Front\tBack\nkotek\tcat\n"); got == base {
+		t.Errorf("GUID unchanged after editing the index field")
+	}
+	otherModel := strings.Replace(testProjectYAML, "identifier: 1700000000002", "identifier: 1700000000003", 1)
+	if got := guid(otherModel, "Front\tBack\nkot\tcat\n"); got == base {
+		t.Errorf("GUID unchanged under a different model")
+	}
+}
+
+// TestBuildAnkiPackageDuplicateIndex: repeated index values are reported
+// across data files, each with both positions.
+func TestBuildAnkiPackageDuplicateIndex(t *testing.T) {
+	projectYAML := testProjectYAML + "  - filename: more.tsv\n"
+	projectFile := writeTestProject(t, projectYAML, map[string]string{
+		"words.tsv": "Front\tBack\nkot\tcat\npies\tdog\n",
+		"more.tsv":  "Front\tBack\n kot \tanother cat\npies\tanother dog\n",
+	})
+	project, err := ReadProject(projectFile)
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	notes, err := ReadNotes(project)
+	if err != nil {
+		t.Fatalf("ReadNotes: %v", err)
+	}
+	_, err = buildAnkiPackage(project, notes)
+	if err == nil {
+		t.Fatal("expected duplicate index errors, got nil")
+	}
+	for _, want := range []string{`more.tsv:2: duplicate index Front = "kot" (first at `, `more.tsv:3: duplicate index Front = "pies"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v, want it to contain %q", err, want)
+		}
+	}
+}
+
+// TestTemplateFieldOrds: plain, conditional and filtered references count;
+// special fields and unknown names do not.
+func TestTemplateFieldOrds(t *testing.T) {
+	names := []string{"Front", "Back", "Notes"}
+	got := templateFieldOrds("{{#Notes}}{{text:Notes}}{{/Notes}}{{FrontSide}}{{ Front }}", names)
+	if len(got) != 2 || got[0] != 0 || got[1] != 2 {
+		t.Errorf("templateFieldOrds = %v, want [0 2]", got)
+	}
+}
+
+// TestBuildAnkiPackageMissingIdentifier: a zero deck identifier is rejected
+// rather than written into the collection.
+func TestBuildAnkiPackageMissingIdentifier(t *testing.T) {
+	projectFile := writeTestProject(t, strings.Replace(testProjectYAML, "identifier: 1700000000001", "identifier: 0", 1), nil)
+	project, err := ReadProject(projectFile)
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	if _, err := buildAnkiPackage(project, nil); err == nil {
+		t.Error("expected an error for a zero deck identifier, got nil")
+	}
+}
+
+// testClozeYAML turns testProjectYAML's model into a cloze model whose single
+// template renders Front through the cloze filter.
+var testClozeYAML = strings.Replace(testProjectYAML, "kind: normal", "kind: cloze", 1)
+
+// TestBuildAnkiPackageCloze: a cloze model is written with Anki's cloze type
+// and each note gets one card per distinct deletion number.
+func TestBuildAnkiPackageCloze(t *testing.T) {
+	projectFile := writeTestProject(t, testClozeYAML, map[string]string{
+		"front.html": "{{cloze:Front}}",
+		"back.html":  "{{cloze:Front}}<br>{{Back}}",
+		"words.tsv":  "Front\tBack\n{{c1::kot}} i {{c2::pies}} {{c1::śpią}}\tcat and dog sleep\n{{c3::dom}}\thouse\n",
+	})
+	db, _ := openTestPackage(t, buildTestPackage(t, projectFile))
+
+	var models string
+	if err := db.QueryRow("SELECT models FROM col").Scan(&models); err != nil {
+		t.Fatalf("select col: %v", err)
+	}
+	var modelMap map[string]ankiModel
+	if err := json.Unmarshal([]byte(models), &modelMap); err != nil {
+		t.Fatalf("models json: %v", err)
+	}
+	if model := modelMap["1700000000002"]; model.Type != ankiModelCloze || len(model.Req) != 0 {
+		t.Errorf("model type = %d, req = %v; want cloze type with no req", model.Type, model.Req)
+	}
+
+	rows, err := db.Query("SELECT n.sfld, c.ord FROM cards c JOIN notes n ON n.id = c.nid ORDER BY c.id")
+	if err != nil {
+		t.Fatalf("select cards: %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var sfld string
+		var ord int
+		if err := rows.Scan(&sfld, &ord); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		got = append(got, fmt.Sprintf("%s#%d", strings.Fields(sfld)[0], ord))
+	}
+	want := []string{"{{c1::kot}}#0", "{{c1::kot}}#1", "{{c3::dom}}#2"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("cards = %q, want %q", got, want)
+	}
+}
+
+// TestBuildAnkiPackageClozeErrors: a cloze template without a cloze field
+// and a row without deletions are both rejected.
+func TestBuildAnkiPackageClozeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		message string
+	}{
+		{
+			name:    "template without cloze filter",
+			files:   map[string]string{"words.tsv": "Front\tBack\n{{c1::kot}}\tcat\n"},
+			message: "no {{cloze:Field}} reference",
+		},
+		{
+			name: "row without deletions",
+			files: map[string]string{
+				"front.html": "{{cloze:Front}}",
+				"words.tsv":  "Front\tBack\n{{c1::kot}}\tcat\npies\tdog\n",
+			},
+			message: "words.tsv:3: no cloze deletions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project, err := ReadProject(writeTestProject(t, testClozeYAML, tt.files))
+			if err != nil {
+				t.Fatalf("ReadProject: %v", err)
+			}
+			notes, err := ReadNotes(project)
+			if err != nil {
+				t.Fatalf("ReadNotes: %v", err)
+			}
+			if _, err := buildAnkiPackage(project, notes); err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.message)
+			}
+		})
+	}
+}
+
+// TestBuildAnkiPackageMedia: local images and sounds are packaged once under
+// content-hashed names and the fields point at those names; remote
+// references are left alone.
+func TestBuildAnkiPackageMedia(t *testing.T) {
+	projectYAML := strings.Replace(testProjectYAML, "      format: text\n      index: true", "      format: markdown\n      index: true", 1)
+	projectFile := writeTestProject(t, projectYAML, map[string]string{
+		"img/kot.png":   "png",
+		"audio/kot.mp3": "mp3",
+		"words.tsv": "Front\tBack\n" +
+			"![](img/kot.png) [sound:audio/kot.mp3]\tcat\n" +
+			"![](./img/kot.png) ![](https://example.com/x.png)\tcat again\n",
+	})
+	db, media := openTestPackage(t, buildTestPackage(t, projectFile))
+
+	if len(media) != 2 {
+		t.Fatalf("media = %v, want two files", media)
+	}
+	png, mp3 := media["0"], media["1"]
+	if !strings.HasSuffix(png, ".png") || !strings.HasSuffix(mp3, ".mp3") || strings.Contains(png, "kot") {
+		t.Errorf("media names = %q, %q, want content-hashed .png and .mp3", png, mp3)
+	}
+
+	rows, err := db.Query("SELECT flds FROM notes ORDER BY id")
+	if err != nil {
+		t.Fatalf("select notes: %v", err)
+	}
+	defer rows.Close()
+	var fronts []string
+	for rows.Next() {
+		var flds string
+		if err := rows.Scan(&flds); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		fronts = append(fronts, strings.Split(flds, "\x1f")[0])
+	}
+	want := []string{
+		`<img src="` + png + `" alt="" /> [sound:` + mp3 + `]`,
+		`<img src="` + png + `" alt="" /> <img src="https://example.com/x.png" alt="" />`,
+	}
+	if strings.Join(fronts, "|") != strings.Join(want, "|") {
+		t.Errorf("fronts = %q, want %q", fronts, want)
+	}
+}
+
+// TestBuildAnkiPackageMissingMedia: a reference to a missing file fails the
+// build, naming the row and the file.
+func TestBuildAnkiPackageMissingMedia(t *testing.T) {
+	projectFile := writeTestProject(t, testProjectYAML, map[string]string{
+		"words.tsv": "Front\tBack\nkot [sound:kot.mp3]\tcat\n",
+	})
+	project, err := ReadProject(projectFile)
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	notes, err := ReadNotes(project)
+	if err != nil {
+		t.Fatalf("ReadNotes: %v", err)
+	}
+	_, err = buildAnkiPackage(project, notes)
+	if err == nil || !strings.Contains(err.Error(), "words.tsv:2: field Front") || !strings.Contains(err.Error(), `"kot.mp3" does not exist`) {
+		t.Errorf("error = %v, want a missing-media error for words.tsv:2", err)
+	}
+}
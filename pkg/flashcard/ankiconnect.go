@@ -0,0 +1,94 @@
+package flashcard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ankiConnectVersion is the AnkiConnect API version the requests are written
+// against.
+const ankiConnectVersion = 6
+
+// ankiConnect is a client for the AnkiConnect add-on's JSON-over-HTTP API:
+// every call is a POST of {action, version, params} answered by
+// {result, error}.
+type ankiConnect struct {
+	url    string
+	key    string
+	client *http.Client
+}
+
+func newAnkiConnect(url, key string) *ankiConnect {
+	return &ankiConnect{url: url, key: key, client: &http.Client{Timeout: 60 * time.Second}}
+}
+
+// invoke calls one action and decodes its result into result (which may be
+// nil when the result is not needed). An error reported by AnkiConnect is
+// returned as an error naming the action.
+func (c *ankiConnect) invoke(action string, params any, result any) error {
+	request := map[string]any{"action": action, "version": ankiConnectVersion}
+	if params != nil {
+		request["params"] = params
+	}
+	if c.key != "" {
+		request["key"] = c.key
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Post(c.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("AnkiConnect %s: %w (is Anki running with the AnkiConnect add-on?)", action, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("AnkiConnect %s: %s", action, resp.Status)
+	}
+
+	var reply struct {
+		Result json.RawMessage `json:"result"`
+		Error  *string         `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return fmt.Errorf("AnkiConnect %s: %w", action, err)
+	}
+	if reply.Error != nil {
+		return fmt.Errorf("AnkiConnect %s: %s", action, *reply.Error)
+	}
+	if result == nil || len(reply.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(reply.Result, result)
+}
+
+// ankiConnectNote is a note as returned by notesInfo.
+type ankiConnectNote struct {
+	NoteID    int64    `json:"noteId"`
+	ModelName string   `json:"modelName"`
+	Tags      []string `json:"tags"`
+	Fields    map[string]struct {
+		Value string `json:"value"`
+		Order int    `json:"order"`
+	} `json:"fields"`
+}
+
+// ankiConnectNewNote is a note for addNotes.
+type ankiConnectNewNote struct {
+	DeckName  string            `json:"deckName"`
+	ModelName string            `json:"modelName"`
+	Fields    map[string]string `json:"fields"`
+	Tags      []string          `json:"tags"`
+}
+
+// ankiConnectTemplate is a card template for createModel and
+// modelTemplateAdd.
+type ankiConnectTemplate struct {
+	Name  string `json:"Name"`
+	Front string `json:"Front"`
+	Back  string `json:"Back"`
+}
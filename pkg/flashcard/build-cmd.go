@@ -2,21 +2,47 @@ package flashcard
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/spf13/cobra"
 )
 
+var _formats []string
+
 var buildCmd = &cobra.Command{
 	Use:   "build",
 	Short: "Build flashcard project",
-	Long:  "Build flashard project",
+	Long: "Build a flashcard project into an Anki package (.apkg) at the project's filename, " +
+		"and/or into CSV, TSV, Quizlet/Mochi term-definition text or printable PDF cards next to it.",
 	Run: func(cmd *cobra.Command, args []string) {
-		// flashcardFile, err := buildAnkiPackage(_project)
-		// if err != nil {
-		// 	log.Fatal(err)
-		// }
-		// fmt.Println(flashcardFile)
-		fmt.Println("Not implemented!")
+		// Resolve every requested format before reading the project, so an
+		// unknown format is rejected before anything is written.
+		exporters := make([]Exporter, 0, len(_formats))
+		for _, format := range _formats {
+			exporter, err := exporterFor(format)
+			if err != nil {
+				log.Fatal(err)
+			}
+			exporters = append(exporters, exporter)
+		}
+
+		project, err := ReadProject(_project)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		notes, err := ReadNotes(project)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, exporter := range exporters {
+			outfile, err := exporter.Export(project, notes)
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println(outfile)
+		}
 	},
 }
 
@@ -24,5 +50,6 @@ func init() {
 	mainCmd.AddCommand(buildCmd)
 
 	buildCmd.Flags().StringVarP(&_project, "project", "p", "flashcard.yml", "flashcard project file")
+	buildCmd.Flags().StringSliceVarP(&_formats, "format", "f", []string{"apkg"}, "output format(s): apkg, csv, tsv, quizlet, pdf (repeatable, or comma-separated)")
 	// buildCmd.MarkFlagRequired("project")
 }
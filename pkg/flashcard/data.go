@@ -0,0 +1,253 @@
+package flashcard
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dpurge/cli-tools/pkg/types"
+)
+
+// Note is one flashcard note read from a project data file: its field values,
+// in Model.Fields order, plus the tags attached to it. GUID is the note's
+// Anki GUID from the data file's guidColumn, or "" when it is derived from
+// the index fields (noteGUID). File and Line locate the row the note came
+// from, for error reporting; Line is 0 for notes read from an ebook source.
+type Note struct {
+	Fields []string
+	Tags   []string
+	GUID   string
+	File   string
+	Line   int
+}
+
+// guidColumn is the optional data file column holding each note's Anki
+// GUID, as written by import so a rebuilt deck updates the notes it came
+// from. Anki field names cannot start with '#', so it never names a field.
+const guidColumn = "#guid"
+
+// Position returns the note's "file:line" source location, or just the file
+// when the line is unknown (notes read from an ebook source).
+func (n Note) Position() string {
+	if n.Line == 0 {
+		return n.File
+	}
+	return fmt.Sprintf("%s:%d", n.File, n.Line)
+}
+
+// DataError is a problem in a data file, located by 1-based line and,
+// where known, column (0 when the whole row is at fault).
+type DataError struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *DataError) Error() string {
+	if e.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d: %v", e.File, e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+func (e *DataError) Unwrap() error { return e.Err }
+
+// ReadNotes reads every project.Data file, in order, into notes.
+//
+// A data file is a table with a header row followed by one note per row. The
+// delimiter follows the file extension: ".csv" is comma-separated (RFC 4180
+// quoting, so a value may contain commas, quotes and newlines); anything else
+// (".tsv", ".txt") is tab-separated with no quoting, one note per line. Header
+// names must match Model.Fields[].Name exactly — in any order, each field
+// exactly once — and values are reordered into Model.Fields order. A
+// guidColumn column may follow, giving notes their GUIDs. Blank lines are
+// skipped. Every note inherits the data entry's Tags.
+//
+// A data entry with a Source instead of a Filename reads its notes from the
+// vocabulary and models blocks of an ebook project (see readEbookSource).
+func ReadNotes(project *types.FlashcardProject) ([]Note, error) {
+	var notes []Note
+	for _, data := range project.Data {
+		var fileNotes []Note
+		var err error
+		if data.Source != "" {
+			fileNotes, err = readEbookSource(data, project.Model.Fields)
+		} else {
+			fileNotes, err = readDataFile(data, project.Model.Fields)
+		}
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, fileNotes...)
+	}
+	return notes, nil
+}
+
+// readDataFile reads one data file into notes (see ReadNotes for the format).
+func readDataFile(data types.FlashcardData, fields []types.FlashcardField) ([]Note, error) {
+	f, err := os.Open(data.Filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rows := newRowReader(data.Filename, f)
+
+	header, headerLine, err := rows.next()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%s: empty data file (expected a header row)", data.Filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns, err := dataColumns(header, fields)
+	if err != nil {
+		return nil, &DataError{File: data.Filename, Line: headerLine, Err: err}
+	}
+
+	var notes []Note
+	for {
+		row, line, err := rows.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(row) != len(header) {
+			return nil, &DataError{
+				File: data.Filename,
+				Line: line,
+				Err:  fmt.Errorf("expected %d columns (%s), got %d", len(header), strings.Join(header, ", "), len(row)),
+			}
+		}
+
+		note := Note{
+			Fields: make([]string, len(fields)),
+			Tags:   append([]string(nil), data.Tags...),
+			File:   data.Filename,
+			Line:   line,
+		}
+		for column, value := range row {
+			if columns[column] < 0 {
+				note.GUID = strings.TrimSpace(value)
+			} else {
+				note.Fields[columns[column]] = value
+			}
+		}
+		notes = append(notes, note)
+	}
+
+	return notes, nil
+}
+
+// dataColumns maps each header column to its Model.Fields ordinal, or to -1
+// for the guidColumn. Every other column must name a model field, no column
+// may repeat, and every field must have a column.
+func dataColumns(header []string, fields []types.FlashcardField) ([]int, error) {
+	ords := make(map[string]int, len(fields))
+	for i, field := range fields {
+		ords[field.Name] = i
+	}
+
+	columns := make([]int, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, name := range header {
+		ord, ok := ords[name]
+		if name == guidColumn {
+			ord, ok = -1, true
+		}
+		if !ok {
+			return nil, fmt.Errorf("column %d: %q is not a model field", i+1, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("column %d: field %q appears more than once", i+1, name)
+		}
+		seen[name] = true
+		columns[i] = ord
+	}
+
+	var missing []string
+	for _, field := range fields {
+		if !seen[field.Name] {
+			missing = append(missing, field.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("header has no column for field(s): %s", strings.Join(missing, ", "))
+	}
+
+	return columns, nil
+}
+
+// rowReader yields a data file's non-blank rows together with the 1-based
+// line each starts on, hiding the CSV/TSV difference from readDataFile.
+type rowReader struct {
+	filename string
+	csv      *csv.Reader
+	lines    *bufio.Scanner
+	line     int
+	first    bool
+}
+
+func newRowReader(filename string, r io.Reader) *rowReader {
+	rows := &rowReader{filename: filename, first: true}
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		rows.csv = csv.NewReader(r)
+		rows.csv.FieldsPerRecord = -1 // column counts are checked against the header by the caller
+	} else {
+		rows.lines = bufio.NewScanner(r)
+		rows.lines.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	}
+	return rows
+}
+
+// next returns the next non-blank row, or io.EOF after the last one.
+func (r *rowReader) next() ([]string, int, error) {
+	row, line, err := r.read()
+	if err != nil {
+		return nil, line, err
+	}
+	if r.first {
+		// A UTF-8 byte order mark (left by spreadsheet exports) is not part of
+		// the first header name.
+		row[0] = strings.TrimPrefix(row[0], "\ufeff")
+		r.first = false
+	}
+	return row, line, nil
+}
+
+func (r *rowReader) read() ([]string, int, error) {
+	if r.csv != nil {
+		row, err := r.csv.Read()
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, parseErr.Line, &DataError{File: r.filename, Line: parseErr.Line, Column: parseErr.Column, Err: parseErr.Err}
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		line, _ := r.csv.FieldPos(0)
+		return row, line, nil
+	}
+
+	for r.lines.Scan() {
+		r.line++
+		text := strings.TrimSuffix(r.lines.Text(), "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		return strings.Split(text, "\t"), r.line, nil
+	}
+	if err := r.lines.Err(); err != nil {
+		return nil, r.line, &DataError{File: r.filename, Line: r.line + 1, Err: err}
+	}
+	return nil, r.line, io.EOF
+}
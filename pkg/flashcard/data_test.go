@@ -0,0 +1,115 @@
+package flashcard
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// readTestNotes writes a project whose single data file is name with content,
+// and reads its notes.
+func readTestNotes(t *testing.T, name, content string) ([]Note, error) {
+	t.Helper()
+	projectYAML := strings.Replace(testProjectYAML, "filename: words.tsv", "filename: "+name, 1)
+	project, err := ReadProject(writeTestProject(t, projectYAML, map[string]string{name: content}))
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	return ReadNotes(project)
+}
+
+// TestReadNotesFormats: TSV and CSV files with columns in any order are read
+// into Model.Fields order, with the data entry's tags and each row's line.
+func TestReadNotesFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    [][]string
+		lines   []int
+	}{
+		{
+			name:    "tsv",
+			file:    "words.tsv",
+			content: "Front\tBack\nkot\tcat\n\npies\tdog\n",
+			want:    [][]string{{"kot", "cat"}, {"pies", "dog"}},
+			lines:   []int{2, 4},
+		},
+		{
+			name:    "tsv crlf, reordered columns, bom",
+			file:    "words.txt",
+			content: "\ufeffBack\tFront\r\ncat\tkot\r\n",
+			want:    [][]string{{"kot", "cat"}},
+			lines:   []int{2},
+		},
+		{
+			name:    "csv with quoting and embedded newline",
+			file:    "words.csv",
+			content: "Front,Back\n\"kot, domowy\",\"a \"\"house\"\"\ncat\"\npies,dog\n",
+			want:    [][]string{{"kot, domowy", "a \"house\"\ncat"}, {"pies", "dog"}},
+			lines:   []int{2, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notes, err := readTestNotes(t, tt.file, tt.content)
+			if err != nil {
+				t.Fatalf("ReadNotes: %v", err)
+			}
+			if len(notes) != len(tt.want) {
+				t.Fatalf("got %d notes, want %d", len(notes), len(tt.want))
+			}
+			for i, note := range notes {
+				if !reflect.DeepEqual(note.Fields, tt.want[i]) {
+					t.Errorf("note %d fields = %q, want %q", i, note.Fields, tt.want[i])
+				}
+				if note.Line != tt.lines[i] {
+					t.Errorf("note %d line = %d, want %d", i, note.Line, tt.lines[i])
+				}
+				if !reflect.DeepEqual(note.Tags, []string{"lesson1"}) {
+					t.Errorf("note %d tags = %q, want [lesson1]", i, note.Tags)
+				}
+			}
+		})
+	}
+}
+
+// TestReadNotesErrors: header and row problems are reported as DataErrors
+// carrying the file's line (and column, for CSV syntax errors).
+func TestReadNotesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		line    int
+		column  int
+		message string
+	}{
+		{"unknown column", "words.tsv", "Front\tBack\tExtra\n", 1, 0, `"Extra" is not a model field`},
+		{"missing column", "words.tsv", "Front\n", 1, 0, "no column for field(s): Back"},
+		{"duplicate column", "words.tsv", "Front\tBack\tFront\n", 1, 0, `"Front" appears more than once`},
+		{"short row", "words.tsv", "Front\tBack\nkot\tcat\npies\n", 3, 0, "expected 2 columns"},
+		{"csv bare quote", "words.csv", "Front,Back\nko\"t,cat\n", 2, 3, "bare"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readTestNotes(t, tt.file, tt.content)
+			var dataErr *DataError
+			if !errors.As(err, &dataErr) {
+				t.Fatalf("error = %v, want a *DataError", err)
+			}
+			if dataErr.Line != tt.line || dataErr.Column != tt.column {
+				t.Errorf("position = %d:%d, want %d:%d", dataErr.Line, dataErr.Column, tt.line, tt.column)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error = %q, want it to mention %q", err, tt.message)
+			}
+			if !strings.Contains(err.Error(), tt.file+":") {
+				t.Errorf("error = %q, want it to name %s", err, tt.file)
+			}
+		})
+	}
+}
@@ -2,19 +2,44 @@ package flashcard
 
 import (
 	"fmt"
+	"os"
 
+	"github.com/dpurge/cli-tools/pkg/config"
 	"github.com/spf13/cobra"
 )
 
 var doctorCmd = &cobra.Command{
 	Use:   "doctor",
-	Short: "Doctor Cmd short description",
-	Long:  "Doctor Cmd long description",
+	Short: "Check a flashcard project for problems",
+	Long: "Load a flashcard project and check it without building: identifiers, " +
+		"template field references, data file columns, and the notes' index " +
+		"fields. Every problem is reported, not just the first.",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("doctor called")
+		project, err := ReadProject(_project)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERR  proj   %v\n", err)
+			os.Exit(config.ExitCodeError)
+		}
+		fmt.Printf("OK   proj   %s\n", _project)
+
+		healthy := true
+		for _, result := range checkProject(project) {
+			if result.Err != nil {
+				healthy = false
+				fmt.Fprintf(os.Stderr, "ERR  %-5s  %v\n", result.Topic, result.Err)
+			} else {
+				fmt.Printf("OK   %-5s  %s\n", result.Topic, result.Message)
+			}
+		}
+
+		if !healthy {
+			os.Exit(config.ExitCodeError)
+		}
 	},
 }
 
 func init() {
 	mainCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().StringVarP(&_project, "project", "p", "flashcard.yml", "flashcard project file")
 }
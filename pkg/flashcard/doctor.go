@@ -0,0 +1,212 @@
+package flashcard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dpurge/cli-tools/pkg/types"
+)
+
+// ankiSpecialFields are the names Anki substitutes in card templates besides
+// the model's own fields.
+var ankiSpecialFields = []string{"FrontSide", "Tags", "Type", "Deck", "Subdeck", "Card", "CardFlag", "CardID"}
+
+// doctorResult is one line of `flashcard-cli doctor` output: a passed check
+// (Err nil) or a problem, under a short topic such as "tmpl" or "data".
+type doctorResult struct {
+	Topic   string
+	Message string
+	Err     error
+}
+
+// checkProject runs every doctor check against a loaded project and returns
+// the results in report order. Unlike build, it keeps going after a problem,
+// so one run reports everything that needs fixing.
+func checkProject(project *types.FlashcardProject) []doctorResult {
+	var results []doctorResult
+	results = append(results, checkIdentifiers(project)...)
+	results = append(results, checkTemplates(project)...)
+
+	notes, dataResults := checkData(project)
+	results = append(results, dataResults...)
+	results = append(results, checkNotes(project, notes)...)
+	return results
+}
+
+// checkIdentifiers: deck and model identifiers are set, and distinct from
+// each other and from Anki's built-in Default deck (id 1).
+func checkIdentifiers(project *types.FlashcardProject) []doctorResult {
+	var errs []error
+	if project.Deck.Identifier == 0 {
+		errs = append(errs, fmt.Errorf("deck %q has no identifier", project.Deck.Name))
+	} else if project.Deck.Identifier == 1 {
+		errs = append(errs, fmt.Errorf("deck %q uses identifier 1, reserved for Anki's Default deck", project.Deck.Name))
+	}
+	if project.Model.Identifier == 0 {
+		errs = append(errs, fmt.Errorf("model %q has no identifier", project.Model.Name))
+	}
+	if project.Deck.Identifier != 0 && project.Deck.Identifier == project.Model.Identifier {
+		errs = append(errs, fmt.Errorf("deck and model share identifier %d", project.Deck.Identifier))
+	}
+
+	if len(errs) == 0 {
+		return []doctorResult{{Topic: "ident", Message: fmt.Sprintf("deck %d, model %d", project.Deck.Identifier, project.Model.Identifier)}}
+	}
+	var results []doctorResult
+	for _, e := range errs {
+		results = append(results, doctorResult{Topic: "ident", Err: e})
+	}
+	return results
+}
+
+// checkTemplates: every {{Field}} a template references is a model field
+// (or one of Anki's special fields), and a cloze model has a single template
+// with a {{cloze:Field}} reference.
+func checkTemplates(project *types.FlashcardProject) []doctorResult {
+	names := make([]string, len(project.Model.Fields))
+	for i, field := range project.Model.Fields {
+		names[i] = field.Name
+	}
+
+	var results []doctorResult
+	if project.Model.Kind == "cloze" && len(project.Model.Templates) != 1 {
+		results = append(results, doctorResult{Topic: "tmpl", Err: fmt.Errorf("cloze model %q must have exactly one template, has %d", project.Model.Name, len(project.Model.Templates))})
+	}
+
+	for _, template := range project.Model.Templates {
+		healthy := true
+		for _, file := range []string{template.QFmt, template.AFmt} {
+			content, err := readFileString(file)
+			if err != nil {
+				healthy = false
+				results = append(results, doctorResult{Topic: "tmpl", Err: err})
+				continue
+			}
+			var unknown []string
+			for _, ref := range templateFieldRefs(content) {
+				if !slices.Contains(names, ref) && !slices.Contains(ankiSpecialFields, ref) && !slices.Contains(unknown, ref) {
+					unknown = append(unknown, ref)
+				}
+			}
+			if len(unknown) > 0 {
+				healthy = false
+				results = append(results, doctorResult{Topic: "tmpl", Err: fmt.Errorf("%s (%s): {{%s}} not a model field", template.Name, filepath.Base(file), strings.Join(unknown, "}}, {{"))})
+			}
+			if project.Model.Kind == "cloze" && file == template.QFmt && len(clozeFieldOrds(content, names)) == 0 {
+				healthy = false
+				results = append(results, doctorResult{Topic: "tmpl", Err: fmt.Errorf("%s (%s): no {{cloze:Field}} reference", template.Name, filepath.Base(file))})
+			}
+		}
+		if healthy {
+			results = append(results, doctorResult{Topic: "tmpl", Message: template.Name})
+		}
+	}
+	return results
+}
+
+// checkData reads every data entry on its own, so a problem in one file
+// (such as a field without a column) does not hide problems in the next,
+// and returns the notes of the entries that loaded.
+func checkData(project *types.FlashcardProject) ([]Note, []doctorResult) {
+	var notes []Note
+	var results []doctorResult
+	for _, data := range project.Data {
+		var fileNotes []Note
+		var err error
+		name := data.Filename
+		if data.Source != "" {
+			name = data.Source
+			fileNotes, err = readEbookSource(data, project.Model.Fields)
+		} else {
+			fileNotes, err = readDataFile(data, project.Model.Fields)
+		}
+		if err != nil {
+			results = append(results, doctorResult{Topic: "data", Err: err})
+			continue
+		}
+		notes = append(notes, fileNotes...)
+		results = append(results, doctorResult{Topic: "data", Message: fmt.Sprintf("%s (%d notes)", name, len(fileNotes))})
+	}
+	return notes, results
+}
+
+// checkNotes: index fields (the note's identity) are non-empty and unique,
+// and every note produces at least one card — for a cloze model, at least
+// one deletion.
+func checkNotes(project *types.FlashcardProject, notes []Note) []doctorResult {
+	fields := project.Model.Fields
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.Name
+	}
+
+	var cardFields [][]int
+	var clozeFields []int
+	for _, template := range project.Model.Templates {
+		qfmt, err := readFileString(template.QFmt)
+		if err != nil {
+			return nil // already reported by checkTemplates
+		}
+		cardFields = append(cardFields, templateFieldOrds(qfmt, names))
+		if project.Model.Kind == "cloze" {
+			clozeFields = clozeFieldOrds(qfmt, names)
+		}
+	}
+
+	var results []doctorResult
+	indexOrds := indexFieldOrds(fields)
+	for _, note := range notes {
+		for _, ord := range indexOrds {
+			if strings.TrimSpace(note.Fields[ord]) == "" {
+				results = append(results, doctorResult{Topic: "field", Err: fmt.Errorf("%s: index field %s is empty", note.Position(), fields[ord].Name)})
+			}
+		}
+
+		if project.Model.Kind == "cloze" {
+			if len(clozeFields) > 0 && len(clozeCardOrds(clozeFields, note.Fields)) == 0 {
+				results = append(results, doctorResult{Topic: "field", Err: fmt.Errorf("%s: no cloze deletions ({{c1::...}})", note.Position())})
+			}
+			continue
+		}
+		if !producesCard(cardFields, note.Fields) {
+			results = append(results, doctorResult{Topic: "field", Err: fmt.Errorf("%s: every field a card's front uses is empty, so the note has no cards", note.Position())})
+		}
+	}
+
+	if err := checkDuplicateIndex(fields, notes); err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			results = append(results, doctorResult{Topic: "index", Err: errors.New(line)})
+		}
+	}
+
+	if len(results) == 0 {
+		results = append(results, doctorResult{Topic: "field", Message: fmt.Sprintf("%d notes, index fields set and unique", len(notes))})
+	}
+	return results
+}
+
+// producesCard reports whether some template's front references a non-empty
+// field of the note.
+func producesCard(cardFields [][]int, fields []string) bool {
+	for _, ords := range cardFields {
+		for _, ord := range ords {
+			if strings.TrimSpace(fields[ord]) != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readFileString reads a whole file as a string.
+func readFileString(filename string) (string, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
@@ -0,0 +1,89 @@
+package flashcard
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCheckProjectHealthy: the shared test project passes every check.
+func TestCheckProjectHealthy(t *testing.T) {
+	project, err := ReadProject(writeTestProject(t, testProjectYAML, nil))
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	for _, result := range checkProject(project) {
+		if result.Err != nil {
+			t.Errorf("ERR %s: %v", result.Topic, result.Err)
+		}
+	}
+}
+
+// TestCheckProjectProblems: each kind of problem is reported under its topic,
+// and checking continues past the first one.
+func TestCheckProjectProblems(t *testing.T) {
+	tests := []struct {
+		name  string
+		yaml  string
+		files map[string]string
+		want  []string // fragments of "topic: message" lines
+	}{
+		{
+			name: "identifiers",
+			yaml: strings.Replace(testProjectYAML, "identifier: 1700000000002", "identifier: 1700000000001", 1),
+			want: []string{"ident: deck and model share identifier 1700000000001"},
+		},
+		{
+			name: "zero identifier",
+			yaml: strings.Replace(testProjectYAML, "identifier: 1700000000001", "identifier: 0", 1),
+			want: []string{`ident: deck "Test Deck" has no identifier`},
+		},
+		{
+			name:  "unknown template field",
+			yaml:  testProjectYAML,
+			files: map[string]string{"back.html": "{{FrontSide}}<hr id=answer>{{Back}} {{#Notes}}{{Notes}}{{/Notes}}"},
+			want:  []string{"tmpl: Card 1 (back.html): {{Notes}} not a model field"},
+		},
+		{
+			name:  "missing column and empty index, reported together",
+			yaml:  testProjectYAML + "  - filename: more.tsv\n",
+			files: map[string]string{"words.tsv": "Front\n", "more.tsv": "Front\tBack\n\tcat\n"},
+			want: []string{
+				"words.tsv:1: header has no column for field(s): Back",
+				"more.tsv:2: index field Front is empty",
+			},
+		},
+		{
+			name:  "note without cards",
+			yaml:  testProjectYAML,
+			files: map[string]string{"words.tsv": "Front\tBack\nkot\t\n", "front.html": "{{Back}}"},
+			want:  []string{"words.tsv:2: every field a card's front uses is empty"},
+		},
+		{
+			name:  "duplicate index",
+			yaml:  testProjectYAML,
+			files: map[string]string{"words.tsv": "Front\tBack\nkot\tcat\nkot\tdog\n"},
+			want:  []string{`words.tsv:3: duplicate index Front = "kot"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project, err := ReadProject(writeTestProject(t, tt.yaml, tt.files))
+			if err != nil {
+				t.Fatalf("ReadProject: %v", err)
+			}
+			var got []string
+			for _, result := range checkProject(project) {
+				if result.Err != nil {
+					got = append(got, result.Topic+": "+result.Err.Error())
+				}
+			}
+			report := strings.Join(got, "\n")
+			for _, want := range tt.want {
+				if !strings.Contains(report, want) {
+					t.Errorf("report:\n%s\nwant it to contain %q", report, want)
+				}
+			}
+		})
+	}
+}
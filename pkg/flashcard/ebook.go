@@ -0,0 +1,98 @@
+package flashcard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dpurge/cli-tools/pkg/ebook"
+	"github.com/dpurge/cli-tools/pkg/types"
+)
+
+// ebookFields are the model field names an ebook data source can fill, in
+// the order they appear on a vocabulary line. Models items have no grammar
+// tag, so their Grammar field is always empty.
+var ebookFields = []string{"Phrase", "Grammar", "Transcription", "Translation"}
+
+// readEbookSource reads one `source:` data entry: every text file of the
+// ebook project it names, in WalkTexts order, yields one note per
+// {start-vocabulary} and {start-models} item (ebook.WalkVocabulary), those
+// in {start-text} bodies included. Headings and notes inside the blocks are
+// skipped.
+//
+// Each note gets the data entry's Tags, a tag naming the text file it came
+// from (its basename without extension), and lang::<lang> / script::<script>
+// tags from the block's marker attributes, falling back to the ebook
+// project's language and script.
+func readEbookSource(data types.FlashcardData, fields []types.FlashcardField) ([]Note, error) {
+	ords := make([]int, len(fields))
+	for i, field := range fields {
+		ords[i] = slices.Index(ebookFields, field.Name)
+		if ords[i] < 0 {
+			return nil, fmt.Errorf("%s: model field %q cannot be filled from an ebook (expected one of: %s)", data.Source, field.Name, strings.Join(ebookFields, ", "))
+		}
+	}
+
+	project, err := ebook.ReadProject(data.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []Note
+	for _, item := range ebook.WalkTexts(project.Text) {
+		source, err := os.ReadFile(item.File)
+		if err != nil {
+			return nil, err
+		}
+
+		tags := append(append([]string(nil), data.Tags...), textTag(item.File))
+		add := func(values []string, lang, script string) {
+			note := Note{
+				Fields: make([]string, len(fields)),
+				Tags:   append(append([]string(nil), tags...), languageTags(lang, script, project)...),
+				File:   item.File,
+			}
+			for i, ord := range ords {
+				note.Fields[i] = values[ord]
+			}
+			notes = append(notes, note)
+		}
+
+		err = ebook.WalkVocabulary(source, true, func(record ebook.VocabularyRecord, lang, script string) {
+			add([]string{record.Phrase, record.Grammar, record.Transcription, record.Translation}, lang, script)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", item.File, err)
+		}
+	}
+
+	return notes, nil
+}
+
+// textTag derives an Anki tag from a text file name: its basename without
+// extension, with spaces (which separate Anki tags) replaced by underscores.
+func textTag(filename string) string {
+	base := filepath.Base(filename)
+	return strings.ReplaceAll(strings.TrimSuffix(base, filepath.Ext(base)), " ", "_")
+}
+
+// languageTags returns the lang:: and script:: tags for a block, using the
+// ebook project's language and script where the block's marker sets none.
+func languageTags(lang, script string, project *ebook.EBookProject) []string {
+	if lang == "" {
+		lang = project.Language
+	}
+	if script == "" {
+		script = project.Script
+	}
+	var tags []string
+	if lang != "" {
+		tags = append(tags, "lang::"+lang)
+	}
+	if script != "" {
+		tags = append(tags, "script::"+script)
+	}
+	return tags
+}
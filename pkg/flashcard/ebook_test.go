@@ -0,0 +1,72 @@
+package flashcard
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testEbookFiles is a one-section, one-chapter ebook project whose chapter
+// holds a vocabulary block (with a heading to skip), a text block with a
+// vocabulary block in its body, and a models block.
+var testEbookFiles = map[string]string{
+	"book/ebook.yml":  "filename: book.epub\ntitle: Book\nlanguage: tur\nscript: latn\ntext:\n  - [section.md, lesson 01.md]\n",
+	"book/section.md": "# Section\n",
+	"book/lesson 01.md": "# Lesson 1\n\n" +
+		"{start-vocabulary}\n## Greetings\nmerhaba {intj} [merˈhaba] = hello\n{end-vocabulary}\n\n" +
+		"{start-text as=translation}\nReading:\n\n{start-vocabulary}\nkitap {n} = book\n{end-vocabulary}\n{end-text}\n\n" +
+		"{start-models lang=ara script=arab}\nمرحبا = hello\n{end-models}\n",
+}
+
+// TestReadNotesEbookSource: a source entry yields one note per vocabulary
+// and models item, nested ones included, with fields matched by name and
+// chapter/language tags.
+func TestReadNotesEbookSource(t *testing.T) {
+	projectYAML := strings.Replace(testProjectYAML, "  - filename: words.tsv\n", "  - source: book/ebook.yml\n", 1)
+	projectYAML = strings.Replace(projectYAML, "name: Front", "name: Phrase", 1)
+	projectYAML = strings.Replace(projectYAML, "name: Back", "name: Translation", 1)
+	projectYAML = strings.Replace(projectYAML, "    - name: Translation\n      template: field.html\n      format: text\n",
+		"    - name: Translation\n      template: field.html\n      format: text\n    - name: Grammar\n      template: field.html\n      format: text\n", 1)
+
+	project, err := ReadProject(writeTestProject(t, projectYAML, testEbookFiles))
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	notes, err := ReadNotes(project)
+	if err != nil {
+		t.Fatalf("ReadNotes: %v", err)
+	}
+
+	want := []struct {
+		fields []string
+		tags   []string
+	}{
+		{[]string{"merhaba", "hello", "intj"}, []string{"lesson1", "lesson_01", "lang::tur", "script::latn"}},
+		{[]string{"kitap", "book", "n"}, []string{"lesson1", "lesson_01", "lang::tur", "script::latn"}},
+		{[]string{"مرحبا", "hello", ""}, []string{"lesson1", "lesson_01", "lang::ara", "script::arab"}},
+	}
+	if len(notes) != len(want) {
+		t.Fatalf("got %d notes, want %d: %+v", len(notes), len(want), notes)
+	}
+	for i, note := range notes {
+		if !reflect.DeepEqual(note.Fields, want[i].fields) {
+			t.Errorf("note %d fields = %q, want %q", i, note.Fields, want[i].fields)
+		}
+		if !reflect.DeepEqual(note.Tags, want[i].tags) {
+			t.Errorf("note %d tags = %q, want %q", i, note.Tags, want[i].tags)
+		}
+	}
+}
+
+// TestReadNotesEbookSourceUnknownField: a model field an ebook cannot fill is
+// rejected rather than left empty on every note.
+func TestReadNotesEbookSourceUnknownField(t *testing.T) {
+	projectYAML := strings.Replace(testProjectYAML, "  - filename: words.tsv\n", "  - source: book/ebook.yml\n", 1)
+	project, err := ReadProject(writeTestProject(t, projectYAML, testEbookFiles))
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	if _, err := ReadNotes(project); err == nil || !strings.Contains(err.Error(), `"Front"`) {
+		t.Errorf("ReadNotes error = %v, want one naming field \"Front\"", err)
+	}
+}
@@ -0,0 +1,105 @@
+package flashcard
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dpurge/cli-tools/pkg/tool"
+	"github.com/dpurge/cli-tools/pkg/types"
+)
+
+// Exporter writes one output artifact (Anki package, CSV, ...) from a loaded
+// project and its notes. build-cmd.go reads the project and notes once and
+// dispatches them to every requested format.
+type Exporter interface {
+	Export(project *types.FlashcardProject, notes []Note) (outfile string, err error)
+}
+
+// exporterFor maps a --format value to its Exporter, or reports it as
+// unknown.
+func exporterFor(format string) (Exporter, error) {
+	switch format {
+	case "apkg":
+		return ankiExporter{}, nil
+	case "csv":
+		return tableExporter{ext: ".csv", comma: ','}, nil
+	case "tsv":
+		return tableExporter{ext: ".tsv", comma: '\t'}, nil
+	case "quizlet":
+		return quizletExporter{}, nil
+	case "pdf":
+		return pdfExporter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q (want apkg|csv|tsv|quizlet|pdf)", format)
+	}
+}
+
+// ankiExporter writes the Anki package at the project's filename.
+type ankiExporter struct{}
+
+func (ankiExporter) Export(project *types.FlashcardProject, notes []Note) (string, error) {
+	return buildAnkiPackage(project, notes)
+}
+
+// tableExporter writes the raw field values as a table with a header row of
+// field names plus a Tags column (space-separated tags): the same layout as
+// a data file, written with tool.WriteTable like ebook-cli's vocabulary
+// export.
+type tableExporter struct {
+	ext   string
+	comma rune
+}
+
+func (e tableExporter) Export(project *types.FlashcardProject, notes []Note) (string, error) {
+	outfile := tool.BaseOutputName(project.Filename) + e.ext
+
+	header := make([]string, 0, len(project.Model.Fields)+1)
+	for _, field := range project.Model.Fields {
+		header = append(header, field.Name)
+	}
+	header = append(header, "Tags")
+
+	rows := [][]string{header}
+	for _, note := range notes {
+		rows = append(rows, append(append([]string(nil), note.Fields...), strings.Join(note.Tags, " ")))
+	}
+	if err := tool.WriteTable(outfile, rows, e.comma); err != nil {
+		return "", err
+	}
+	return outfile, nil
+}
+
+// quizletExporter writes a term/definition text file in the format Quizlet
+// and Mochi import: one card per line, term and definition separated by a
+// tab. The term is the note's sort field (its first index field); the
+// definition joins the note's other non-empty fields with " / ".
+type quizletExporter struct{}
+
+func (quizletExporter) Export(project *types.FlashcardProject, notes []Note) (string, error) {
+	outfile := tool.BaseOutputName(project.Filename) + ".txt"
+	term := sortFieldIndex(project.Model.Fields)
+
+	f, err := os.Create(outfile)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, note := range notes {
+		var definition []string
+		for i, value := range note.Fields {
+			if i != term && strings.TrimSpace(value) != "" {
+				definition = append(definition, tool.FlattenValue(value))
+			}
+		}
+		w.WriteString(tool.FlattenValue(note.Fields[term]) + "\t" + strings.Join(definition, " / ") + "\n")
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+
+	return outfile, f.Close()
+}
@@ -0,0 +1,70 @@
+package flashcard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestExporterFor: every documented format resolves; anything else is an
+// error before any output is written.
+func TestExporterFor(t *testing.T) {
+	for _, format := range []string{"apkg", "csv", "tsv", "quizlet", "pdf"} {
+		if _, err := exporterFor(format); err != nil {
+			t.Errorf("exporterFor(%q): %v", format, err)
+		}
+	}
+	if _, err := exporterFor("xlsx"); err == nil {
+		t.Error("exporterFor(\"xlsx\"): expected an error, got nil")
+	}
+}
+
+// TestTextExporters: each plain-text export is written next to the project's
+// filename with the expected content.
+func TestTextExporters(t *testing.T) {
+	words := "Front\tBack\nkot\tcat\npies, duży\tdog\n"
+	tests := []struct {
+		format string
+		file   string
+		want   string
+	}{
+		{"csv", "deck.csv", "Front,Back,Tags\nkot,cat,lesson1\n\"pies, duży\",\"big\n\tdog\",lesson1\n"},
+		{"tsv", "deck.tsv", "Front\tBack\tTags\nkot\tcat\tlesson1\npies, duży\tbig dog\tlesson1\n"},
+		{"quizlet", "deck.txt", "kot\tcat\npies, duży\tbig dog\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			project, err := ReadProject(writeTestProject(t, testProjectYAML, map[string]string{"words.tsv": words}))
+			if err != nil {
+				t.Fatalf("ReadProject: %v", err)
+			}
+			notes, err := ReadNotes(project)
+			if err != nil {
+				t.Fatalf("ReadNotes: %v", err)
+			}
+			// A multi-line value, as a CSV data file or an ebook source can
+			// produce, to exercise quoting and flattening.
+			notes[1].Fields[1] = "big\n\tdog"
+
+			exporter, err := exporterFor(tt.format)
+			if err != nil {
+				t.Fatalf("exporterFor: %v", err)
+			}
+			outfile, err := exporter.Export(project, notes)
+			if err != nil {
+				t.Fatalf("Export: %v", err)
+			}
+			if filepath.Base(outfile) != tt.file {
+				t.Errorf("outfile = %q, want %s", outfile, tt.file)
+			}
+			got, err := os.ReadFile(outfile)
+			if err != nil {
+				t.Fatalf("read output: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
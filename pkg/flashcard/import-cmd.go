@@ -0,0 +1,41 @@
+package flashcard
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var _apkg string
+var _output string
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import an Anki package into a flashcard project",
+	Long: "Convert an Anki package (.apkg) into a flashcard project: flashcard.yml with " +
+		"the deck and note type identifiers, the card templates, CSS and LaTeX as files, " +
+		"TSV data files and the media the notes use. A package with several note types " +
+		"gives one project per note type, each in its own subdirectory.",
+	Run: func(cmd *cobra.Command, args []string) {
+		projects, warnings, err := importAnkiPackage(_apkg, _output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+		}
+		for _, project := range projects {
+			fmt.Println(project)
+		}
+	},
+}
+
+func init() {
+	mainCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVar(&_apkg, "apkg", "", "Anki package to import")
+	importCmd.Flags().StringVarP(&_output, "output", "o", ".", "directory to write the project into")
+	importCmd.MarkFlagRequired("apkg")
+}
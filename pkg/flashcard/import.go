@@ -0,0 +1,461 @@
+package flashcard
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dpurge/cli-tools/pkg/types"
+)
+
+// importedNote is one note read from a package's collection.
+type importedNote struct {
+	ModelID int64
+	GUID    string
+	Tags    []string
+	Fields  []string
+}
+
+// importAnkiPackage converts an Anki package into flashcard projects under
+// dir, one per note type that has notes: into dir itself when there is only
+// one, otherwise into a subdirectory named after each note type. It returns
+// the project files written, and warnings about what the projects cannot
+// express as they stand.
+//
+// Each project gets its flashcard.yml, the model's CSS, LaTeX preamble and
+// postamble, a qfmt/afmt file per template, and one TSV data file per
+// distinct tag set (the entry's tags: carry the notes' tags). Field values
+// keep Anki's HTML, as "html" fields. Media the notes reference is written
+// to media/ and the references rewritten to match.
+func importAnkiPackage(apkg, dir string) (projects, warnings []string, err error) {
+	archive, err := zip.OpenReader(apkg)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer archive.Close()
+
+	entries := map[string]*zip.File{}
+	for _, f := range archive.File {
+		entries[f.Name] = f
+	}
+
+	// Anki 2.1.50+ exports a zstd-compressed collection.anki21b next to a
+	// stub collection.anki2; only the legacy layouts are readable here.
+	collection := entries["collection.anki21"]
+	if collection == nil {
+		if entries["collection.anki21b"] != nil {
+			return nil, nil, fmt.Errorf("%s: collection.anki21b (Anki 2.1.50+) is not supported: export again with \"Support older Anki versions\" checked", apkg)
+		}
+		collection = entries["collection.anki2"]
+	}
+	if collection == nil {
+		return nil, nil, fmt.Errorf("%s: not an Anki package (no collection.anki2)", apkg)
+	}
+
+	tmpdir, err := os.MkdirTemp("", "flashcard-")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(tmpdir)
+
+	dbfile := filepath.Join(tmpdir, "collection.anki2")
+	if err := extractArchiveFile(collection, dbfile); err != nil {
+		return nil, nil, err
+	}
+
+	media := map[string]*zip.File{}
+	if f := entries["media"]; f != nil {
+		mediaMap := map[string]string{}
+		if err := readArchiveJSON(f, &mediaMap); err != nil {
+			return nil, nil, fmt.Errorf("%s: media map: %w", apkg, err)
+		}
+		for entry, name := range mediaMap {
+			// Only plain file names: a path in the map must not write
+			// outside media/.
+			if f := entries[entry]; f != nil && name == filepath.Base(name) && !strings.HasPrefix(name, ".") {
+				media[name] = f
+			}
+		}
+	}
+
+	db, err := sql.Open("sqlite", dbfile)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer db.Close()
+
+	var modelsJSON, decksJSON string
+	if err := db.QueryRow("SELECT models, decks FROM col").Scan(&modelsJSON, &decksJSON); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", apkg, err)
+	}
+	models := map[string]ankiModel{}
+	if err := json.Unmarshal([]byte(modelsJSON), &models); err != nil {
+		return nil, nil, fmt.Errorf("%s: note types: %w", apkg, err)
+	}
+	decks := map[string]ankiDeck{}
+	if err := json.Unmarshal([]byte(decksJSON), &decks); err != nil {
+		return nil, nil, fmt.Errorf("%s: decks: %w", apkg, err)
+	}
+
+	notes, err := readImportedNotes(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", apkg, err)
+	}
+	modelDecks, err := readModelDecks(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", apkg, err)
+	}
+
+	var used []ankiModel
+	for _, model := range models {
+		if slices.ContainsFunc(notes, func(note importedNote) bool { return note.ModelID == model.ID }) {
+			used = append(used, model)
+		}
+	}
+	if len(used) == 0 {
+		return nil, nil, fmt.Errorf("%s: the package has no notes", apkg)
+	}
+	slices.SortFunc(used, func(a, b ankiModel) int { return strings.Compare(a.Name, b.Name) })
+
+	for _, model := range used {
+		projectDir := dir
+		if len(used) > 1 {
+			projectDir = filepath.Join(dir, importSlug(model.Name, "model"))
+		}
+
+		deck := decks[strconv.FormatInt(model.Did, 10)]
+		if id, ok := modelDecks[model.ID]; ok {
+			deck = decks[strconv.FormatInt(id, 10)]
+		}
+
+		var modelNotes []importedNote
+		for _, note := range notes {
+			if note.ModelID == model.ID {
+				modelNotes = append(modelNotes, note)
+			}
+		}
+
+		project, modelWarnings, err := writeImportedProject(projectDir, model, deck, modelNotes, media)
+		if err != nil {
+			return nil, nil, err
+		}
+		projects = append(projects, project)
+		for _, w := range modelWarnings {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", model.Name, w))
+		}
+	}
+	return projects, warnings, nil
+}
+
+// readImportedNotes reads every note, oldest first.
+func readImportedNotes(db *sql.DB) ([]importedNote, error) {
+	rows, err := db.Query("SELECT mid, guid, tags, flds FROM notes ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []importedNote
+	for rows.Next() {
+		var note importedNote
+		var tags, flds string
+		if err := rows.Scan(&note.ModelID, &note.GUID, &tags, &flds); err != nil {
+			return nil, err
+		}
+		note.Tags = strings.Fields(tags)
+		note.Fields = strings.Split(flds, "\x1f")
+		notes = append(notes, note)
+	}
+	return notes, rows.Err()
+}
+
+// readModelDecks maps each note type to the deck holding most of its cards
+// (the lowest deck id on a tie).
+func readModelDecks(db *sql.DB) (map[int64]int64, error) {
+	rows, err := db.Query("SELECT n.mid, c.did, COUNT(*) FROM cards c JOIN notes n ON n.id = c.nid GROUP BY n.mid, c.did ORDER BY n.mid, COUNT(*) DESC, c.did")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	decks := map[int64]int64{}
+	for rows.Next() {
+		var mid, did, count int64
+		if err := rows.Scan(&mid, &did, &count); err != nil {
+			return nil, err
+		}
+		if _, ok := decks[mid]; !ok {
+			decks[mid] = did
+		}
+	}
+	return decks, rows.Err()
+}
+
+// writeImportedProject writes one note type's project into dir and returns
+// the path of its flashcard.yml. Nothing is written if any of the project's
+// files exists already.
+func writeImportedProject(dir string, model ankiModel, deck ankiDeck, notes []importedNote, media map[string]*zip.File) (string, []string, error) {
+	projectFile := filepath.Join(dir, "flashcard.yml")
+
+	files := map[string]string{
+		"style.css":         model.CSS,
+		"latex/prefix.tex":  model.LatexPre,
+		"latex/postfix.tex": model.LatexPost,
+		"field.html":        "",
+	}
+	// Anki's defaults are left blank, which readLatex reads back as the
+	// default, so the project only carries a preamble someone changed.
+	if model.LatexPre == ankiDefaultLatexPre {
+		files["latex/prefix.tex"] = ""
+	}
+	if model.LatexPost == ankiDefaultLatexPost {
+		files["latex/postfix.tex"] = ""
+	}
+
+	project := types.FlashcardProject{
+		Filename: importSlug(deck.Name, "deck") + ".apkg",
+		Deck:     types.FlashcardDeck{Identifier: deck.ID, Name: deck.Name},
+		Model: types.FlashcardModel{
+			Identifier: model.ID,
+			Name:       model.Name,
+			Kind:       "normal",
+			Style: types.FlashcardStyle{
+				CSS:   "style.css",
+				Latex: types.FlashcardLatex{Prefix: "latex/prefix.tex", Postfix: "latex/postfix.tex"},
+			},
+		},
+	}
+	if model.Type == ankiModelCloze {
+		project.Model.Kind = "cloze"
+	}
+
+	// Card type and field ordinals follow their position in the project file.
+	slices.SortStableFunc(model.Tmpls, func(a, b ankiTemplate) int { return a.Ord - b.Ord })
+	slices.SortStableFunc(model.Flds, func(a, b ankiField) int { return a.Ord - b.Ord })
+
+	templateNames := map[string]bool{}
+	for _, tmpl := range model.Tmpls {
+		name := uniqueSlug(importSlug(tmpl.Name, "card"), templateNames)
+		qfmt, afmt := "templates/"+name+"-front.html", "templates/"+name+"-back.html"
+		files[qfmt], files[afmt] = tmpl.QFmt, tmpl.AFmt
+		project.Model.Templates = append(project.Model.Templates, types.FlashcardTemplate{Name: tmpl.Name, QFmt: qfmt, AFmt: afmt})
+	}
+
+	header := make([]string, len(model.Flds))
+	for i, field := range model.Flds {
+		header[i] = field.Name
+		imported := types.FlashcardField{
+			Name:     field.Name,
+			Template: "field.html",
+			Format:   "html",
+			Index:    i == model.Sortf,
+			RTL:      field.RTL,
+		}
+		if field.Font != ankiDefaultFont || field.Size != ankiDefaultFontSize {
+			imported.Font = types.FlashcardFont{Name: field.Font, Size: field.Size}
+		}
+		project.Model.Fields = append(project.Model.Fields, imported)
+	}
+
+	// One data file per tag set, in order of first appearance. Each row ends
+	// with the note's GUID (guidColumn), so the rebuilt deck updates the
+	// notes it came from instead of adding copies.
+	var warnings []string
+	referenced := map[string]bool{}
+	tables := map[string]*bytes.Buffer{}
+	dataNames := map[string]bool{}
+	sortValues := map[string]bool{}
+	duplicates, empty := 0, 0
+	for _, note := range notes {
+		values := make([]string, len(header))
+		for i := range values {
+			if i < len(note.Fields) {
+				values[i] = importFieldValue(importMediaRefs(note.Fields[i], media, referenced))
+			}
+		}
+		// A row with only blank fields reads as a blank line, which data
+		// files skip: such a note would vanish on the first build.
+		if strings.TrimSpace(strings.Join(values, "")) == "" {
+			empty++
+			continue
+		}
+		if sortValues[values[model.Sortf]] {
+			duplicates++
+		}
+		sortValues[values[model.Sortf]] = true
+
+		key := strings.Join(note.Tags, " ")
+		table, ok := tables[key]
+		if !ok {
+			name := "data/" + uniqueSlug(importSlug(strings.Join(note.Tags, "_"), "notes"), dataNames) + ".tsv"
+			table = &bytes.Buffer{}
+			table.WriteString(strings.Join(header, "\t") + "\t" + guidColumn + "\n")
+			tables[key] = table
+			project.Data = append(project.Data, types.FlashcardData{Filename: name, Tags: note.Tags})
+		}
+		table.WriteString(strings.Join(values, "\t") + "\t" + importFieldValue(note.GUID) + "\n")
+	}
+	for _, data := range project.Data {
+		files[data.Filename] = tables[strings.Join(data.Tags, " ")].String()
+	}
+	if empty > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d notes have only empty fields and were not imported", empty))
+	}
+	if duplicates > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d notes repeat the sort field %s; mark more fields index: true before building", duplicates, header[model.Sortf]))
+	}
+
+	paths := []string{projectFile}
+	for name := range files {
+		paths = append(paths, filepath.Join(dir, filepath.FromSlash(name)))
+	}
+	for name := range referenced {
+		paths = append(paths, filepath.Join(dir, "media", name))
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return "", nil, fmt.Errorf("%s already exists", path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", nil, err
+		}
+	}
+
+	for name, content := range files {
+		if err := writeImportedFile(filepath.Join(dir, filepath.FromSlash(name)), strings.NewReader(content)); err != nil {
+			return "", nil, err
+		}
+	}
+	for name := range referenced {
+		if err := extractArchiveFile(media[name], filepath.Join(dir, "media", name)); err != nil {
+			return "", nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(project); err != nil {
+		return "", nil, err
+	}
+	if err := writeImportedFile(projectFile, &buf); err != nil {
+		return "", nil, err
+	}
+	return projectFile, warnings, nil
+}
+
+// importMediaRefs points the src and [sound:] references to packaged media
+// in an imported value at media/, noting each file referenced.
+func importMediaRefs(value string, media map[string]*zip.File, referenced map[string]bool) string {
+	value = ankiSrcRe.ReplaceAllStringFunc(value, func(attr string) string {
+		parts := ankiSrcRe.FindStringSubmatch(attr)
+		quote := parts[2][:1]
+		name := html.UnescapeString(parts[2][1 : len(parts[2])-1])
+		if media[name] == nil {
+			return attr
+		}
+		referenced[name] = true
+		return parts[1] + quote + html.EscapeString("media/"+name) + quote
+	})
+	return ankiSoundRe.ReplaceAllStringFunc(value, func(sound string) string {
+		name := html.UnescapeString(ankiSoundRe.FindStringSubmatch(sound)[1])
+		if media[name] == nil {
+			return sound
+		}
+		referenced[name] = true
+		return "[sound:" + html.EscapeString("media/"+name) + "]"
+	})
+}
+
+// importSlug turns a deck, note type, template or tag name into a file name:
+// lowercased letters and digits, with runs of anything else as a single "-".
+// A name with nothing usable in it gives fallback.
+func importSlug(name, fallback string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	if b.Len() == 0 {
+		return fallback
+	}
+	return b.String()
+}
+
+// uniqueSlug returns slug, or slug-2, slug-3, … when taken already, and marks
+// the result taken.
+func uniqueSlug(slug string, taken map[string]bool) string {
+	name := slug
+	for i := 2; taken[name]; i++ {
+		name = slug + "-" + strconv.Itoa(i)
+	}
+	taken[name] = true
+	return name
+}
+
+// readArchiveJSON decodes a JSON archive entry into v.
+func readArchiveJSON(f *zip.File, v any) error {
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return json.NewDecoder(r).Decode(v)
+}
+
+// extractArchiveFile copies an archive entry to path.
+func extractArchiveFile(f *zip.File, path string) error {
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return writeImportedFile(path, r)
+}
+
+// writeImportedFile writes r to path, creating its directory.
+func writeImportedFile(path string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, r); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// importFieldLineBreaks turns the characters a data file row cannot hold
+// into spaces.
+var importFieldLineBreaks = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+
+// importFieldValue puts an Anki field value on one data file row: each line
+// break and tab becomes one space, and everything else — runs of spaces,
+// indentation inside <pre> — is kept as it was.
+func importFieldValue(value string) string {
+	return importFieldLineBreaks.Replace(value)
+}
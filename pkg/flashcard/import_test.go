@@ -0,0 +1,205 @@
+package flashcard
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// noteRows returns each note's fields and tags, oldest first.
+func noteRows(t *testing.T, apkg string) []string {
+	t.Helper()
+	db, _ := openTestPackage(t, apkg)
+	rows, err := db.Query("SELECT flds, tags FROM notes ORDER BY id")
+	if err != nil {
+		t.Fatalf("select notes: %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var flds, tags string
+		if err := rows.Scan(&flds, &tags); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		got = append(got, strings.ReplaceAll(flds, "\x1f", " | ")+" #"+strings.TrimSpace(tags))
+	}
+	return got
+}
+
+// TestImportAnkiPackage: a built package imports into a project that builds
+// back into the same notes, media and tags.
+func TestImportAnkiPackage(t *testing.T) {
+	projectYAML := strings.Replace(testProjectYAML, "      format: text\n      index: true", "      format: markdown\n      index: true", 1)
+	projectYAML = strings.Replace(projectYAML, "      format: text\ndata:", "      format: text\n      rtl: true\ndata:", 1) +
+		"  - filename: more.tsv\n    tags: [lesson2, verbs]\n"
+	apkg := buildTestPackage(t, writeTestProject(t, projectYAML, map[string]string{
+		"img/kot.png": "png",
+		"words.tsv":   "Front\tBack\n![](img/kot.png) **kot**\tcat\npies\tdog\n",
+		"more.tsv":    "Front\tBack\nspać\tto sleep\n",
+	}))
+
+	dir := t.TempDir()
+	projects, warnings, err := importAnkiPackage(apkg, dir)
+	if err != nil {
+		t.Fatalf("importAnkiPackage: %v", err)
+	}
+	if len(projects) != 1 || projects[0] != filepath.Join(dir, "flashcard.yml") || len(warnings) != 0 {
+		t.Fatalf("projects = %v, warnings = %v; want %s/flashcard.yml", projects, warnings, dir)
+	}
+
+	project, err := ReadProject(projects[0])
+	if err != nil {
+		t.Fatalf("ReadProject(imported): %v", err)
+	}
+	if project.Deck.Identifier != 1700000000001 || project.Model.Identifier != 1700000000002 || project.Deck.Name != "Test Deck" {
+		t.Errorf("deck/model = %+v / %d, want the original identifiers", project.Deck, project.Model.Identifier)
+	}
+	fields := project.Model.Fields
+	if len(fields) != 2 || fields[0].Format != "html" || !fields[0].Index || !fields[1].RTL {
+		t.Errorf("fields = %+v, want html fields, Front the index, Back rtl", fields)
+	}
+	if len(project.Data) != 2 || strings.Join(project.Data[1].Tags, " ") != "lesson2 verbs" {
+		t.Errorf("data = %+v, want one entry per tag set", project.Data)
+	}
+	qfmt, err := os.ReadFile(project.Model.Templates[0].QFmt)
+	if err != nil || string(qfmt) != testProjectFiles["front.html"] {
+		t.Errorf("qfmt = %q, %v; want %q", qfmt, err, testProjectFiles["front.html"])
+	}
+
+	rebuilt := buildTestPackage(t, projects[0])
+	want, got := noteRows(t, apkg), noteRows(t, rebuilt)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("rebuilt notes:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	_, media := openTestPackage(t, rebuilt)
+	if len(media) != 1 {
+		t.Errorf("rebuilt media = %v, want the one image", media)
+	}
+
+	if _, _, err := importAnkiPackage(apkg, dir); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("second import: err = %v, want an already-exists error", err)
+	}
+}
+
+// TestImportAnkiPackageKeepsGUIDs: notes keep their original GUIDs, which
+// are not the ones the index fields would give, through import and
+// rebuild, so the rebuilt package updates the collection it came from.
+func TestImportAnkiPackageKeepsGUIDs(t *testing.T) {
+	apkg := buildTestPackage(t, writeTestProject(t, testProjectYAML, map[string]string{
+		"words.tsv": "Front\tBack\t#guid\nkot\tcat\tAb3$x!9q\npies\tdog\tz)Q7p&k1\n",
+	}))
+	guids := func(apkg string) []string {
+		db, _ := openTestPackage(t, apkg)
+		defer db.Close()
+		rows, err := db.Query("SELECT guid FROM notes ORDER BY id")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var got []string
+		for rows.Next() {
+			var guid string
+			if err := rows.Scan(&guid); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, guid)
+		}
+		return got
+	}
+	want := []string{"Ab3$x!9q", "z)Q7p&k1"}
+	if got := guids(apkg); !slices.Equal(got, want) {
+		t.Fatalf("original GUIDs = %q, want %q", got, want)
+	}
+
+	projects, _, err := importAnkiPackage(apkg, t.TempDir())
+	if err != nil {
+		t.Fatalf("importAnkiPackage: %v", err)
+	}
+	if got := guids(buildTestPackage(t, projects[0])); !slices.Equal(got, want) {
+		t.Errorf("rebuilt GUIDs = %q, want the original %q", got, want)
+	}
+}
+
+// TestImportAnkiPackageEmptyNotes: a note whose fields are all blank would
+// be a blank data file line, which reading skips, so it is left out with a
+// warning rather than lost silently on the first build.
+func TestImportAnkiPackageEmptyNotes(t *testing.T) {
+	projectYAML := strings.Replace(testProjectYAML, "filename: words.tsv", "filename: words.csv", 1)
+	apkg := buildTestPackage(t, writeTestProject(t, projectYAML, map[string]string{
+		"words.csv": "Front,Back\nkot,cat\n\" \",\npies,dog\n",
+	}))
+
+	projects, warnings, err := importAnkiPackage(apkg, t.TempDir())
+	if err != nil {
+		t.Fatalf("importAnkiPackage: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "1 notes have only empty fields") {
+		t.Errorf("warnings = %q, want one about the empty note", warnings)
+	}
+	project, err := ReadProject(projects[0])
+	if err != nil {
+		t.Fatalf("ReadProject(imported): %v", err)
+	}
+	notes, err := ReadNotes(project)
+	if err != nil {
+		t.Fatalf("ReadNotes(imported): %v", err)
+	}
+	if len(notes) != 2 || notes[0].Fields[0] != "kot" || notes[1].Fields[0] != "pies" {
+		t.Errorf("imported notes = %+v, want kot and pies", notes)
+	}
+}
+
+// TestImportAnkiPackageNewFormat: a package with only the Anki 2.1.50+
+// collection is rejected with a hint.
+func TestImportAnkiPackageNewFormat(t *testing.T) {
+	apkg := filepath.Join(t.TempDir(), "new.apkg")
+	f, err := os.Create(apkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := zip.NewWriter(f)
+	if _, err := archive.Create("collection.anki21b"); err != nil {
+		t.Fatal(err)
+	}
+	archive.Close()
+	f.Close()
+
+	if _, _, err := importAnkiPackage(apkg, t.TempDir()); err == nil || !strings.Contains(err.Error(), "older Anki versions") {
+		t.Errorf("err = %v, want the anki21b hint", err)
+	}
+}
+
+// TestImportSlug covers file names made from deck, template and tag names.
+func TestImportSlug(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Turkish Vocabulary", "turkish-vocabulary"},
+		{"Języki::Polski", "języki-polski"},
+		{"  Card 1 ", "card-1"},
+		{"::", "fallback"},
+	}
+	for _, tt := range tests {
+		if got := importSlug(tt.in, "fallback"); got != tt.want {
+			t.Errorf("importSlug(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestImportFieldValue: only line breaks and tabs are joined into spaces;
+// other whitespace is kept.
+func TestImportFieldValue(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"kot", "kot"},
+		{"a\r\nb\nc\rd", "a b c d"},
+		{"a\tb", "a b"},
+		{"<pre>if x:\n    y  = 1</pre>", "<pre>if x:     y  = 1</pre>"},
+		{"  two  spaces ", "  two  spaces "},
+	}
+	for _, tt := range tests {
+		if got := importFieldValue(tt.in); got != tt.want {
+			t.Errorf("importFieldValue(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package flashcard
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ankiSrcRe matches a src attribute (double- or single-quoted) in rendered
+// field HTML: <img src="…">, <audio src="…">, <source src="…"> and so on.
+var ankiSrcRe = regexp.MustCompile(`(\ssrc\s*=\s*)("[^"]*"|'[^']*')`)
+
+// ankiSoundRe matches Anki's [sound:file] audio reference.
+var ankiSoundRe = regexp.MustCompile(`\[sound:([^\]]+)\]`)
+
+// ankiMedia collects the local files referenced by note fields, for the
+// package's media map. Each file is stored once under a content-hashed name,
+// so the same file referenced from many notes (or under different relative
+// paths) is packaged once, and a changed file gets a new name that Anki will
+// not confuse with the old one.
+type ankiMedia struct {
+	dir   string
+	names map[string]string // source path -> packaged name
+	files []string          // source paths, in packaging order
+}
+
+func newAnkiMedia(dir string) *ankiMedia {
+	return &ankiMedia{dir: dir, names: map[string]string{}}
+}
+
+// collect rewrites every local src and [sound:] reference in a rendered
+// field to its packaged name, adding the referenced files to the media set.
+// Remote (http:, https:, data:, …) references are left alone. A reference to
+// a file that does not exist is an error.
+func (m *ankiMedia) collect(value string) (string, error) {
+	var err error
+	value = ankiSrcRe.ReplaceAllStringFunc(value, func(attr string) string {
+		parts := ankiSrcRe.FindStringSubmatch(attr)
+		quote := parts[2][:1]
+		ref := html.UnescapeString(parts[2][1 : len(parts[2])-1])
+		name, ok, e := m.add(ref, true)
+		if e != nil && err == nil {
+			err = e
+		}
+		if !ok {
+			return attr
+		}
+		return parts[1] + quote + html.EscapeString(name) + quote
+	})
+	value = ankiSoundRe.ReplaceAllStringFunc(value, func(sound string) string {
+		ref := html.UnescapeString(ankiSoundRe.FindStringSubmatch(sound)[1])
+		name, ok, e := m.add(ref, false)
+		if e != nil && err == nil {
+			err = e
+		}
+		if !ok {
+			return sound
+		}
+		return "[sound:" + html.EscapeString(name) + "]"
+	})
+	return value, err
+}
+
+// add resolves one reference against the project directory and returns its
+// packaged name; ok is false for references that are not local files. A src
+// reference is a URL, so its path is percent-decoded first.
+func (m *ankiMedia) add(ref string, isURL bool) (name string, ok bool, err error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "//") {
+		return "", false, nil
+	}
+	if isURL {
+		u, err := url.Parse(ref)
+		if err != nil {
+			return "", false, nil
+		}
+		// A one-letter scheme is a Windows drive ("C:\…"), not a URL.
+		if len(u.Scheme) > 1 {
+			return "", false, nil
+		}
+		if u.Scheme == "" {
+			ref = u.Path
+		}
+	}
+
+	path := filepath.FromSlash(ref)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.dir, path)
+	}
+	if name, ok := m.names[path]; ok {
+		return name, true, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, fmt.Errorf("media file %q does not exist: %s", ref, path)
+		}
+		return "", false, err
+	}
+	sum := sha256.Sum256(content)
+	name = hex.EncodeToString(sum[:16]) + strings.ToLower(filepath.Ext(path))
+
+	m.names[path] = name
+	m.files = append(m.files, path)
+	return name, true, nil
+}
+
+// entries returns the packaged media in order: the source path of each file
+// and its packaged name, skipping files whose content (and so name) was
+// already packaged under another path.
+func (m *ankiMedia) entries() (paths, names []string) {
+	seen := map[string]bool{}
+	for _, path := range m.files {
+		name := m.names[path]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		paths = append(paths, path)
+		names = append(names, name)
+	}
+	return paths, names
+}
@@ -0,0 +1,208 @@
+package flashcard
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dpurge/cli-tools/pkg/ebook"
+	"github.com/dpurge/cli-tools/pkg/tool"
+	"github.com/dpurge/cli-tools/pkg/tool/markdown"
+	"github.com/dpurge/cli-tools/pkg/types"
+)
+
+// cardsTemplate is the Typst preamble for printable cards: the #cards
+// function that lays card faces out on duplex sheets.
+//
+//go:embed templates/cards.typ
+var cardsTemplate string
+
+// printMarks are the accepted print.marks values; the first is the default.
+var printMarks = []string{"crop", "outline", "none"}
+
+// pdfExporter writes printable cut-out cards next to the project's filename:
+// a .typ source (kept for debugging a failed compile) and the .pdf Typst
+// compiles from it.
+type pdfExporter struct{}
+
+func (pdfExporter) Export(project *types.FlashcardProject, notes []Note) (string, error) {
+	document, err := assembleCardsDocument(project, notes)
+	if err != nil {
+		return "", err
+	}
+
+	base := tool.BaseOutputName(project.Filename)
+	pdfPath, typPath := base+".pdf", base+".typ"
+	if err := os.WriteFile(typPath, []byte(document), 0o644); err != nil {
+		return "", err
+	}
+
+	typstPath, err := ebook.LocateTypst()
+	if err != nil {
+		return "", err
+	}
+	output, err := ebook.RunTypst(typstPath, "compile", typPath, pdfPath, "--root", filepath.Dir(pdfPath))
+	if err != nil {
+		return "", fmt.Errorf("typst compile failed: %s", output)
+	}
+	if strings.TrimSpace(output) != "" {
+		fmt.Fprint(os.Stderr, output)
+	}
+
+	return pdfPath, nil
+}
+
+// assembleCardsDocument builds the .typ source: ebook-cli's book.typ, for
+// the block functions markdown fields' Typst calls (typstFace), then
+// cards.typ and one #cards(...) call carrying the project's print settings
+// and a front/back pair of faces per note. Lengths are validated before
+// they are emitted unquoted; print.paper is passed as a string, so Typst
+// checks the name.
+func assembleCardsDocument(project *types.FlashcardProject, notes []Note) (string, error) {
+	if project.Model.Kind == "cloze" {
+		return "", fmt.Errorf("pdf export does not support cloze models (%s)", project.Model.Name)
+	}
+	front, back, err := printFaces(project.Model.Fields, project.Print)
+	if err != nil {
+		return "", err
+	}
+
+	layout := project.Print
+	marks := layout.Marks
+	if marks == "" {
+		marks = printMarks[0]
+	}
+	if !slices.Contains(printMarks, marks) {
+		return "", fmt.Errorf("invalid print.marks: %s (valid marks: %v)", marks, printMarks)
+	}
+
+	var doc strings.Builder
+	doc.WriteString(ebook.BookTemplate())
+	doc.WriteString("\n")
+	doc.WriteString(cardsTemplate)
+	doc.WriteString("\n#cards(\n")
+	if layout.Paper != "" {
+		doc.WriteString("  paper: " + ebook.TypstStringLiteral(layout.Paper) + ",\n")
+	}
+	for _, l := range []struct{ key, arg, value string }{
+		{"print.card.width", "card-width", layout.Card.Width},
+		{"print.card.height", "card-height", layout.Card.Height},
+		{"print.margin", "margin", layout.Margin},
+	} {
+		if l.value == "" {
+			continue
+		}
+		v, err := ebook.TypstLength(l.key, l.value)
+		if err != nil {
+			return "", err
+		}
+		doc.WriteString("  " + l.arg + ": " + v + ",\n")
+	}
+	doc.WriteString("  marks: " + ebook.TypstStringLiteral(marks) + ",\n")
+
+	doc.WriteString("  cards: (\n")
+	for _, note := range notes {
+		frontFace, err := typstFace(project.Model.Fields, front, note)
+		if err != nil {
+			return "", err
+		}
+		backFace, err := typstFace(project.Model.Fields, back, note)
+		if err != nil {
+			return "", err
+		}
+		doc.WriteString("    (front: " + frontFace + ", back: " + backFace + "),\n")
+	}
+	doc.WriteString("  ),\n")
+	doc.WriteString(")\n")
+
+	return doc.String(), nil
+}
+
+// printFaces resolves print.front and print.back to field ordinals. Without
+// print.front the front is the sort field (as in the Quizlet export); without
+// print.back the back is every field not on the front.
+func printFaces(fields []types.FlashcardField, layout types.FlashcardPrint) (front, back []int, err error) {
+	resolve := func(side string, names []string) ([]int, error) {
+		var ords []int
+		for _, name := range names {
+			ord := slices.IndexFunc(fields, func(field types.FlashcardField) bool { return field.Name == name })
+			if ord < 0 {
+				return nil, fmt.Errorf("print.%s: %s is not a model field", side, name)
+			}
+			ords = append(ords, ord)
+		}
+		return ords, nil
+	}
+
+	if front, err = resolve("front", layout.Front); err != nil {
+		return nil, nil, err
+	}
+	if len(front) == 0 {
+		front = []int{sortFieldIndex(fields)}
+	}
+	if back, err = resolve("back", layout.Back); err != nil {
+		return nil, nil, err
+	}
+	if len(back) == 0 {
+		for i := range fields {
+			if !slices.Contains(front, i) {
+				back = append(back, i)
+			}
+		}
+	}
+	return front, back, nil
+}
+
+// typstFace renders the note's fields at ords as a Typst array of _field
+// calls, skipping empty values. A markdown value goes through
+// markdown.ToTypst, whose {start-…} blocks call book.typ's functions; a
+// text value is a string literal, so it prints verbatim, and an html value
+// is first reduced to plain text (htmlToText). RTL fields get dir: rtl, and
+// a field font's name and size (in points) carry over from the Anki
+// styling.
+func typstFace(fields []types.FlashcardField, ords []int, note Note) (string, error) {
+	var items []string
+	for _, ord := range ords {
+		field, value := fields[ord], note.Fields[ord]
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+
+		var args []string
+		if field.RTL {
+			args = append(args, "dir: rtl")
+		}
+		if field.Font.Size != 0 {
+			args = append(args, fmt.Sprintf("size: %dpt", field.Font.Size))
+		}
+		if field.Font.Name != "" {
+			args = append(args, "font: "+ebook.TypstStringLiteral(field.Font.Name))
+		}
+
+		switch field.Format {
+		case "markdown":
+			body, err := markdown.ToTypst([]byte(value))
+			if err != nil {
+				return "", fmt.Errorf("%s: field %s: %w", note.Position(), field.Name, err)
+			}
+			args = append(args, "["+strings.TrimSpace(string(body))+"]")
+		case "html":
+			args = append(args, ebook.TypstStringLiteral(htmlToText(value)))
+		default:
+			args = append(args, ebook.TypstStringLiteral(value))
+		}
+		items = append(items, "_field("+strings.Join(args, ", ")+")")
+	}
+
+	// A trailing comma keeps a one-element array from reading as a
+	// parenthesized expression.
+	var face strings.Builder
+	face.WriteString("(")
+	for _, item := range items {
+		face.WriteString(item + ", ")
+	}
+	return strings.TrimSuffix(face.String(), " ") + ")", nil
+}
@@ -0,0 +1,177 @@
+package flashcard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dpurge/cli-tools/pkg/ebook"
+)
+
+// TestAssembleCardsDocument: print settings become #cards arguments, and each
+// note becomes a front/back pair of _field calls, RTL fields carrying dir: rtl.
+func TestAssembleCardsDocument(t *testing.T) {
+	projectYAML := strings.Replace(testProjectYAML, "      format: text\ndata:", "      format: text\n      rtl: true\n      font:\n        name: Amiri\n        size: 18\ndata:", 1) + `print:
+  paper: a5
+  card:
+    width: 60mm
+    height: 40mm
+  marks: outline
+`
+	project, err := ReadProject(writeTestProject(t, projectYAML, map[string]string{"words.tsv": "Front\tBack\nkot \"x\"\tقطة\npies\t\n"}))
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	notes, err := ReadNotes(project)
+	if err != nil {
+		t.Fatalf("ReadNotes: %v", err)
+	}
+
+	document, err := assembleCardsDocument(project, notes)
+	if err != nil {
+		t.Fatalf("assembleCardsDocument: %v", err)
+	}
+	for _, want := range []string{
+		`  paper: "a5",`,
+		"  card-width: 60mm,",
+		"  card-height: 40mm,",
+		`  marks: "outline",`,
+		`    (front: (_field("kot \"x\""),), back: (_field(dir: rtl, size: 18pt, font: "Amiri", "قطة"),)),`,
+		`    (front: (_field("pies"),), back: ()),`,
+	} {
+		if !strings.Contains(document, want) {
+			t.Errorf("document missing %q", want)
+		}
+	}
+	call := document[strings.LastIndex(document, "\n#cards(\n"):]
+	if strings.Contains(call, "  margin:") {
+		t.Error("unset print.margin should leave the template default")
+	}
+}
+
+// TestAssembleCardsDocumentErrors: bad print settings and cloze models are
+// rejected before anything is compiled.
+func TestAssembleCardsDocumentErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		print string
+		want  string
+	}{
+		{"length", "print:\n  margin: 1 cm\n", "print.margin"},
+		{"marks", "print:\n  marks: dotted\n", "invalid print.marks"},
+		{"front", "print:\n  front: [Word]\n", "print.front: Word is not a model field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project, err := ReadProject(writeTestProject(t, testProjectYAML+tt.print, nil))
+			if err != nil {
+				t.Fatalf("ReadProject: %v", err)
+			}
+			_, err = assembleCardsDocument(project, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	project, err := ReadProject(writeTestProject(t, testClozeYAML, nil))
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	if _, err := assembleCardsDocument(project, nil); err == nil {
+		t.Error("cloze model: expected an error, got nil")
+	}
+}
+
+// TestPrintFaces: the front defaults to the sort field and the back to the
+// remaining fields; explicit lists are taken in their own order.
+func TestPrintFaces(t *testing.T) {
+	project, err := ReadProject(writeTestProject(t, testProjectYAML, nil))
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	fields := project.Model.Fields
+
+	front, back, err := printFaces(fields, project.Print)
+	if err != nil || len(front) != 1 || front[0] != 0 || len(back) != 1 || back[0] != 1 {
+		t.Errorf("default faces = %v, %v, %v; want [0], [1]", front, back, err)
+	}
+
+	project.Print.Front = []string{"Back"}
+	project.Print.Back = []string{"Front", "Back"}
+	front, back, err = printFaces(fields, project.Print)
+	if err != nil || len(front) != 1 || front[0] != 1 || len(back) != 2 || back[0] != 0 || back[1] != 1 {
+		t.Errorf("explicit faces = %v, %v, %v; want [1], [0 1]", front, back, err)
+	}
+}
+
+// TestPdfExport compiles the test project's cards; it needs the typst binary.
+func TestPdfExport(t *testing.T) {
+	if _, err := ebook.LocateTypst(); err != nil {
+		t.Skipf("typst binary not available: %v", err)
+	}
+	project, err := ReadProject(writeTestProject(t, testProjectYAML, nil))
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	notes, err := ReadNotes(project)
+	if err != nil {
+		t.Fatalf("ReadNotes: %v", err)
+	}
+
+	outfile, err := pdfExporter{}.Export(project, notes)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if filepath.Base(outfile) != "deck.pdf" {
+		t.Errorf("outfile = %q, want deck.pdf", outfile)
+	}
+	content, err := os.ReadFile(outfile)
+	if err != nil {
+		t.Fatalf("read pdf: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "%PDF") {
+		t.Error("output is not a PDF")
+	}
+}
+
+// TestPdfExportMarkdownBlocks: a markdown field holding a {start-…} block
+// calls book.typ's block functions, which the card document defines ahead of
+// cards.typ; compiling it needs the typst binary.
+func TestPdfExportMarkdownBlocks(t *testing.T) {
+	projectYAML := strings.Replace(testProjectYAML, "      format: text\ndata:", "      format: markdown\ndata:", 1)
+	projectYAML = strings.Replace(projectYAML, "words.tsv", "words.csv", 1)
+	value := "{start-vocabulary lang=arb script=arab}\nبيت = house\n{end-vocabulary}\n\n{start-dialog}\n@A:\n  Salam\n{end-dialog}"
+	project, err := ReadProject(writeTestProject(t, projectYAML, map[string]string{"words.csv": "Front,Back\nbayt,\"" + value + "\"\n"}))
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	notes, err := ReadNotes(project)
+	if err != nil {
+		t.Fatalf("ReadNotes: %v", err)
+	}
+
+	document, err := assembleCardsDocument(project, notes)
+	if err != nil {
+		t.Fatalf("assembleCardsDocument: %v", err)
+	}
+	call := strings.LastIndex(document, "\n#cards(\n")
+	for _, def := range []string{"#let vocabulary(", "#let dialog(", "#let _ctbadge("} {
+		if i := strings.Index(document, def); i < 0 || i > call {
+			t.Errorf("document does not define %q before the #cards call", def)
+		}
+	}
+	for _, want := range []string{"#vocabulary(", "#dialog("} {
+		if !strings.Contains(document[call:], want) {
+			t.Errorf("card faces missing %q", want)
+		}
+	}
+
+	if _, err := ebook.LocateTypst(); err != nil {
+		t.Skipf("typst binary not available: %v", err)
+	}
+	if _, err := (pdfExporter{}).Export(project, notes); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+}
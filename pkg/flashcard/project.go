@@ -31,6 +31,7 @@ func ReadProject(filename string) (*types.FlashcardProject, error) {
 	}
 
 	directory, _ := filepath.Split(filename)
+	project.Directory = filepath.Clean(directory)
 
 	if project.Filename, err = filepath.Abs(filepath.Join(directory, project.Filename)); err != nil {
 		return nil, err
@@ -80,7 +81,7 @@ func ReadProject(filename string) (*types.FlashcardProject, error) {
 		project.Model.Templates[i].AFmt = template.AFmt
 	}
 
-	formats := []string{"text", "markdown"}
+	formats := []string{"text", "markdown", "html"}
 	for i, field := range project.Model.Fields {
 		if field.Template, err = filepath.Abs(filepath.Join(directory, field.Template)); err != nil {
 			return nil, err
@@ -96,6 +97,20 @@ func ReadProject(filename string) (*types.FlashcardProject, error) {
 	}
 
 	for i, data := range project.Data {
+		if data.Source != "" {
+			if data.Filename != "" {
+				return nil, fmt.Errorf("data entry %d has both filename and source: %s, %s", i+1, data.Filename, data.Source)
+			}
+			if data.Source, err = filepath.Abs(filepath.Join(directory, data.Source)); err != nil {
+				return nil, err
+			}
+			if _, err := os.Stat(data.Source); errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("data source does not exist: %s", data.Source)
+			}
+			project.Data[i].Source = data.Source
+			continue
+		}
+
 		if data.Filename, err = filepath.Abs(filepath.Join(directory, data.Filename)); err != nil {
 			return nil, err
 		}
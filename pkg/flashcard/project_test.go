@@ -0,0 +1,99 @@
+package flashcard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testProjectYAML is a minimal two-field, one-template project used across the
+// package's tests; testProjectFiles holds the files it references.
+const testProjectYAML = `filename: deck.apkg
+deck:
+  identifier: 1700000000001
+  name: Test Deck
+model:
+  identifier: 1700000000002
+  name: Test Model
+  kind: normal
+  style:
+    css: style.css
+    latex:
+      prefix: prefix.tex
+      postfix: postfix.tex
+  templates:
+    - name: Card 1
+      qfmt: front.html
+      afmt: back.html
+  fields:
+    - name: Front
+      template: field.html
+      format: text
+      index: true
+    - name: Back
+      template: field.html
+      format: text
+data:
+  - filename: words.tsv
+    tags: [lesson1]
+`
+
+var testProjectFiles = map[string]string{
+	"style.css":   ".card { font-family: serif; }\n",
+	"prefix.tex":  "\\documentclass{article}\n\\begin{document}\n",
+	"postfix.tex": "\\end{document}\n",
+	"front.html":  "{{Front}}",
+	"back.html":   "{{FrontSide}}<hr id=answer>{{Back}}",
+	"field.html":  "",
+	"words.tsv":   "Front\tBack\nkot\tcat\npies\tdog\n",
+}
+
+// writeTestProject writes projectYAML plus files into a fresh temp directory
+// and returns the project file's path. files entries override (or, with an
+// empty name, extend) testProjectFiles.
+func writeTestProject(t *testing.T, projectYAML string, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	all := map[string]string{}
+	for name, content := range testProjectFiles {
+		all[name] = content
+	}
+	for name, content := range files {
+		all[name] = content
+	}
+	all["flashcard.yml"] = projectYAML
+	for name, content := range all {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	return filepath.Join(dir, "flashcard.yml")
+}
+
+// TestReadProjectResolvesPaths: every referenced file is resolved to an
+// absolute path next to the project file.
+func TestReadProjectResolvesPaths(t *testing.T) {
+	projectFile := writeTestProject(t, testProjectYAML, nil)
+	dir := filepath.Dir(projectFile)
+
+	project, err := ReadProject(projectFile)
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+
+	for name, got := range map[string]string{
+		"deck.apkg":  project.Filename,
+		"style.css":  project.Model.Style.CSS,
+		"front.html": project.Model.Templates[0].QFmt,
+		"back.html":  project.Model.Templates[0].AFmt,
+		"words.tsv":  project.Data[0].Filename,
+	} {
+		if want := filepath.Join(dir, name); got != want {
+			t.Errorf("resolved %s = %q, want %q", name, got, want)
+		}
+	}
+}
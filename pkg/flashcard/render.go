@@ -0,0 +1,241 @@
+package flashcard
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/dpurge/cli-tools/pkg/tool/markdown"
+	"github.com/dpurge/cli-tools/pkg/types"
+)
+
+// renderNoteFields converts a note's raw field values into the HTML stored
+// in the Anki note, field by field (see renderField).
+func renderNoteFields(fields []types.FlashcardField, mathjax bool, note Note) ([]string, error) {
+	rendered := make([]string, len(fields))
+	for i, field := range fields {
+		value, err := renderField(field, note.Fields[i], mathjax)
+		if err != nil {
+			return nil, fmt.Errorf("%s: field %s: %w", note.Position(), field.Name, err)
+		}
+		rendered[i] = value
+	}
+	return rendered, nil
+}
+
+// renderField converts one raw field value into HTML according to the
+// field's Format: "markdown" goes through markdown.ToHTML, so the project's
+// {start-…} blocks, tables and definition lists work on cards; "text" is
+// HTML-escaped; "html" is stored as written, without the wrapper below (as
+// in a deck imported from Anki). With mathjax set, $…$ and $$…$$ in a
+// markdown value become Anki's \(…\) and \[…\] MathJax delimiters (see
+// extractMath). A markdown value that renders to a single paragraph is
+// unwrapped so it flows inline in the card template like a text value.
+//
+// RTL and Font are applied as a wrapper element carrying dir and an inline
+// font style: a span around inline content, a div around block content.
+// Empty values stay empty, so Anki's field conditionals ({{#F}}) and card
+// requirements still see them as empty.
+func renderField(field types.FlashcardField, value string, mathjax bool) (string, error) {
+	if strings.TrimSpace(value) == "" {
+		return "", nil
+	}
+
+	var content string
+	block := false
+	switch field.Format {
+	case "markdown":
+		var math []string
+		if mathjax {
+			value, math = extractMath(value)
+		}
+		body, err := markdown.ToHTML([]byte(value))
+		if err != nil {
+			return "", err
+		}
+		content, block = unwrapParagraph(strings.TrimSpace(string(body)))
+		for i, m := range math {
+			content = strings.Replace(content, mathPlaceholder(i), m, 1)
+		}
+	case "html":
+		// Stored exactly as written: an imported deck's values already carry
+		// their own markup, so RTL and Font only configure Anki's editor.
+		return value, nil
+	default:
+		content = html.EscapeString(value)
+	}
+
+	var attrs []string
+	if field.RTL {
+		attrs = append(attrs, `dir="rtl"`)
+	}
+	if style := fieldStyle(field.Font); style != "" {
+		attrs = append(attrs, `style="`+html.EscapeString(style)+`"`)
+	}
+	if len(attrs) == 0 {
+		return content, nil
+	}
+
+	tag := "span"
+	if block {
+		tag = "div"
+	}
+	return fmt.Sprintf("<%s %s>%s</%s>", tag, strings.Join(attrs, " "), content, tag), nil
+}
+
+// unwrapParagraph strips the <p>…</p> around HTML that is exactly one
+// paragraph, reporting whether what remains is block content.
+func unwrapParagraph(body string) (string, bool) {
+	inner, ok := strings.CutPrefix(body, "<p>")
+	if !ok {
+		return body, true
+	}
+	inner, ok = strings.CutSuffix(inner, "</p>")
+	if !ok || strings.Contains(inner, "<p>") {
+		return body, true
+	}
+	return inner, false
+}
+
+// fieldStyle returns the inline CSS for a field's font, or "" when neither
+// name nor size is set.
+func fieldStyle(font types.FlashcardFont) string {
+	var style []string
+	if font.Name != "" {
+		style = append(style, fmt.Sprintf("font-family: %q", font.Name))
+	}
+	if font.Size != 0 {
+		style = append(style, fmt.Sprintf("font-size: %dpx", font.Size))
+	}
+	return strings.Join(style, "; ")
+}
+
+// mathPlaceholder is the token extractMath leaves in place of the i-th math
+// span: plain letters and digits, which the markdown converter passes
+// through untouched.
+func mathPlaceholder(i int) string {
+	return fmt.Sprintf("FLASHCARDMATH%dX", i)
+}
+
+// extractMath replaces the TeX math spans in a markdown value with
+// placeholders, returning the rewritten value and, per placeholder, the span
+// as escaped HTML in MathJax delimiters. Taking the math out before markdown
+// conversion keeps its backslashes, underscores and asterisks away from the
+// markdown parser.
+//
+// $$…$$ is display math and may span lines; $…$ is inline math on one line,
+// and follows Pandoc's rule that the opening $ is not followed by a space and
+// the closing $ is not preceded by one, so "$5 and $10" stays text. \$ is a
+// literal dollar sign. Code spans and fenced code blocks are copied as they
+// are, so TeX written in code stays literal.
+func extractMath(value string) (string, []string) {
+	var out strings.Builder
+	var math []string
+	add := func(open, tex, close string) {
+		out.WriteString(mathPlaceholder(len(math)))
+		math = append(math, open+html.EscapeString(tex)+close)
+	}
+
+	for i := 0; i < len(value); {
+		if i == 0 || value[i-1] == '\n' {
+			if end := codeFenceEnd(value[i:]); end > 0 {
+				out.WriteString(value[i : i+end])
+				i += end
+				continue
+			}
+		}
+		switch {
+		case value[i] == '`':
+			end := codeSpanEnd(value[i:])
+			out.WriteString(value[i : i+end])
+			i += end
+			continue
+		case strings.HasPrefix(value[i:], `\$`):
+			out.WriteString(`\$`)
+			i += 2
+			continue
+		case strings.HasPrefix(value[i:], "$$"):
+			if end := strings.Index(value[i+2:], "$$"); end > 0 {
+				add(`\[`, value[i+2:i+2+end], `\]`)
+				i += 2 + end + 2
+				continue
+			}
+		case value[i] == '$':
+			if end := inlineMathEnd(value[i+1:]); end > 0 {
+				add(`\(`, value[i+1:i+1+end], `\)`)
+				i += 1 + end + 1
+				continue
+			}
+		}
+		out.WriteByte(value[i])
+		i++
+	}
+	return out.String(), math
+}
+
+// codeFenceEnd returns the length of the fenced code block s starts with —
+// through its closing fence, or to the end of s when it is never closed — or
+// 0 when s does not start with an opening fence: up to three spaces, then at
+// least three backticks or tildes.
+func codeFenceEnd(s string) int {
+	line, _, _ := strings.Cut(s, "\n")
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || len(trimmed) < 3 || (trimmed[0] != '`' && trimmed[0] != '~') {
+		return 0
+	}
+	fence := trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, trimmed[:1]))]
+	if len(fence) < 3 || (fence[0] == '`' && strings.Contains(trimmed[len(fence):], "`")) {
+		return 0
+	}
+	for i := len(line) + 1; i < len(s); {
+		next, _, _ := strings.Cut(s[i:], "\n")
+		closing := strings.TrimSpace(next)
+		if strings.HasPrefix(closing, fence) && strings.Trim(closing, fence[:1]) == "" {
+			return min(i+len(next)+1, len(s))
+		}
+		i += len(next) + 1
+	}
+	return len(s)
+}
+
+// codeSpanEnd returns the length of the code span s starts with: its
+// opening run of backticks through the next run of the same length. An
+// opening run that is never closed is literal text, and only it is
+// returned.
+func codeSpanEnd(s string) int {
+	n := len(s) - len(strings.TrimLeft(s, "`"))
+	for i := n; i < len(s); {
+		j := strings.IndexByte(s[i:], '`')
+		if j < 0 {
+			break
+		}
+		i += j
+		run := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+		if run == n {
+			return i + run
+		}
+		i += run
+	}
+	return n
+}
+
+// inlineMathEnd returns the offset in s of the $ closing an inline math span
+// whose opening $ preceded s, or -1 when there is none on this line.
+func inlineMathEnd(s string) int {
+	if s == "" || s[0] == ' ' || s[0] == '\t' || s[0] == '\n' {
+		return -1
+	}
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\n':
+			return -1
+		case '\\':
+			i++
+		case '$':
+			if s[i-1] != ' ' && s[i-1] != '\t' {
+				return i
+			}
+		}
+	}
+	return -1
+}
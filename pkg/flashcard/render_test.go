@@ -0,0 +1,90 @@
+package flashcard
+
+import (
+	"testing"
+
+	"github.com/dpurge/cli-tools/pkg/types"
+)
+
+// TestRenderField: text is escaped, markdown is converted (single paragraphs
+// unwrapped), and RTL/font settings wrap non-empty values.
+func TestRenderField(t *testing.T) {
+	tests := []struct {
+		name  string
+		field types.FlashcardField
+		value string
+		want  string
+	}{
+		{"text escaped", types.FlashcardField{Format: "text"}, `a < b & "c"`, `a &lt; b &amp; &#34;c&#34;`},
+		{"empty stays empty", types.FlashcardField{Format: "text", RTL: true}, "  ", ""},
+		{"markdown paragraph unwrapped", types.FlashcardField{Format: "markdown"}, "*kot*", "<em>kot</em>"},
+		{"markdown blocks kept", types.FlashcardField{Format: "markdown"}, "- a\n- b\n", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>"},
+		{
+			name:  "rtl with font, inline",
+			field: types.FlashcardField{Format: "text", RTL: true, Font: types.FlashcardFont{Name: "Amiri", Size: 28}},
+			value: "مرحبا",
+			want:  `<span dir="rtl" style="font-family: &#34;Amiri&#34;; font-size: 28px">مرحبا</span>`,
+		},
+		{
+			name:  "rtl markdown block",
+			field: types.FlashcardField{Format: "markdown", RTL: true},
+			value: "שלום\n\nעולם",
+			want:  "<div dir=\"rtl\"><p>שלום</p>\n<p>עולם</p></div>",
+		},
+		{
+			name:  "vocabulary block",
+			field: types.FlashcardField{Format: "markdown"},
+			value: "{start-vocabulary}\nkot = cat\n{end-vocabulary}\n",
+			want: "<div class=\"block-marker\"><span class=\"ct-badge\">V</span></div>\n" +
+				"<div class=\"vocabulary\" dir=\"ltr\">\n<div class=\"vocabulary-item\">\n" +
+				"<span class=\"vocabulary-phrase\">kot</span>\n" +
+				"<span class=\"vocabulary-translation\" dir=\"ltr\">cat</span>\n</div>\n</div>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderField(tt.field, tt.value, false)
+			if err != nil {
+				t.Fatalf("renderField: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("renderField = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRenderFieldMathJax: with the mathjax option, $…$ and $$…$$ in markdown
+// fields become MathJax delimiters with their TeX left untouched by markdown.
+func TestRenderFieldMathJax(t *testing.T) {
+	markdownField := types.FlashcardField{Format: "markdown"}
+	tests := []struct {
+		name  string
+		field types.FlashcardField
+		value string
+		want  string
+	}{
+		{"inline", markdownField, `[$\text{k}_a^*$] is *aspirated*`, `[\(\text{k}_a^*\)] is <em>aspirated</em>`},
+		{"display", markdownField, "$$\nx < y_1\n$$", `\[` + "\nx &lt; y_1\n" + `\]`},
+		{"prices stay text", markdownField, "$5 and $10", "$5 and $10"},
+		{"escaped dollar", markdownField, `\$x$`, "$x$"},
+		{"text fields untouched", types.FlashcardField{Format: "text"}, "$x$", "$x$"},
+		{"code span", markdownField, "`$x$` and $y$", `<code>$x$</code> and \(y\)`},
+		{"double-backtick code span", markdownField, "``a ` $x$`` $y$", "<code>a ` $x$</code> \\(y\\)"},
+		{"fenced code block", markdownField, "```\n$$x$$\n```\n\n$y$", "<pre><code>$$x$$\n</code></pre>\n<p>\\(y\\)</p>"},
+		{"unclosed backtick", markdownField, "` $x$", "` \\(x\\)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderField(tt.field, tt.value, true)
+			if err != nil {
+				t.Fatalf("renderField: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("renderField = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package flashcard
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dpurge/cli-tools/pkg/tool"
+)
+
+// studyDateLayout is the layout of due dates in the study state file: whole
+// days, in local time, so a card due "today" is due all day.
+const studyDateLayout = "2006-01-02"
+
+// SM-2 constants: a new card's ease factor and the floor it never drops below.
+const (
+	sm2InitialEase = 2.5
+	sm2MinimumEase = 1.3
+)
+
+// Study grades, as typed in a review session, and the SM-2 quality (0-5)
+// each stands for. Anything below sm2Pass is a failed recall.
+var studyGrades = map[string]int{
+	"1": 1, // again
+	"2": 3, // hard
+	"3": 4, // good
+	"4": 5, // easy
+}
+
+const sm2Pass = 3
+
+// studyState is the study state file: the schedule and review history of
+// every card reviewed so far, keyed by studyCard.Key. Cards not in the map
+// are new.
+type studyState struct {
+	Cards map[string]*cardState `json:"cards"`
+}
+
+// cardState is one card's SM-2 schedule plus its review history.
+type cardState struct {
+	Ease     float64       `json:"ease"`
+	Interval int           `json:"interval"` // days
+	Reps     int           `json:"reps"`     // successful reviews in a row
+	Lapses   int           `json:"lapses"`   // failed reviews after the first success
+	Due      string        `json:"due"`      // studyDateLayout
+	History  []studyReview `json:"history"`
+}
+
+// studyReview is one review of a card: when, the SM-2 quality given, and the
+// schedule it produced.
+type studyReview struct {
+	Time     time.Time `json:"time"`
+	Quality  int       `json:"quality"`
+	Interval int       `json:"interval"`
+	Ease     float64   `json:"ease"`
+}
+
+// studyStateFile returns the state file kept next to a project file:
+// flashcard.yml -> flashcard.study.json.
+func studyStateFile(projectFile string) string {
+	return tool.BaseOutputName(projectFile) + ".study.json"
+}
+
+// readStudyState loads a state file; a missing file is an empty state.
+func readStudyState(filename string) (*studyState, error) {
+	state := &studyState{Cards: map[string]*cardState{}}
+	buf, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(buf, state); err != nil {
+		return nil, err
+	}
+	if state.Cards == nil {
+		state.Cards = map[string]*cardState{}
+	}
+	return state, nil
+}
+
+// writeStudyState saves the state through a temporary file and a rename, so
+// an interrupted session never leaves a half-written file behind.
+func writeStudyState(filename string, state *studyState) error {
+	buf, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(buf, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// isDue reports whether a reviewed card is due on or before the day of now.
+func (c *cardState) isDue(now time.Time) bool {
+	return c.Due <= now.Format(studyDateLayout)
+}
+
+// review applies one SM-2 repetition with the given quality (0-5). A failed
+// recall restarts the repetitions at a one-day interval; a passed one moves
+// to 1 day, then 6 days, then the previous interval times the ease factor.
+// The ease factor is adjusted by every review, down to sm2MinimumEase.
+func (c *cardState) review(quality int, now time.Time) {
+	if c.Ease == 0 {
+		c.Ease = sm2InitialEase
+	}
+
+	if quality < sm2Pass {
+		if c.Reps > 0 {
+			c.Lapses++
+		}
+		c.Reps = 0
+		c.Interval = 1
+	} else {
+		switch c.Reps {
+		case 0:
+			c.Interval = 1
+		case 1:
+			c.Interval = 6
+		default:
+			c.Interval = int(math.Round(float64(c.Interval) * c.Ease))
+		}
+		c.Reps++
+	}
+
+	q := float64(5 - quality)
+	c.Ease = math.Max(sm2MinimumEase, c.Ease+0.1-q*(0.08+q*0.02))
+	// Rounded so the state file stays readable; SM-2 needs no more precision.
+	c.Ease = math.Round(c.Ease*100) / 100
+
+	c.Due = now.AddDate(0, 0, c.Interval).Format(studyDateLayout)
+	c.History = append(c.History, studyReview{Time: now, Quality: quality, Interval: c.Interval, Ease: c.Ease})
+}
@@ -0,0 +1,73 @@
+package flashcard
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestCardStateReview walks a card through SM-2 repetitions: 1 day, 6 days,
+// then interval x ease; a failure restarts at 1 day and counts a lapse, and
+// the ease factor never drops below 1.3.
+func TestCardStateReview(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)
+	tests := []struct {
+		quality  int
+		interval int
+		reps     int
+		lapses   int
+		ease     float64
+		due      string
+	}{
+		{4, 1, 1, 0, 2.5, "2026-03-02"},
+		{4, 6, 2, 0, 2.5, "2026-03-07"},
+		{5, 15, 3, 0, 2.6, "2026-03-16"},
+		{3, 39, 4, 0, 2.46, "2026-04-09"},
+		{1, 1, 0, 1, 1.92, "2026-03-02"},
+		{0, 1, 0, 1, 1.3, "2026-03-02"},
+		{4, 1, 1, 1, 1.3, "2026-03-02"},
+	}
+
+	cs := &cardState{}
+	for i, tt := range tests {
+		cs.review(tt.quality, now)
+		if cs.Interval != tt.interval || cs.Reps != tt.reps || cs.Lapses != tt.lapses || cs.Ease != tt.ease || cs.Due != tt.due {
+			t.Errorf("review %d (q=%d) = interval %d, reps %d, lapses %d, ease %v, due %s; want %d, %d, %d, %v, %s",
+				i+1, tt.quality, cs.Interval, cs.Reps, cs.Lapses, cs.Ease, cs.Due, tt.interval, tt.reps, tt.lapses, tt.ease, tt.due)
+		}
+	}
+	if len(cs.History) != len(tests) {
+		t.Errorf("history has %d reviews, want %d", len(cs.History), len(tests))
+	}
+	if !cs.isDue(now.AddDate(0, 0, 1)) || cs.isDue(now) {
+		t.Errorf("due %s: want due tomorrow, not today", cs.Due)
+	}
+}
+
+// TestStudyStateRoundTrip: a missing state file is an empty state, and a
+// saved state reads back unchanged.
+func TestStudyStateRoundTrip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "deck.study.json")
+	state, err := readStudyState(filename)
+	if err != nil || len(state.Cards) != 0 {
+		t.Fatalf("readStudyState(missing) = %v, %v; want empty state", state, err)
+	}
+
+	state.Cards["abc/0"] = &cardState{}
+	state.Cards["abc/0"].review(4, time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	if err := writeStudyState(filename, state); err != nil {
+		t.Fatalf("writeStudyState: %v", err)
+	}
+	got, err := readStudyState(filename)
+	if err != nil {
+		t.Fatalf("readStudyState: %v", err)
+	}
+	cs := got.Cards["abc/0"]
+	if cs == nil || cs.Due != "2026-03-02" || len(cs.History) != 1 || cs.History[0].Quality != 4 {
+		t.Errorf("read back %+v", cs)
+	}
+
+	if f := studyStateFile("/p/flashcard.yml"); f != "/p/flashcard.study.json" {
+		t.Errorf("studyStateFile = %q", f)
+	}
+}
@@ -0,0 +1,67 @@
+package flashcard
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var _newCards int
+
+var studyCmd = &cobra.Command{
+	Use:   "study",
+	Short: "Review flashcards in the terminal",
+	Long: "Run an interactive review session in the terminal, without Anki. Cards are " +
+		"scheduled with SM-2; their schedule and review history are kept in a " +
+		"<project>.study.json file next to the project file.",
+	Run: func(cmd *cobra.Command, args []string) {
+		project, err := ReadProject(_project)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		notes, err := ReadNotes(project)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		cards, err := studyCards(project, notes)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		stateFile := studyStateFile(_project)
+		state, err := readStudyState(stateFile)
+		if err != nil {
+			log.Fatalf("in file %q: %v", stateFile, err)
+		}
+
+		queue := studyQueue(cards, state, time.Now(), _newCards)
+		if len(queue) == 0 {
+			if next := nextDue(state); next != "" {
+				fmt.Printf("Nothing to study; next review due %s.\n", next)
+			} else {
+				fmt.Println("Nothing to study.")
+			}
+			return
+		}
+
+		reviews, err := studySession(os.Stdin, os.Stdout, queue, state, time.Now, func() error {
+			return writeStudyState(stateFile, state)
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("\n%d reviews saved to %s\n", reviews, stateFile)
+	},
+}
+
+func init() {
+	mainCmd.AddCommand(studyCmd)
+
+	studyCmd.Flags().StringVarP(&_project, "project", "p", "flashcard.yml", "flashcard project file")
+	studyCmd.Flags().IntVarP(&_newCards, "new", "n", 20, "maximum number of new cards per session")
+}
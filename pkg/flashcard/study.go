@@ -0,0 +1,325 @@
+package flashcard
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dpurge/cli-tools/pkg/types"
+)
+
+// studyCard is one card of a study session: its question and answer as
+// plain text, and the key its schedule is stored under — the note's GUID
+// plus the card ordinal, so editing a non-index field keeps the schedule.
+type studyCard struct {
+	Key      string
+	Question string
+	Answer   string
+}
+
+var (
+	// ankiClozeDeletionRe matches one whole cloze deletion, {{c1::text}} or
+	// {{c1::text::hint}}.
+	ankiClozeDeletionRe = regexp.MustCompile(`(?s)\{\{c([1-9][0-9]*)::(.*?)(?:::(.*?))?\}\}`)
+
+	studyBlockTagRe = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|tr|h[1-6]|blockquote|table)>`)
+	studyCellTagRe  = regexp.MustCompile(`(?i)</t[dh]>`)
+	studyRuleTagRe  = regexp.MustCompile(`(?i)<hr\b[^>]*>`)
+	studyImageTagRe = regexp.MustCompile(`(?i)<img\b[^>]*?\ssrc\s*=\s*("[^"]*"|'[^']*')[^>]*>`)
+	studySkipRe     = regexp.MustCompile(`(?is)<(style|script)\b.*?</(style|script)>`)
+)
+
+// studyCards renders every card the notes produce, in note order: the same
+// cards an .apkg build would give, with the fields rendered as for the
+// package and the card templates applied, then reduced to plain text.
+func studyCards(project *types.FlashcardProject, notes []Note) ([]studyCard, error) {
+	if err := checkDuplicateIndex(project.Model.Fields, notes); err != nil {
+		return nil, err
+	}
+	collection, err := newAnkiCollection(project, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	model := collection.Model
+	indexOrds := indexFieldOrds(collection.Fields)
+
+	var cards []studyCard
+	for _, note := range notes {
+		fields, err := renderNoteFields(collection.Fields, collection.MathJax, note)
+		if err != nil {
+			return nil, err
+		}
+		values := map[string]string{
+			"Tags": strings.Join(note.Tags, " "),
+			"Deck": project.Deck.Name,
+		}
+		for i, field := range model.Flds {
+			values[field.Name] = fields[i]
+		}
+		guid := noteGUID(model.ID, noteIndex(indexOrds, note))
+
+		if model.Type == ankiModelCloze {
+			tmpl := model.Tmpls[0]
+			for _, ord := range clozeCardOrds(collection.ClozeFields, note.Fields) {
+				values["Card"] = tmpl.Name
+				card := cardTemplate{values: values, cloze: ord + 1}
+				cards = append(cards, card.studyCard(guid, ord, tmpl))
+			}
+			continue
+		}
+		for _, ord := range noteCardOrds(model, note.Fields) {
+			tmpl := model.Tmpls[ord]
+			values["Card"] = tmpl.Name
+			card := cardTemplate{values: values}
+			cards = append(cards, card.studyCard(guid, ord, tmpl))
+		}
+	}
+	return cards, nil
+}
+
+// cardTemplate renders Anki card templates against one note's rendered
+// field values: {{Field}} and filtered {{filter:Field}} references,
+// {{#Field}}…{{/Field}} and {{^Field}}…{{/Field}} sections, and FrontSide on
+// the answer side. cloze is the 1-based deletion the card asks for (0 for a
+// standard card).
+type cardTemplate struct {
+	values map[string]string
+	cloze  int
+	answer bool
+}
+
+func (c cardTemplate) studyCard(guid string, ord int, tmpl ankiTemplate) studyCard {
+	question := c.render(tmpl.QFmt)
+
+	values := map[string]string{"FrontSide": question}
+	for name, value := range c.values {
+		values[name] = value
+	}
+	answer := cardTemplate{values: values, cloze: c.cloze, answer: true}.render(tmpl.AFmt)
+
+	return studyCard{
+		Key:      guid + "/" + strconv.Itoa(ord),
+		Question: htmlToText(question),
+		Answer:   htmlToText(answer),
+	}
+}
+
+func (c cardTemplate) render(tmpl string) string {
+	var out strings.Builder
+	for {
+		start := strings.Index(tmpl, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(tmpl[start:], "}}")
+		if end < 0 {
+			break
+		}
+		out.WriteString(tmpl[:start])
+		tag := strings.TrimSpace(tmpl[start+2 : start+end])
+		tmpl = tmpl[start+end+2:]
+
+		switch {
+		case strings.HasPrefix(tag, "#"), strings.HasPrefix(tag, "^"):
+			name := strings.TrimSpace(tag[1:])
+			closeStart, closeEnd := sectionEnd(tmpl, name)
+			if closeStart < 0 {
+				continue
+			}
+			body := tmpl[:closeStart]
+			tmpl = tmpl[closeEnd:]
+			if (tag[0] == '#') == (strings.TrimSpace(c.values[name]) != "") {
+				out.WriteString(c.render(body))
+			}
+		case strings.HasPrefix(tag, "/"):
+			// A closing tag without its section: dropped, as Anki does.
+		default:
+			out.WriteString(c.field(tag))
+		}
+	}
+	out.WriteString(tmpl)
+	return out.String()
+}
+
+// sectionEnd finds the {{/name}} tag closing a section, tolerating spaces
+// inside the braces, and returns its start and end offsets in tmpl, or -1, -1
+// when the section is never closed.
+func sectionEnd(tmpl, name string) (int, int) {
+	for offset := 0; ; {
+		start := strings.Index(tmpl[offset:], "{{")
+		if start < 0 {
+			return -1, -1
+		}
+		start += offset
+		end := strings.Index(tmpl[start:], "}}")
+		if end < 0 {
+			return -1, -1
+		}
+		end += start
+		tag := strings.TrimSpace(tmpl[start+2 : end])
+		if strings.HasPrefix(tag, "/") && strings.TrimSpace(tag[1:]) == name {
+			return start, end + 2
+		}
+		offset = start + 2
+	}
+}
+
+// field resolves one {{filter:…:Field}} reference. Filters apply right to
+// left. type: (type-in answers) has no plain-text equivalent and renders
+// nothing; unknown filters pass the value through.
+func (c cardTemplate) field(tag string) string {
+	filters := strings.Split(tag, ":")
+	value := c.values[strings.TrimSpace(filters[len(filters)-1])]
+	for i := len(filters) - 2; i >= 0; i-- {
+		switch strings.TrimSpace(filters[i]) {
+		case "cloze":
+			value = c.renderCloze(value)
+		case "text":
+			value = html.EscapeString(htmlToText(value))
+		case "type":
+			return ""
+		}
+	}
+	return value
+}
+
+// renderCloze shows the card's own deletion as [...] (or [hint]) on the
+// question side and as [text] on the answer side; other deletions show
+// their text.
+func (c cardTemplate) renderCloze(value string) string {
+	return ankiClozeDeletionRe.ReplaceAllStringFunc(value, func(deletion string) string {
+		m := ankiClozeDeletionRe.FindStringSubmatch(deletion)
+		if n, _ := strconv.Atoi(m[1]); n != c.cloze {
+			return m[2]
+		}
+		if c.answer {
+			return "[" + m[2] + "]"
+		}
+		if m[3] != "" {
+			return "[" + m[3] + "]"
+		}
+		return "[...]"
+	})
+}
+
+// htmlToText reduces rendered card HTML to plain text: block ends become
+// line breaks, table cells are separated by spaces, a rule becomes a line
+// of dashes and an image its file name; other tags are dropped, entities
+// decoded and blank lines collapsed.
+func htmlToText(s string) string {
+	s = studySkipRe.ReplaceAllString(s, "")
+	s = studyImageTagRe.ReplaceAllStringFunc(s, func(img string) string {
+		src := studyImageTagRe.FindStringSubmatch(img)[1]
+		return "[image: " + src[1:len(src)-1] + "]"
+	})
+	s = studyRuleTagRe.ReplaceAllString(s, "\n----\n")
+	s = studyBlockTagRe.ReplaceAllString(s, "\n")
+	s = studyCellTagRe.ReplaceAllString(s, "  ")
+	s = ankiHTMLTagRe.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" && (len(lines) == 0 || lines[len(lines)-1] == "") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// studyQueue picks the cards for a session: reviewed cards that are due,
+// most overdue first, then up to newLimit cards never reviewed, in note
+// order.
+func studyQueue(cards []studyCard, state *studyState, now time.Time, newLimit int) []studyCard {
+	var due, fresh []studyCard
+	for _, card := range cards {
+		cs, ok := state.Cards[card.Key]
+		switch {
+		case !ok:
+			if len(fresh) < newLimit {
+				fresh = append(fresh, card)
+			}
+		case cs.isDue(now):
+			due = append(due, card)
+		}
+	}
+	slices.SortStableFunc(due, func(a, b studyCard) int {
+		return strings.Compare(state.Cards[a.Key].Due, state.Cards[b.Key].Due)
+	})
+	return append(due, fresh...)
+}
+
+// studySession runs an interactive review: for each card it shows the
+// question, waits for Enter, shows the answer and reads a grade. Each grade
+// is scheduled with SM-2 and saved straight away, so quitting (q, or end of
+// input) loses nothing. A failed card comes back at the end of the session.
+// It returns the number of reviews made.
+func studySession(in io.Reader, out io.Writer, queue []studyCard, state *studyState, now func() time.Time, save func() error) (int, error) {
+	scanner := bufio.NewScanner(in)
+	readLine := func() (string, bool) {
+		if !scanner.Scan() {
+			return "", false
+		}
+		return strings.TrimSpace(scanner.Text()), true
+	}
+
+	reviews := 0
+	for i := 0; i < len(queue); i++ {
+		card := queue[i]
+		label := "new"
+		if _, ok := state.Cards[card.Key]; ok {
+			label = "review"
+		}
+		fmt.Fprintf(out, "\n[%d/%d %s]\n%s\n\n(Enter: show answer, q: quit) ", i+1, len(queue), label, card.Question)
+		if line, ok := readLine(); !ok || line == "q" {
+			break
+		}
+		fmt.Fprintf(out, "\n%s\n\n", card.Answer)
+
+		quality := -1
+		for quality < 0 {
+			fmt.Fprint(out, "1 again, 2 hard, 3 good, 4 easy (q: quit) ")
+			line, ok := readLine()
+			if !ok || line == "q" {
+				return reviews, nil
+			}
+			if q, ok := studyGrades[line]; ok {
+				quality = q
+			}
+		}
+
+		cs, ok := state.Cards[card.Key]
+		if !ok {
+			cs = &cardState{}
+			state.Cards[card.Key] = cs
+		}
+		cs.review(quality, now())
+		reviews++
+		if err := save(); err != nil {
+			return reviews, err
+		}
+		if quality < sm2Pass {
+			queue = append(queue, card)
+		}
+	}
+	return reviews, scanner.Err()
+}
+
+// nextDue returns the earliest due date in the state, or "" if it is empty.
+func nextDue(state *studyState) string {
+	next := ""
+	for _, cs := range state.Cards {
+		if next == "" || cs.Due < next {
+			next = cs.Due
+		}
+	}
+	return next
+}
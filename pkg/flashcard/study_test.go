@@ -0,0 +1,164 @@
+package flashcard
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// loadStudyCards reads a test project and renders its study cards.
+func loadStudyCards(t *testing.T, projectYAML string, files map[string]string) []studyCard {
+	t.Helper()
+	project, err := ReadProject(writeTestProject(t, projectYAML, files))
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	notes, err := ReadNotes(project)
+	if err != nil {
+		t.Fatalf("ReadNotes: %v", err)
+	}
+	cards, err := studyCards(project, notes)
+	if err != nil {
+		t.Fatalf("studyCards: %v", err)
+	}
+	return cards
+}
+
+// TestStudyCards: card templates are applied to the rendered fields, with
+// FrontSide, sections and the answer rule, and reduced to plain text.
+func TestStudyCards(t *testing.T) {
+	cards := loadStudyCards(t, testProjectYAML, map[string]string{
+		"front.html": "<div class=front>{{Front}}</div>{{#Tags}}<small>{{Tags}}</small>{{/Tags}}",
+		"words.tsv":  "Front\tBack\nkot & mysz\tcat\n",
+	})
+	if len(cards) != 1 {
+		t.Fatalf("got %d cards, want 1", len(cards))
+	}
+	if want := "kot & mysz\nlesson1"; cards[0].Question != want {
+		t.Errorf("question = %q, want %q", cards[0].Question, want)
+	}
+	if want := "kot & mysz\nlesson1\n----\ncat"; cards[0].Answer != want {
+		t.Errorf("answer = %q, want %q", cards[0].Answer, want)
+	}
+	if !strings.HasSuffix(cards[0].Key, "/0") {
+		t.Errorf("key = %q, want <guid>/0", cards[0].Key)
+	}
+}
+
+// TestStudyCardsCloze: a cloze note gives one card per deletion, each hiding
+// only its own deletion.
+func TestStudyCardsCloze(t *testing.T) {
+	cards := loadStudyCards(t, testClozeYAML, map[string]string{
+		"front.html": "{{cloze:Front}}",
+		"back.html":  "{{cloze:Front}}<br>{{Back}}",
+		"words.tsv":  "Front\tBack\n{{c1::kot}} i {{c2::pies::animal}}\tcat and dog\n",
+	})
+	want := []studyCard{
+		{Question: "[...] i pies", Answer: "[kot] i pies\ncat and dog"},
+		{Question: "kot i [animal]", Answer: "kot i [pies]\ncat and dog"},
+	}
+	if len(cards) != len(want) {
+		t.Fatalf("got %d cards, want %d", len(cards), len(want))
+	}
+	for i, card := range cards {
+		if card.Question != want[i].Question || card.Answer != want[i].Answer {
+			t.Errorf("card %d = %q / %q, want %q / %q", i, card.Question, card.Answer, want[i].Question, want[i].Answer)
+		}
+	}
+}
+
+// TestCardTemplateSections covers {{#…}} and {{^…}} sections, including
+// spaced closing tags, nesting and a section that is never closed.
+func TestCardTemplateSections(t *testing.T) {
+	c := cardTemplate{values: map[string]string{"Front": "kot", "Back": "", "a.b": "x"}}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"{{#Front}}[{{Front}}]{{/Front}}", "[kot]"},
+		{"{{#Back}}[{{Back}}]{{/Back}}!", "!"},
+		{"{{^Back}}none{{/Back}}", "none"},
+		{"{{ #Front }}x{{ / Front }}y", "xy"},
+		{"{{#Front}}{{#Back}}b{{/Back}}f{{/Front}}", "f"},
+		{"{{#a.b}}{{a.b}}{{/a.b}}", "x"},
+		{"{{#Front}}open", "open"},
+		{"a{{/Front}}b", "ab"},
+	}
+	for _, tt := range tests {
+		if got := c.render(tt.in); got != tt.want {
+			t.Errorf("render(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestHTMLToText covers the tags the plain-text card view maps to layout.
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"<p>a</p><p>b</p>", "a\nb"},
+		{"a<br>b<br/>c", "a\nb\nc"},
+		{"<style>.x{}</style>q<hr id=answer>a", "q\n----\na"},
+		{`<img src="3f9c.png"> kot`, "[image: 3f9c.png] kot"},
+		{"<table><tr><td>a</td><td>b</td></tr></table>", "a  b"},
+		{"<span dir=\"rtl\">&lt;قطة&gt;</span>", "<قطة>"},
+	}
+	for _, tt := range tests {
+		if got := htmlToText(tt.in); got != tt.want {
+			t.Errorf("htmlToText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestStudyQueue: due cards come first, most overdue first; new cards follow
+// up to the limit; cards due later are left out.
+func TestStudyQueue(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	cards := []studyCard{{Key: "a"}, {Key: "b"}, {Key: "c"}, {Key: "d"}, {Key: "e"}, {Key: "f"}}
+	state := &studyState{Cards: map[string]*cardState{
+		"a": {Due: "2026-03-10"},
+		"b": {Due: "2026-03-11"},
+		"c": {Due: "2026-03-01"},
+	}}
+
+	var got []string
+	for _, card := range studyQueue(cards, state, now, 2) {
+		got = append(got, card.Key)
+	}
+	if strings.Join(got, "") != "cade" {
+		t.Errorf("queue = %v, want [c a d e]", got)
+	}
+}
+
+// TestStudySession drives a session through scripted input: a failed card
+// comes back at the end, every grade is saved, and q ends the session.
+func TestStudySession(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	queue := []studyCard{{Key: "a", Question: "kot", Answer: "cat"}, {Key: "b", Question: "pies", Answer: "dog"}}
+	state := &studyState{Cards: map[string]*cardState{}}
+	saves := 0
+
+	// a: again; b: invalid grade, then good; a (repeated): good; then quit.
+	input := "\n1\n\nx\n3\n\n3\n"
+	var out strings.Builder
+	reviews, err := studySession(strings.NewReader(input), &out, queue, state, func() time.Time { return now }, func() error {
+		saves++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("studySession: %v", err)
+	}
+	if reviews != 3 || saves != 3 {
+		t.Errorf("reviews = %d, saves = %d; want 3, 3", reviews, saves)
+	}
+	if a := state.Cards["a"]; a == nil || len(a.History) != 2 || a.Due != "2026-03-11" {
+		t.Errorf("card a = %+v, want two reviews, due tomorrow", a)
+	}
+	if b := state.Cards["b"]; b == nil || b.Reps != 1 {
+		t.Errorf("card b = %+v, want one passed review", b)
+	}
+	if !strings.Contains(out.String(), "[3/3 review]\nkot") {
+		t.Errorf("output missing the repeated card:\n%s", out.String())
+	}
+}
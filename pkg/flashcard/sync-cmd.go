@@ -0,0 +1,45 @@
+package flashcard
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/dpurge/cli-tools/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var _delete bool
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Push a flashcard project to Anki through AnkiConnect",
+	Long: "Push a flashcard project to a running Anki through the AnkiConnect add-on " +
+		"(AnkiConnect.url in the config, default " + config.DefaultAnkiConnectURL + "): " +
+		"create or update the deck and note type, add new notes and update changed ones, " +
+		"matched by their index fields.",
+	Run: func(cmd *cobra.Command, args []string) {
+		project, err := ReadProject(_project)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		notes, err := ReadNotes(project)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		cfg := config.GetAnkiConnectConfig()
+		result, err := syncProject(newAnkiConnect(cfg.URL, cfg.Key), project, notes, _delete)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s: %s\n", project.Deck.Name, result)
+	},
+}
+
+func init() {
+	mainCmd.AddCommand(syncCmd)
+
+	syncCmd.Flags().StringVarP(&_project, "project", "p", "flashcard.yml", "flashcard project file")
+	syncCmd.Flags().BoolVar(&_delete, "delete", false, "delete notes that are no longer in the data files")
+}
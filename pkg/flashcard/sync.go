@@ -0,0 +1,321 @@
+package flashcard
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dpurge/cli-tools/pkg/types"
+)
+
+// syncResult counts what a sync changed in Anki.
+type syncResult struct {
+	DeckCreated  bool
+	ModelCreated bool
+	ModelUpdated bool
+	Media        int // media files uploaded
+	Added        int
+	Updated      int
+	Unchanged    int
+	Deleted      int
+	Missing      int // notes in Anki but no longer in the data, kept
+}
+
+// syncProject pushes a project's deck, note type and notes to a running Anki
+// through AnkiConnect. The deck and note type are matched by name and created
+// when missing; an existing note type gets the project's CSS and card
+// templates and any fields it lacks. Notes are matched to the ones already in
+// the deck by their index fields: new ones are added, changed ones (fields or
+// tags) updated in place, keeping their review history. With deleteMissing,
+// notes of the note type in the deck that no longer appear in the data are
+// deleted; otherwise they are only counted.
+//
+// Field values are rendered exactly as for an .apkg build, media included.
+func syncProject(client *ankiConnect, project *types.FlashcardProject, notes []Note, deleteMissing bool) (*syncResult, error) {
+	if err := checkDuplicateIndex(project.Model.Fields, notes); err != nil {
+		return nil, err
+	}
+	collection, err := newAnkiCollection(project, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	model := collection.Model
+	result := &syncResult{}
+
+	var deckNames []string
+	if err := client.invoke("deckNames", nil, &deckNames); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(deckNames, project.Deck.Name) {
+		if err := client.invoke("createDeck", map[string]any{"deck": project.Deck.Name}, nil); err != nil {
+			return nil, err
+		}
+		result.DeckCreated = true
+	}
+
+	var modelNames []string
+	if err := client.invoke("modelNames", nil, &modelNames); err != nil {
+		return nil, err
+	}
+	if slices.Contains(modelNames, model.Name) {
+		if result.ModelUpdated, err = syncModel(client, model); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := createModel(client, model); err != nil {
+			return nil, err
+		}
+		result.ModelCreated = true
+	}
+
+	// Render the notes as the .apkg builder does, keyed by their rendered
+	// index values: the form the same notes have once they are in Anki.
+	media := newAnkiMedia(project.Directory)
+	indexOrds := indexFieldOrds(collection.Fields)
+	local := make([]map[string]string, len(notes))
+	keys := make([]string, len(notes))
+	for i, note := range notes {
+		fields, err := renderNoteFields(collection.Fields, collection.MathJax, note)
+		if err != nil {
+			return nil, err
+		}
+		local[i] = map[string]string{}
+		for f := range fields {
+			if fields[f], err = media.collect(fields[f]); err != nil {
+				return nil, fmt.Errorf("%s: field %s: %w", note.Position(), model.Flds[f].Name, err)
+			}
+			local[i][model.Flds[f].Name] = fields[f]
+		}
+		keys[i] = syncKey(model.Flds, indexOrds, local[i])
+	}
+
+	paths, names := media.entries()
+	for i, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		params := map[string]any{"filename": names[i], "data": base64.StdEncoding.EncodeToString(content)}
+		if err := client.invoke("storeMediaFile", params, nil); err != nil {
+			return nil, err
+		}
+		result.Media++
+	}
+
+	query := ankiSearchTerm("note", model.Name) + " " + ankiSearchTerm("deck", project.Deck.Name)
+	var ids []int64
+	if err := client.invoke("findNotes", map[string]any{"query": query}, &ids); err != nil {
+		return nil, err
+	}
+	var existing []ankiConnectNote
+	if len(ids) > 0 {
+		if err := client.invoke("notesInfo", map[string]any{"notes": ids}, &existing); err != nil {
+			return nil, err
+		}
+	}
+	byKey := map[string]ankiConnectNote{}
+	for _, note := range existing {
+		values := map[string]string{}
+		for name, field := range note.Fields {
+			values[name] = field.Value
+		}
+		key := syncKey(model.Flds, indexOrds, values)
+		if _, ok := byKey[key]; !ok {
+			byKey[key] = note
+		}
+	}
+
+	matched := map[int64]bool{}
+	var added []ankiConnectNewNote
+	var addedNotes []Note
+	for i, note := range notes {
+		tags := strings.Fields(ankiTags(note.Tags))
+		if tags == nil {
+			tags = []string{}
+		}
+		current, ok := byKey[keys[i]]
+		if !ok {
+			added = append(added, ankiConnectNewNote{DeckName: project.Deck.Name, ModelName: model.Name, Fields: local[i], Tags: tags})
+			addedNotes = append(addedNotes, note)
+			continue
+		}
+		matched[current.NoteID] = true
+
+		changed := !slices.Equal(slices.Sorted(slices.Values(current.Tags)), slices.Sorted(slices.Values(tags)))
+		for name, value := range local[i] {
+			if current.Fields[name].Value != value {
+				changed = true
+			}
+		}
+		if !changed {
+			result.Unchanged++
+			continue
+		}
+		params := map[string]any{"note": map[string]any{"id": current.NoteID, "fields": local[i], "tags": tags}}
+		if err := client.invoke("updateNote", params, nil); err != nil {
+			return nil, fmt.Errorf("%s: %w", note.Position(), err)
+		}
+		result.Updated++
+	}
+
+	if len(added) > 0 {
+		var addedIDs []*int64
+		if err := client.invoke("addNotes", map[string]any{"notes": added}, &addedIDs); err != nil {
+			return nil, err
+		}
+		for i, id := range addedIDs {
+			if id == nil && i < len(addedNotes) {
+				return nil, fmt.Errorf("%s: AnkiConnect addNotes: note was not added", addedNotes[i].Position())
+			}
+		}
+		result.Added = len(added)
+	}
+
+	var missing []int64
+	for _, note := range existing {
+		if !matched[note.NoteID] {
+			missing = append(missing, note.NoteID)
+		}
+	}
+	if deleteMissing && len(missing) > 0 {
+		if err := client.invoke("deleteNotes", map[string]any{"notes": missing}, nil); err != nil {
+			return nil, err
+		}
+		result.Deleted = len(missing)
+	} else {
+		result.Missing = len(missing)
+	}
+
+	return result, nil
+}
+
+// createModel creates the note type with the project's fields, templates and
+// CSS.
+func createModel(client *ankiConnect, model ankiModel) error {
+	fields := make([]string, len(model.Flds))
+	for i, field := range model.Flds {
+		fields[i] = field.Name
+	}
+	templates := make([]ankiConnectTemplate, len(model.Tmpls))
+	for i, tmpl := range model.Tmpls {
+		templates[i] = ankiConnectTemplate{Name: tmpl.Name, Front: tmpl.QFmt, Back: tmpl.AFmt}
+	}
+	return client.invoke("createModel", map[string]any{
+		"modelName":     model.Name,
+		"inOrderFields": fields,
+		"css":           model.CSS,
+		"isCloze":       model.Type == ankiModelCloze,
+		"cardTemplates": templates,
+	}, nil)
+}
+
+// syncModel brings an existing note type in line with the project: missing
+// fields and card templates are added, changed templates and CSS replaced.
+// Fields and templates Anki has beyond the project's are left alone, since
+// removing them would delete data. It reports whether anything changed.
+func syncModel(client *ankiConnect, model ankiModel) (bool, error) {
+	changed := false
+	nameParam := map[string]any{"modelName": model.Name}
+
+	var fieldNames []string
+	if err := client.invoke("modelFieldNames", nameParam, &fieldNames); err != nil {
+		return false, err
+	}
+	for i, field := range model.Flds {
+		if slices.Contains(fieldNames, field.Name) {
+			continue
+		}
+		params := map[string]any{"modelName": model.Name, "fieldName": field.Name, "index": i}
+		if err := client.invoke("modelFieldAdd", params, nil); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	var templates map[string]struct{ Front, Back string }
+	if err := client.invoke("modelTemplates", nameParam, &templates); err != nil {
+		return false, err
+	}
+	updated := map[string]any{}
+	for _, tmpl := range model.Tmpls {
+		current, ok := templates[tmpl.Name]
+		if !ok {
+			params := map[string]any{"modelName": model.Name, "template": ankiConnectTemplate{Name: tmpl.Name, Front: tmpl.QFmt, Back: tmpl.AFmt}}
+			if err := client.invoke("modelTemplateAdd", params, nil); err != nil {
+				return false, err
+			}
+			changed = true
+			continue
+		}
+		if current.Front != tmpl.QFmt || current.Back != tmpl.AFmt {
+			updated[tmpl.Name] = map[string]string{"Front": tmpl.QFmt, "Back": tmpl.AFmt}
+		}
+	}
+	if len(updated) > 0 {
+		params := map[string]any{"model": map[string]any{"name": model.Name, "templates": updated}}
+		if err := client.invoke("updateModelTemplates", params, nil); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	var styling struct {
+		CSS string `json:"css"`
+	}
+	if err := client.invoke("modelStyling", nameParam, &styling); err != nil {
+		return false, err
+	}
+	if styling.CSS != model.CSS {
+		params := map[string]any{"model": map[string]any{"name": model.Name, "css": model.CSS}}
+		if err := client.invoke("updateModelStyling", params, nil); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// syncKey identifies a note by its (rendered) index field values.
+func syncKey(fields []ankiField, indexOrds []int, values map[string]string) string {
+	parts := make([]string, len(indexOrds))
+	for i, ord := range indexOrds {
+		parts[i] = strings.TrimSpace(values[fields[ord].Name])
+	}
+	return strings.Join(parts, "\x1f")
+}
+
+// ankiSearchTerm quotes a key:value term for an Anki search, escaping the
+// characters Anki's search syntax gives meaning to.
+func ankiSearchTerm(key, value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `*`, `\*`, `_`, `\_`).Replace(value)
+	return `"` + key + ":" + escaped + `"`
+}
+
+// String summarizes the sync for the command's output.
+func (r *syncResult) String() string {
+	var parts []string
+	if r.DeckCreated {
+		parts = append(parts, "deck created")
+	}
+	switch {
+	case r.ModelCreated:
+		parts = append(parts, "note type created")
+	case r.ModelUpdated:
+		parts = append(parts, "note type updated")
+	}
+	if r.Media > 0 {
+		parts = append(parts, fmt.Sprintf("%d media files", r.Media))
+	}
+	parts = append(parts, fmt.Sprintf("%d added, %d updated, %d unchanged", r.Added, r.Updated, r.Unchanged))
+	if r.Deleted > 0 {
+		parts = append(parts, fmt.Sprintf("%d deleted", r.Deleted))
+	}
+	if r.Missing > 0 {
+		parts = append(parts, fmt.Sprintf("%d no longer in the data (kept; use --delete)", r.Missing))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,297 @@
+package flashcard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeAnki is an in-memory stand-in for Anki with the AnkiConnect add-on,
+// implementing the actions sync uses.
+type fakeAnki struct {
+	decks     []string
+	models    map[string]*fakeModel
+	notes     map[int64]*fakeNote
+	media     map[string]string
+	nextID    int64
+	actions   []string
+	failNotes bool // addNotes reports null ids
+}
+
+type fakeModel struct {
+	fields    []string
+	templates map[string]map[string]string
+	css       string
+}
+
+type fakeNote struct {
+	deck, model string
+	fields      map[string]string
+	tags        []string
+}
+
+// fakeQueryRe parses the `"note:…" "deck:…"` query sync sends.
+var fakeQueryRe = regexp.MustCompile(`^"note:(.*)" "deck:(.*)"$`)
+
+func newFakeAnki(t *testing.T) (*fakeAnki, *ankiConnect) {
+	fake := &fakeAnki{decks: []string{"Default"}, models: map[string]*fakeModel{}, notes: map[int64]*fakeNote{}, media: map[string]string{}, nextID: 1}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Action  string          `json:"action"`
+			Version int             `json:"version"`
+			Params  json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Version != ankiConnectVersion {
+			t.Errorf("bad request: %v (version %d)", err, request.Version)
+		}
+		fake.actions = append(fake.actions, request.Action)
+		result, errMsg := fake.handle(t, request.Action, request.Params)
+		reply := map[string]any{"result": result, "error": nil}
+		if errMsg != "" {
+			reply["error"] = errMsg
+		}
+		json.NewEncoder(w).Encode(reply)
+	}))
+	t.Cleanup(server.Close)
+	return fake, newAnkiConnect(server.URL, "")
+}
+
+func (f *fakeAnki) handle(t *testing.T, action string, raw json.RawMessage) (any, string) {
+	var p struct {
+		Deck          string              `json:"deck"`
+		ModelName     string              `json:"modelName"`
+		InOrderFields []string            `json:"inOrderFields"`
+		CSS           string              `json:"css"`
+		CardTemplates []map[string]string `json:"cardTemplates"`
+		FieldName     string              `json:"fieldName"`
+		Template      map[string]string   `json:"template"`
+		Model         struct {
+			Name      string                       `json:"name"`
+			CSS       string                       `json:"css"`
+			Templates map[string]map[string]string `json:"templates"`
+		} `json:"model"`
+		Filename string          `json:"filename"`
+		Data     string          `json:"data"`
+		Query    string          `json:"query"`
+		Notes    json.RawMessage `json:"notes"`
+		Note     struct {
+			ID     int64             `json:"id"`
+			Fields map[string]string `json:"fields"`
+			Tags   []string          `json:"tags"`
+		} `json:"note"`
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &p); err != nil {
+			t.Fatalf("%s params: %v", action, err)
+		}
+	}
+
+	switch action {
+	case "deckNames":
+		return f.decks, ""
+	case "createDeck":
+		f.decks = append(f.decks, p.Deck)
+		return 1, ""
+	case "modelNames":
+		var names []string
+		for name := range f.models {
+			names = append(names, name)
+		}
+		return names, ""
+	case "createModel":
+		m := &fakeModel{fields: p.InOrderFields, css: p.CSS, templates: map[string]map[string]string{}}
+		for _, tmpl := range p.CardTemplates {
+			m.templates[tmpl["Name"]] = map[string]string{"Front": tmpl["Front"], "Back": tmpl["Back"]}
+		}
+		f.models[p.ModelName] = m
+		return map[string]any{}, ""
+	case "modelFieldNames":
+		return f.models[p.ModelName].fields, ""
+	case "modelFieldAdd":
+		m := f.models[p.ModelName]
+		m.fields = append(m.fields, p.FieldName)
+		return nil, ""
+	case "modelTemplates":
+		return f.models[p.ModelName].templates, ""
+	case "modelTemplateAdd":
+		f.models[p.ModelName].templates[p.Template["Name"]] = map[string]string{"Front": p.Template["Front"], "Back": p.Template["Back"]}
+		return nil, ""
+	case "updateModelTemplates":
+		for name, tmpl := range p.Model.Templates {
+			f.models[p.Model.Name].templates[name] = tmpl
+		}
+		return nil, ""
+	case "modelStyling":
+		return map[string]string{"css": f.models[p.ModelName].css}, ""
+	case "updateModelStyling":
+		f.models[p.Model.Name].css = p.Model.CSS
+		return nil, ""
+	case "storeMediaFile":
+		f.media[p.Filename] = p.Data
+		return p.Filename, ""
+	case "findNotes":
+		m := fakeQueryRe.FindStringSubmatch(p.Query)
+		if m == nil {
+			return nil, "bad query " + p.Query
+		}
+		ids := []int64{}
+		for id, note := range f.notes {
+			if note.model == m[1] && note.deck == m[2] {
+				ids = append(ids, id)
+			}
+		}
+		slices.Sort(ids)
+		return ids, ""
+	case "notesInfo":
+		var ids []int64
+		json.Unmarshal(p.Notes, &ids)
+		var infos []map[string]any
+		for _, id := range ids {
+			note := f.notes[id]
+			fields := map[string]any{}
+			for i, name := range f.models[note.model].fields {
+				fields[name] = map[string]any{"value": note.fields[name], "order": i}
+			}
+			infos = append(infos, map[string]any{"noteId": id, "modelName": note.model, "tags": note.tags, "fields": fields})
+		}
+		return infos, ""
+	case "addNotes":
+		var notes []ankiConnectNewNote
+		json.Unmarshal(p.Notes, &notes)
+		var ids []*int64
+		for _, note := range notes {
+			if f.failNotes {
+				ids = append(ids, nil)
+				continue
+			}
+			id := f.nextID
+			f.nextID++
+			f.notes[id] = &fakeNote{deck: note.DeckName, model: note.ModelName, fields: note.Fields, tags: note.Tags}
+			ids = append(ids, &id)
+		}
+		return ids, ""
+	case "updateNote":
+		note, ok := f.notes[p.Note.ID]
+		if !ok {
+			return nil, "note was not found"
+		}
+		note.fields, note.tags = p.Note.Fields, p.Note.Tags
+		return nil, ""
+	case "deleteNotes":
+		var ids []int64
+		json.Unmarshal(p.Notes, &ids)
+		for _, id := range ids {
+			delete(f.notes, id)
+		}
+		return nil, ""
+	}
+	return nil, "unsupported action " + action
+}
+
+// TestSyncProject: a first sync creates the deck, note type and notes; a
+// second sync after editing the data updates changed notes, adds new ones,
+// and deletes the removed one only with deleteMissing.
+func TestSyncProject(t *testing.T) {
+	fake, client := newFakeAnki(t)
+	projectFile := writeTestProject(t, testProjectYAML, map[string]string{
+		"words.tsv": "Front\tBack\nkot\tcat\npies\tdog\nmysz\tmouse\n",
+	})
+	sync := func(deleteMissing bool) *syncResult {
+		t.Helper()
+		project, err := ReadProject(projectFile)
+		if err != nil {
+			t.Fatalf("ReadProject: %v", err)
+		}
+		notes, err := ReadNotes(project)
+		if err != nil {
+			t.Fatalf("ReadNotes: %v", err)
+		}
+		result, err := syncProject(client, project, notes, deleteMissing)
+		if err != nil {
+			t.Fatalf("syncProject: %v", err)
+		}
+		return result
+	}
+
+	result := sync(false)
+	if !result.DeckCreated || !result.ModelCreated || result.Added != 3 {
+		t.Errorf("first sync = %+v, want deck and model created, 3 added", result)
+	}
+	model := fake.models["Test Model"]
+	if model == nil || strings.Join(model.fields, ",") != "Front,Back" || model.templates["Card 1"]["Front"] != "{{Front}}" {
+		t.Fatalf("model = %+v", model)
+	}
+
+	result = sync(false)
+	if result.DeckCreated || result.ModelCreated || result.ModelUpdated || result.Added != 0 || result.Updated != 0 || result.Unchanged != 3 {
+		t.Errorf("repeat sync = %+v, want everything unchanged", result)
+	}
+
+	for name, content := range map[string]string{
+		"words.tsv":  "Front\tBack\nkot\tcat (feline)\npies\tdog\nkoń\thorse\n",
+		"front.html": "<b>{{Front}}</b>",
+	} {
+		if err := os.WriteFile(filepath.Join(filepath.Dir(projectFile), name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result = sync(false)
+	if !result.ModelUpdated || result.Added != 1 || result.Updated != 1 || result.Unchanged != 1 || result.Missing != 1 || result.Deleted != 0 {
+		t.Errorf("sync after edit = %+v, want template updated, 1 added, 1 updated, 1 unchanged, 1 missing", result)
+	}
+	if model.templates["Card 1"]["Front"] != "<b>{{Front}}</b>" {
+		t.Errorf("template not updated: %v", model.templates["Card 1"])
+	}
+	if len(fake.notes) != 4 {
+		t.Errorf("anki has %d notes, want 4 (mysz kept)", len(fake.notes))
+	}
+
+	result = sync(true)
+	if result.Deleted != 1 || len(fake.notes) != 3 {
+		t.Errorf("sync --delete = %+v with %d notes, want 1 deleted, 3 left", result, len(fake.notes))
+	}
+	for _, note := range fake.notes {
+		if note.fields["Front"] == "kot" && note.fields["Back"] != "cat (feline)" {
+			t.Errorf("kot = %v, want the updated Back", note.fields)
+		}
+		if note.deck != "Test Deck" || strings.Join(note.tags, " ") != "lesson1" {
+			t.Errorf("note %v in deck %q with tags %v", note.fields, note.deck, note.tags)
+		}
+	}
+}
+
+// TestSyncProjectErrors: an AnkiConnect error and a note Anki refuses to add
+// both fail the sync.
+func TestSyncProjectErrors(t *testing.T) {
+	fake, client := newFakeAnki(t)
+	project, err := ReadProject(writeTestProject(t, testProjectYAML, nil))
+	if err != nil {
+		t.Fatalf("ReadProject: %v", err)
+	}
+	notes, err := ReadNotes(project)
+	if err != nil {
+		t.Fatalf("ReadNotes: %v", err)
+	}
+
+	fake.failNotes = true
+	if _, err := syncProject(client, project, notes, false); err == nil || !strings.Contains(err.Error(), "words.tsv:2") {
+		t.Errorf("err = %v, want the refused note's position", err)
+	}
+
+	if err := client.invoke("noSuchAction", nil, nil); err == nil || !strings.Contains(err.Error(), "unsupported action") {
+		t.Errorf("invoke error = %v, want AnkiConnect's error", err)
+	}
+}
+
+// TestAnkiSearchTerm: quotes and wildcards in names are escaped.
+func TestAnkiSearchTerm(t *testing.T) {
+	if got, want := ankiSearchTerm("deck", `My "best"_deck*`), `"deck:My \"best\"\_deck\*"`; got != want {
+		t.Errorf("ankiSearchTerm = %s, want %s", got, want)
+	}
+}
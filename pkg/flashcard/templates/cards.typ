@@ -0,0 +1,99 @@
+// Printable cut-out flashcards. `flashcard-cli build -f pdf` appends one
+// #cards(...) call to this file; every argument below is its default.
+//
+// Each sheet is a pair of pages: the fronts of up to cols x rows cards on
+// the odd page, their backs on the following even page. The back page is
+// mirrored left-to-right, so after duplex printing (flip on the long edge)
+// every back lands behind its own front. The card grid is centred on the
+// page, which keeps the mirrored columns aligned with any symmetric margin.
+
+// _field typesets one field value with its own direction, size and font.
+#let _field(dir: ltr, size: none, font: none, body) = {
+  set text(dir: dir)
+  set text(size: size) if size != none
+  set text(font: font) if font != none
+  [#body]
+}
+
+// _marks draws crop marks in the margin around a cols x rows grid of w x h
+// cards whose top-left corner is at (x0, y0): one mark per cut line, on
+// both ends of it.
+#let _marks(cols, rows, w, h, x0, y0) = {
+  let gap = 2mm
+  let len = 5mm
+  let mark = 0.3pt + black
+  for k in range(cols + 1) {
+    let x = x0 + k * w
+    place(top + left, dx: x, dy: y0 - gap - len, line(angle: 90deg, length: len, stroke: mark))
+    place(top + left, dx: x, dy: y0 + rows * h + gap, line(angle: 90deg, length: len, stroke: mark))
+  }
+  for k in range(rows + 1) {
+    let y = y0 + k * h
+    place(top + left, dx: x0 - gap - len, dy: y, line(length: len, stroke: mark))
+    place(top + left, dx: x0 + cols * w + gap, dy: y, line(length: len, stroke: mark))
+  }
+}
+
+// _sheet places one page of card faces, row by row; mirror reverses the
+// column order for the back page.
+#let _sheet(faces, cols, rows, w, h, x0, y0, marks, mirror) = {
+  let outline = if marks == "outline" { 0.25pt + luma(160) } else { none }
+  for (i, face) in faces.enumerate() {
+    let row = calc.quo(i, cols)
+    let col = calc.rem(i, cols)
+    if mirror {
+      col = cols - 1 - col
+    }
+    place(
+      top + left,
+      dx: x0 + col * w,
+      dy: y0 + row * h,
+      box(
+        width: w,
+        height: h,
+        inset: 4mm,
+        stroke: outline,
+        clip: true,
+        align(center + horizon, stack(spacing: 0.8em, ..face)),
+      ),
+    )
+  }
+  if marks == "crop" {
+    _marks(cols, rows, w, h, x0, y0)
+  }
+}
+
+// cards lays out the card faces. cards is an array of (front: (..), back:
+// (..)) dictionaries, each face an array of _field(...) values; marks is
+// "crop", "outline" or "none".
+#let cards(
+  paper: "a4",
+  card-width: 74mm,
+  card-height: 52mm,
+  margin: 10mm,
+  marks: "crop",
+  size: 12pt,
+  cards: (),
+) = {
+  set page(paper: paper, margin: margin)
+  set text(size: size)
+  set par(justify: false)
+
+  context {
+    let area-width = page.width - 2 * margin
+    let area-height = page.height - 2 * margin
+    let cols = calc.max(1, calc.floor(area-width / card-width))
+    let rows = calc.max(1, calc.floor(area-height / card-height))
+    let x0 = (area-width - cols * card-width) / 2
+    let y0 = (area-height - rows * card-height) / 2
+
+    for (i, sheet) in cards.chunks(cols * rows).enumerate() {
+      if i > 0 {
+        pagebreak()
+      }
+      _sheet(sheet.map(card => card.front), cols, rows, card-width, card-height, x0, y0, marks, false)
+      pagebreak()
+      _sheet(sheet.map(card => card.back), cols, rows, card-width, card-height, x0, y0, marks, true)
+    }
+  }
+}
@@ -4,14 +4,12 @@ import (
 	gast "github.com/yuin/goldmark/ast"
 )
 
-// Node kinds for the custom block types. KindInterlinear is defined for
-// completeness but never registered with the converter (see
-// interlinear.go). KindText is the highest ordinal ever registered by this
-// package; ALL THREE renderers (HTML, Typst, MDX) MUST register a
-// NodeRendererFunc for EVERY kind through KindText, or a document
-// containing a block whose kind exceeds the registered maximum panics
-// (index out of range) — see the identical warning on typstNodeRenderer/
-// mdxNodeRenderer and SPECS ASR-1.
+// Node kinds for the custom block types. KindText is the highest ordinal
+// ever registered by this package; ALL FOUR renderers (HTML, Typst, MDX,
+// DOCX) MUST register a NodeRendererFunc for EVERY kind through KindText,
+// or a document containing a block whose kind exceeds the registered
+// maximum panics (index out of range) — see the identical warning on
+// typstNodeRenderer/mdxNodeRenderer/docxNodeRenderer and SPECS ASR-1.
 var (
 	KindVocabulary     = gast.NewNodeKind("Vocabulary")
 	KindDialog         = gast.NewNodeKind("Dialog")
@@ -36,13 +34,15 @@ const (
 )
 
 // BlockAnnotation carries the discriminated-union fields added to every
-// structured-block item type. Embedding it in an item struct promotes Kind,
-// Level, and Text with zero values (ItemData, 0, "") that leave all
-// pre-existing struct literals unchanged (ASR-3).
+// structured-block item type, plus the item's source line. Embedding it in
+// an item struct promotes Kind, Level, Text and Line with zero values
+// (ItemData, 0, "", 0) that leave all pre-existing struct literals
+// unchanged (ASR-3).
 type BlockAnnotation struct {
 	Kind  ItemKind
 	Level int    // 1-6 for ItemHeader; 0 otherwise
 	Text  string // heading/note text; "" for ItemData
+	Line  int    // 1-based source line the item starts at (a turn: its header)
 }
 
 // VocabularyItem is one parsed `{start-vocabulary}` line: a phrase plus its
@@ -63,6 +63,7 @@ type VocabularyItem struct {
 type Vocabulary struct {
 	gast.BaseBlock
 
+	Line         int // 1-based source line of the {start-…} marker
 	Lang, Script string
 	Err          error
 	Items        []VocabularyItem
@@ -104,6 +105,7 @@ type DialogItem struct {
 type Dialog struct {
 	gast.BaseBlock
 
+	Line             int // 1-based source line of the {start-…} marker
 	Lang, Script, As string
 	Items            []DialogItem
 	Err              error
@@ -126,6 +128,7 @@ func (n *Dialog) Dump(source []byte, level int) {
 // (stacked under the source in the primary column). All are converted
 // recursively at render time.
 type ParallelRow struct {
+	Line             int    // 1-based source line the row starts at
 	SourceRaw        string // field 1 — marker lang/script (was: MainRaw)
 	TranslationRaw   string // field 2 — book language      (was: SecondaryRaw)
 	TranscriptionRaw string // field 3 — pinned Latin/LTR romanization (NEW)
@@ -138,6 +141,7 @@ type ParallelRow struct {
 type Parallel struct {
 	gast.BaseBlock
 
+	Line         int // 1-based source line of the {start-…} marker
 	Lang, Script string
 	Err          error
 	Rows         []ParallelRow
@@ -154,6 +158,52 @@ func (n *Parallel) Dump(source []byte, level int) {
 	gast.DumpHelper(n, source, level, nil, nil)
 }
 
+// InterlinearWord is one aligned column of a {start-interlinear} example:
+// the source-line token with the transliteration and gloss tokens at the
+// same position. Transliteration is "" when the example has no
+// transliteration line.
+type InterlinearWord struct {
+	Source          string
+	Transliteration string
+	Gloss           string
+}
+
+// InterlinearItem is one example of a {start-interlinear} block, in Leipzig
+// layout: the word columns (source over optional transliteration over
+// morpheme gloss) and the free translation below them.
+type InterlinearItem struct {
+	BlockAnnotation
+	Words       []InterlinearWord
+	Translation string
+}
+
+// Interlinear is the block node for a `{start-interlinear}` ...
+// `{end-interlinear}` block (word-by-word glossed text). Lang and Script are
+// populated from marker attributes and apply to the source line only; the
+// transliteration, gloss and translation lines are pinned LTR. Err is set
+// when marker attributes are malformed or an example's lines do not have
+// the same number of tokens, and is surfaced at render time, mirroring
+// Dialog.Err.
+type Interlinear struct {
+	gast.BaseBlock
+
+	Line         int // 1-based source line of the {start-…} marker
+	Lang, Script string
+	Err          error
+	Items        []InterlinearItem
+}
+
+// Kind implements ast.Node.
+func (n *Interlinear) Kind() gast.NodeKind { return KindInterlinear }
+
+// IsRaw marks the block as raw; see Vocabulary.IsRaw.
+func (n *Interlinear) IsRaw() bool { return true }
+
+// Dump implements ast.Node.
+func (n *Interlinear) Dump(source []byte, level int) {
+	gast.DumpHelper(n, source, level, nil, nil)
+}
+
 // ParallelDialogItem is one field of a {start-parallel-dialog} row: either a
 // dialog turn (Header+Content, same grammar as DialogItem) or a title
 // (BlockAnnotation.Kind==ItemHeader). Exactly one item per present field —
@@ -171,6 +221,7 @@ type ParallelDialogItem struct {
 // the identical turn/heading grammar — Transcription differs only in which
 // font it renders with (pinned Latin/LTR), not in how it is authored.
 type ParallelDialogRow struct {
+	Line             int // 1-based source line the row starts at
 	Source           ParallelDialogItem
 	Translation      ParallelDialogItem
 	Transcription    ParallelDialogItem
@@ -186,6 +237,7 @@ type ParallelDialogRow struct {
 type ParallelDialog struct {
 	gast.BaseBlock
 
+	Line         int // 1-based source line of the {start-…} marker
 	Lang, Script string
 	Err          error
 	Rows         []ParallelDialogRow
@@ -219,6 +271,7 @@ type ModelsItem struct {
 type Models struct {
 	gast.BaseBlock
 
+	Line         int // 1-based source line of the {start-…} marker
 	Lang, Script string
 	Err          error
 	Items        []ModelsItem
@@ -252,6 +305,7 @@ type QuestionItem struct {
 type Questions struct {
 	gast.BaseBlock
 
+	Line             int // 1-based source line of the {start-…} marker
 	Lang, Script, As string
 	Err              error
 	Items            []QuestionItem
@@ -279,8 +333,10 @@ func (n *Questions) Dump(source []byte, level int) {
 type Text struct {
 	gast.BaseBlock
 
+	Line                     int // 1-based source line of the {start-…} marker
 	As, Lang, Script, System string
 	Raw                      string
+	RawLine                  int // 1-based source line Raw starts at
 	Err                      error
 }
 
@@ -133,3 +133,31 @@ func blockDirection(script string) string {
 	}
 	return "ltr"
 }
+
+// iso15924 lists the ISO 15924 script codes, lowercase as block markers
+// write them. A script= value outside it still renders (LTR fallback, OI-6);
+// only Lint reports it, since it is almost always a typo ("arb" for "arab")
+// that silently loses the block's direction and font role.
+var iso15924 = func() map[string]bool {
+	codes := map[string]bool{}
+	for _, code := range strings.Fields(`
+		adlm afak aghb ahom arab aran armi armn avst bali bamu bass batk beng
+		bhks blis bopo brah brai bugi buhd cakm cans cari cham cher chis chrs
+		cirt copt cpmn cprt cyrl cyrs deva diak dogr dsrt dupl egyd egyh egyp
+		elba elym ethi gara geok geor glag gong gonm goth gran grek gujr gukh
+		guru hanb hang hani hano hans hant hatr hebr hira hluw hmng hmnp hrkt
+		hung inds ital jamo java jpan jurc kali kana kawi khar khmr khoj kitl
+		kits knda kore kpel krai kthi lana laoo latf latg latn leke lepc limb
+		lina linb lisu loma lyci lydi mahj maka mand mani marc maya medf mend
+		merc mero mlym modi mong moon mroo mtei mult mymr nagm nand narb nbat
+		newa nkdb nkgb nkoo nshu ogam olck onao orkh orya osge osma ougr palm
+		pauc pcun pelm perm phag phli phlp phlv phnx piqd plrd prti psin ranj
+		rjng rohg roro runr samr sara sarb saur sgnw shaw shrd shui sidd sidt
+		sind sinh sogd sogo sora soyo sund sunu sylo syrc syre syrj syrn tagb
+		takr tale talu taml tang tavt tayo telu teng tfng tglg thaa thai tibt
+		tirh tnsa todr tols toto tutg ugar vaii visp vith wara wcho wole xpeo
+		xsux yezi yiii zanb zinh zmth zsye zsym zxxx zyyy zzzz`) {
+		codes[code] = true
+	}
+	return codes
+}()
@@ -1,21 +1,22 @@
 // Package markdown converts DPurge project markdown into HTML with goldmark.
 //
 // Besides CommonMark (plus tables, strikethrough, autolinks, definition
-// lists and typographer substitutions), it understands six project-specific
+// lists and typographer substitutions), it understands project-specific
 // block extensions, each delimited by start/end markers that must appear on
 // their own lines:
 //
-//	{start-vocabulary [lang=… script=…]} ... {end-vocabulary}
-//	{start-dialog     [lang=… script=…]} ... {end-dialog}
-//	{start-parallel   [lang=… script=…]} ... {end-parallel}
-//	{start-models     [lang=… script=…]} ... {end-models}
-//	{start-questions  [lang=… script=…]} ... {end-questions}
+//	{start-vocabulary      [lang=… script=…]} ... {end-vocabulary}
+//	{start-dialog          [lang=… script=…]} ... {end-dialog}
+//	{start-parallel        [lang=… script=…]} ... {end-parallel}
+//	{start-parallel-dialog [lang=… script=…]} ... {end-parallel-dialog}
+//	{start-interlinear     [lang=… script=…]} ... {end-interlinear}
+//	{start-models          [lang=… script=…]} ... {end-models}
+//	{start-questions       [lang=… script=…]} ... {end-questions}
 //	{start-text as=… [lang=… script=… system=…]} ... {end-text}
 //
 // Parsing (parser.go) captures raw text/structure into nodes (ast.go);
 // rendering (renderer.go) emits HTML, recursively invoking ToHTML to
-// render dialog/parallel/text cell content. See interlinear.go for a
-// seventh, inactive block type.
+// render dialog/parallel/text cell content.
 package markdown
 
 import (
@@ -23,9 +24,11 @@ import (
 	"os"
 
 	"github.com/yuin/goldmark"
+	gast "github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
 	"github.com/yuin/goldmark/util"
 )
 
@@ -33,7 +36,10 @@ import (
 // recursive rendering of dialog/parallel cell content. Reuse across those
 // recursive calls is safe because rendering only ever recurses after the
 // outer document's parse phase has fully completed (parse-then-render, not
-// interleaved).
+// interleaved). It is equally safe to share across goroutines: goldmark
+// initialises its parser and renderer once behind a sync.Once, each parse
+// gets its own parser.Context, and this package's block parsers, AST
+// transformer and node renderers hold no state (TestConverters_Concurrent).
 var md = goldmark.New(
 	goldmark.WithExtensions(
 		extension.Table,
@@ -45,6 +51,7 @@ var md = goldmark.New(
 		dialogExtender,
 		parallelExtender,
 		parallelDialogExtender,
+		interlinearExtender,
 		modelsExtender,
 		questionsExtender,
 		textExtender,
@@ -69,7 +76,8 @@ func normalizeNewlines(source []byte) []byte {
 	return bytes.ReplaceAll(source, []byte("\r"), []byte("\n"))
 }
 
-// ToHTML converts markdown source into HTML.
+// ToHTML converts markdown source into HTML. A malformed block fails the
+// conversion with a *SourceError at the block's (or item's) line.
 func ToHTML(source []byte) ([]byte, error) {
 	source = normalizeNewlines(source)
 	var buf bytes.Buffer
@@ -79,7 +87,44 @@ func ToHTML(source []byte) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-// FileToHTML reads filename and converts its content into HTML.
+// Parse parses markdown source into its goldmark document without rendering
+// it, for callers that read the custom block nodes (Vocabulary, Models, ...)
+// directly. Block nodes carry their parsed Items, so the returned tree needs
+// no access to source; a block whose markers were malformed keeps its Err
+// for the caller to report.
+func Parse(source []byte) gast.Node {
+	return md.Parser().Parse(text.NewReader(normalizeNewlines(source)))
+}
+
+// WalkBlocks parses source and calls fn on every custom block node
+// (Vocabulary, Models, Text, ...) in document order, including the blocks in
+// a {start-text} body, which the parser keeps as raw markdown (Text.Raw)
+// rather than as child nodes. The lines on a nested block's node count from
+// the start of that body (Text.RawLine). An error from fn stops the walk; it
+// is returned positioned in source, a nested block's line shifted onto the
+// file's.
+func WalkBlocks(source []byte, fn func(block gast.Node) error) error {
+	return gast.Walk(Parse(source), func(n gast.Node, entering bool) (gast.WalkStatus, error) {
+		if !entering {
+			return gast.WalkContinue, nil
+		}
+		if _, _, _, _, ok := blockInfo(n); !ok {
+			return gast.WalkContinue, nil
+		}
+		if err := fn(n); err != nil {
+			return gast.WalkStop, err
+		}
+		if t, ok := n.(*Text); ok && t.Raw != "" {
+			if err := WalkBlocks([]byte(t.Raw), fn); err != nil {
+				return gast.WalkStop, offsetError(t.RawLine, err)
+			}
+		}
+		return gast.WalkSkipChildren, nil
+	})
+}
+
+// FileToHTML reads filename and converts its content into HTML. A
+// conversion error names filename and the line (InFile).
 func FileToHTML(filename string) (string, error) {
 	source, err := os.ReadFile(filename)
 	if err != nil {
@@ -87,7 +132,7 @@ func FileToHTML(filename string) (string, error) {
 	}
 	body, err := ToHTML(source)
 	if err != nil {
-		return "", err
+		return "", InFile(filename, err)
 	}
 	return string(body), nil
 }
@@ -242,7 +242,7 @@ func TestParseDialogMalformedStillErrors(t *testing.T) {
 	if err == nil {
 		t.Fatalf("ToHTML() expected an indentation error for a malformed dialog line, got nil")
 	}
-	wantErr := "Wrong line indentation for dialog item: Badly indented line"
+	wantErr := "line 3: Wrong line indentation for dialog item: Badly indented line"
 	if err.Error() != wantErr {
 		t.Fatalf("ToHTML() error = %q, want %q", err.Error(), wantErr)
 	}
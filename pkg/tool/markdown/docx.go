@@ -0,0 +1,56 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// newDocxRenderer builds a per-call DOCX renderer whose plain paragraphs
+// take the given direction ("rtl", "ltr", or "" to inherit the document's)
+// and paragraph style ("" for Normal). Like newMdxRenderer (mdx.go), it is
+// constructed fresh for every conversion: a block's nested markdown (a
+// dialog turn, a parallel cell, a text block's body) is rendered by its own
+// renderer carrying the block's direction and style, so docxNodeRenderer
+// itself never holds state across calls.
+func newDocxRenderer(dir, style string) renderer.Renderer {
+	return renderer.NewRenderer(renderer.WithNodeRenderers(
+		util.Prioritized(&docxNodeRenderer{dir: dir, style: style}, 100),
+	))
+}
+
+// ToDOCX converts markdown source into a WordprocessingML body fragment:
+// the <w:p> and <w:tbl> elements of the chapter, in order, to be placed
+// inside a document's <w:body> (the exporter writes the package around
+// them). It parses with the SAME parser instance ToHTML/ToTypst/ToMDX use
+// (md.Parser(), converter.go), so the AST is identical; only the renderer
+// differs (docx_render.go).
+//
+// The fragment refers to the paragraph, character and table styles listed
+// on docxNodeRenderer, which the document's styles part must define, and
+// to nothing else: links are HYPERLINK fields rather than relationships,
+// list items carry their own labels rather than a numbering definition, and
+// images are replaced by their alt text — so fragments converted separately
+// (and cached) can be concatenated in any number.
+//
+// Paragraphs outside custom blocks inherit the document's direction; each
+// custom block sets its own from its script (w:bidi on paragraphs, w:rtl
+// on runs, w:bidiVisual on tables), with transcriptions, glosses and
+// translations pinned left-to-right as in the other renderers.
+func ToDOCX(source []byte) ([]byte, error) {
+	return toDOCX(source, "", "")
+}
+
+// toDOCX is ToDOCX with the direction and style of plain paragraphs set, for
+// a block's nested markdown (newDocxRenderer).
+func toDOCX(source []byte, dir, style string) ([]byte, error) {
+	source = normalizeNewlines(source)
+	doc := md.Parser().Parse(text.NewReader(source))
+	var buf bytes.Buffer
+	if err := newDocxRenderer(dir, style).Render(&buf, source, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,36 @@
+package markdown
+
+import (
+	"strings"
+
+	"github.com/dpurge/cli-tools/pkg/tool"
+)
+
+// docxRunContent returns s as the content of a <w:r>: <w:t> text, with
+// every tab as a <w:tab/> and every newline as a <w:br/>, since Word does
+// not honour either inside <w:t>.
+func docxRunContent(s string) string {
+	var b strings.Builder
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			b.WriteString("<w:br/>")
+		}
+		for j, part := range strings.Split(line, "\t") {
+			if j > 0 {
+				b.WriteString("<w:tab/>")
+			}
+			if part != "" {
+				b.WriteString(`<w:t xml:space="preserve">`)
+				b.WriteString(tool.XMLEscape(part))
+				b.WriteString("</w:t>")
+			}
+		}
+	}
+	return b.String()
+}
+
+// docxFieldURL quotes url for a HYPERLINK field instruction, in which a
+// double quote would end the argument.
+func docxFieldURL(url string) string {
+	return `"` + strings.ReplaceAll(url, `"`, "%22") + `"`
+}
@@ -0,0 +1,913 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dpurge/cli-tools/pkg/tool"
+	gast "github.com/yuin/goldmark/ast"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// docxTextWidth is the width of the text area, in twips, that table grids
+// are divided over: an A4 page with 1-inch margins, as the ebook exporter
+// lays it out. Word and LibreOffice only take it as a hint — every table is
+// set to the full text width.
+const docxTextWidth = 9026
+
+// docxNodeRenderer registers a DOCX NodeRendererFunc for every node kind
+// md's parser can produce (the same set as typstNodeRenderer, and for the
+// same reason: a kind above the highest registered one panics, see
+// typst.go). Block nodes write whole <w:p>/<w:tbl> elements, inline nodes
+// write <w:r> runs into the paragraph their block opened; a run's
+// formatting is read off its ancestors (runProps), so inline wrappers such
+// as Emphasis write nothing themselves. RawHTML and HTMLBlock are left
+// unregistered and so emit nothing, as in the Typst renderer.
+//
+// The styles it refers to, which the document must define, are the
+// paragraph styles Heading1-Heading6, Quote, SourceCode, ListParagraph,
+// BlockHeading, BlockNote, DialogSpeaker and DialogText, the character
+// styles Hyperlink and VerbatimChar, and the table styles BlockTable
+// (bordered) and PlainTable (interlinear examples).
+//
+// dir and style apply to plain paragraphs (newDocxRenderer, docx.go).
+type docxNodeRenderer struct {
+	dir, style string
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.
+func (r *docxNodeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(gast.KindDocument, renderPassThroughDocx)
+	reg.Register(gast.KindHeading, r.renderHeading)
+	reg.Register(gast.KindParagraph, r.renderParagraph)
+	reg.Register(gast.KindTextBlock, r.renderParagraph)
+	reg.Register(gast.KindText, r.renderText)
+	reg.Register(gast.KindString, r.renderString)
+	reg.Register(gast.KindEmphasis, renderPassThroughDocx)
+	reg.Register(gast.KindCodeSpan, r.renderCodeSpan)
+	reg.Register(gast.KindLink, r.renderLink)
+	reg.Register(gast.KindAutoLink, r.renderAutoLink)
+	reg.Register(gast.KindImage, r.renderImage)
+	reg.Register(gast.KindList, renderPassThroughDocx)
+	reg.Register(gast.KindListItem, r.renderListItem)
+	reg.Register(gast.KindBlockquote, renderPassThroughDocx)
+	reg.Register(gast.KindFencedCodeBlock, renderCodeBlockDocx)
+	reg.Register(gast.KindCodeBlock, renderCodeBlockDocx)
+	reg.Register(gast.KindThematicBreak, renderThematicBreakDocx)
+
+	reg.Register(extast.KindTable, r.renderTable)
+	reg.Register(extast.KindTableHeader, renderTableRowDocx)
+	reg.Register(extast.KindTableRow, renderTableRowDocx)
+	reg.Register(extast.KindTableCell, r.renderTableCell)
+	reg.Register(extast.KindStrikethrough, renderPassThroughDocx)
+	reg.Register(extast.KindDefinitionList, renderPassThroughDocx)
+	reg.Register(extast.KindDefinitionTerm, r.renderDefinitionTerm)
+	reg.Register(extast.KindDefinitionDescription, renderPassThroughDocx)
+
+	reg.Register(KindVocabulary, r.renderVocabulary)
+	reg.Register(KindDialog, r.renderDialog)
+	reg.Register(KindParallel, r.renderParallel)
+	reg.Register(KindModels, r.renderModels)
+	reg.Register(KindQuestions, r.renderQuestions)
+	reg.Register(KindParallelDialog, r.renderParallelDialog)
+	reg.Register(KindInterlinear, r.renderInterlinear)
+	// KindText MUST be registered last (highest ordinal, see ast.go).
+	reg.Register(KindText, r.renderTextblock)
+}
+
+// docxParagraph is the paragraph properties of a <w:p>, written in the
+// element order the schema requires (Word rejects a document that breaks
+// it).
+type docxParagraph struct {
+	Style         string
+	KeepNext      bool
+	Rule          bool   // a bottom border: a thematic break
+	Dir           string // "rtl", "ltr", or "" to inherit the style's
+	Left, Hanging int    // indentation, in twips
+	Align         string // a w:jc value
+}
+
+// open returns the paragraph's opening <w:p> and properties.
+func (p docxParagraph) open() string {
+	var pr strings.Builder
+	if p.Style != "" {
+		fmt.Fprintf(&pr, `<w:pStyle w:val="%s"/>`, p.Style)
+	}
+	if p.KeepNext {
+		pr.WriteString("<w:keepNext/>")
+	}
+	if p.Rule {
+		pr.WriteString(`<w:pBdr><w:bottom w:val="single" w:sz="6" w:space="1" w:color="auto"/></w:pBdr>`)
+	}
+	switch p.Dir {
+	case "rtl":
+		pr.WriteString("<w:bidi/>")
+	case "ltr":
+		pr.WriteString(`<w:bidi w:val="0"/>`)
+	}
+	switch {
+	case p.Hanging > 0:
+		fmt.Fprintf(&pr, `<w:ind w:left="%d" w:hanging="%d"/>`, p.Left, p.Hanging)
+	case p.Left > 0:
+		fmt.Fprintf(&pr, `<w:ind w:left="%d"/>`, p.Left)
+	}
+	if p.Align != "" {
+		fmt.Fprintf(&pr, `<w:jc w:val="%s"/>`, p.Align)
+	}
+	if pr.Len() == 0 {
+		return "<w:p>"
+	}
+	return "<w:p><w:pPr>" + pr.String() + "</w:pPr>"
+}
+
+// text returns the whole paragraph holding one run of s.
+func (p docxParagraph) text(run docxRun, s string) string {
+	return p.open() + run.text(s) + "</w:p>"
+}
+
+// docxRun is the run properties of a <w:r>, in schema order.
+type docxRun struct {
+	Style                                string
+	Bold, Italic, SmallCaps, Strike, RTL bool
+}
+
+// text returns s as a run, or "" for an empty s.
+func (run docxRun) text(s string) string {
+	if s == "" {
+		return ""
+	}
+	var pr strings.Builder
+	if run.Style != "" {
+		fmt.Fprintf(&pr, `<w:rStyle w:val="%s"/>`, run.Style)
+	}
+	if run.Bold {
+		pr.WriteString("<w:b/><w:bCs/>")
+	}
+	if run.Italic {
+		pr.WriteString("<w:i/><w:iCs/>")
+	}
+	if run.SmallCaps {
+		pr.WriteString("<w:smallCaps/>")
+	}
+	if run.Strike {
+		pr.WriteString("<w:strike/>")
+	}
+	if run.RTL {
+		pr.WriteString("<w:rtl/>")
+	}
+	if pr.Len() == 0 {
+		return "<w:r>" + docxRunContent(s) + "</w:r>"
+	}
+	return "<w:r><w:rPr>" + pr.String() + "</w:rPr>" + docxRunContent(s) + "</w:r>"
+}
+
+// runProps returns the formatting of an inline node's runs, from the
+// emphasis, strikethrough, link, table header and definition term around
+// it, in the renderer's direction.
+func (r *docxNodeRenderer) runProps(node gast.Node) docxRun {
+	run := docxRun{RTL: r.dir == "rtl"}
+	for a := node.Parent(); a != nil; a = a.Parent() {
+		switch a := a.(type) {
+		case *gast.Emphasis:
+			if a.Level == 2 {
+				run.Bold = true
+			} else {
+				run.Italic = true
+			}
+		case *extast.Strikethrough:
+			run.Strike = true
+		case *gast.Link:
+			run.Style = "Hyperlink"
+		case *extast.TableHeader, *extast.DefinitionTerm:
+			run.Bold = true
+		}
+	}
+	return run
+}
+
+// renderPassThroughDocx is for container nodes whose children write all of
+// the output (Document, List, Blockquote, ...) and inline wrappers that
+// runProps reads off the tree (Emphasis, Strikethrough).
+func renderPassThroughDocx(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	return gast.WalkContinue, nil
+}
+
+// renderHeading writes a paragraph in the Heading1-Heading6 style.
+func (r *docxNodeRenderer) renderHeading(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if entering {
+		n := node.(*gast.Heading)
+		io.WriteString(w, docxParagraph{Style: fmt.Sprintf("Heading%d", n.Level), Dir: r.dir}.open())
+	} else {
+		io.WriteString(w, "</w:p>")
+	}
+	return gast.WalkContinue, nil
+}
+
+// renderParagraph writes a Paragraph or a tight list item's TextBlock. Its
+// style comes from the nearest list item, block quote or definition around
+// it: a list item's paragraphs are indented by nesting depth, and the
+// first one starts with the item's label (docxListLabel) and a tab, hung
+// into the indent; Word's own numbering would need one numbering
+// definition per list in the document, which a fragment cannot know.
+func (r *docxNodeRenderer) renderParagraph(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		io.WriteString(w, "</w:p>")
+		return gast.WalkContinue, nil
+	}
+
+	p := docxParagraph{Style: r.style, Dir: r.dir}
+	var item gast.Node
+	depth := 0
+	decided := false
+	for a := node.Parent(); a != nil; a = a.Parent() {
+		switch a.Kind() {
+		case gast.KindList:
+			depth++
+		case gast.KindListItem:
+			if !decided {
+				item = a
+				decided = true
+			}
+		case gast.KindBlockquote:
+			if !decided {
+				p.Style = "Quote"
+				decided = true
+			}
+		case extast.KindDefinitionDescription:
+			if !decided {
+				p.Left = 720
+				decided = true
+			}
+		}
+	}
+	if item == nil {
+		io.WriteString(w, p.open())
+		return gast.WalkContinue, nil
+	}
+
+	p.Style, p.Left = "ListParagraph", 720*depth
+	if item.FirstChild() != node {
+		io.WriteString(w, p.open())
+		return gast.WalkContinue, nil
+	}
+	p.Hanging = 360
+	io.WriteString(w, p.open())
+	r.writeListLabel(w, item, depth)
+	return gast.WalkContinue, nil
+}
+
+// renderListItem labels an item that does not start with a paragraph — an
+// empty item, or one opening with a code block or a nested list — with a
+// labelled empty paragraph of its own; renderParagraph labels the rest.
+func (r *docxNodeRenderer) renderListItem(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	if first := node.FirstChild(); first != nil && (first.Kind() == gast.KindParagraph || first.Kind() == gast.KindTextBlock) {
+		return gast.WalkContinue, nil
+	}
+	depth := 0
+	for a := node.Parent(); a != nil; a = a.Parent() {
+		if a.Kind() == gast.KindList {
+			depth++
+		}
+	}
+	io.WriteString(w, docxParagraph{Style: "ListParagraph", Left: 720 * depth, Hanging: 360, Dir: r.dir}.open())
+	r.writeListLabel(w, node, depth)
+	io.WriteString(w, "</w:p>")
+	return gast.WalkContinue, nil
+}
+
+// writeListLabel writes a list item's label and the tab after it.
+func (r *docxNodeRenderer) writeListLabel(w util.BufWriter, item gast.Node, depth int) {
+	run := docxRun{RTL: r.dir == "rtl"}
+	io.WriteString(w, run.text(docxListLabel(item, depth)))
+	io.WriteString(w, "<w:r><w:tab/></w:r>")
+}
+
+// docxListLabel returns a list item's label: its number and the list's
+// delimiter for an ordered list (counting from the list's start), or a
+// bullet that changes with the nesting depth.
+func docxListLabel(item gast.Node, depth int) string {
+	list := item.Parent().(*gast.List)
+	if !list.IsOrdered() {
+		return []string{"•", "◦", "▪"}[(depth-1)%3]
+	}
+	number := list.Start
+	for c := list.FirstChild(); c != nil && c != item; c = c.NextSibling() {
+		number++
+	}
+	return fmt.Sprintf("%d%c", number, list.Marker)
+}
+
+// renderDefinitionTerm writes a definition list term as a bold paragraph
+// kept with its description, which renderParagraph indents.
+func (r *docxNodeRenderer) renderDefinitionTerm(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if entering {
+		io.WriteString(w, docxParagraph{Style: r.style, KeepNext: true, Dir: r.dir}.open())
+	} else {
+		io.WriteString(w, "</w:p>")
+	}
+	return gast.WalkContinue, nil
+}
+
+// renderText writes the Text node's value as a run — after undoing its
+// backslash escapes, as goldmark's HTML writer does (see
+// unescapeMarkdownBackslash) — then its line break: a space for a soft
+// break, a <w:br/> for a hard one.
+func (r *docxNodeRenderer) renderText(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	n := node.(*gast.Text)
+	value := unescapeMarkdownBackslash(string(n.Value(source)))
+	if n.SoftLineBreak() && !n.HardLineBreak() {
+		value += " "
+	}
+	io.WriteString(w, r.runProps(node).text(value))
+	if n.HardLineBreak() {
+		io.WriteString(w, "<w:r><w:br/></w:r>")
+	}
+	return gast.WalkContinue, nil
+}
+
+// renderString writes a Typographer substitution as its Unicode character
+// (typographerEntities, typst_escape.go), and any other String as is.
+func (r *docxNodeRenderer) renderString(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	value := string(node.(*gast.String).Value)
+	if u, ok := typographerEntities[value]; ok {
+		value = u
+	}
+	io.WriteString(w, r.runProps(node).text(value))
+	return gast.WalkContinue, nil
+}
+
+// renderCodeSpan writes the span's text as one VerbatimChar run, reading
+// its Text children directly as renderCodeSpanTypst does (a trailing
+// newline becomes a space).
+func (r *docxNodeRenderer) renderCodeSpan(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	var buf bytes.Buffer
+	for c := node.FirstChild(); c != nil; c = c.NextSibling() {
+		value := c.(*gast.Text).Segment.Value(source)
+		if bytes.HasSuffix(value, []byte("\n")) {
+			buf.Write(value[:len(value)-1])
+			buf.WriteByte(' ')
+		} else {
+			buf.Write(value)
+		}
+	}
+	run := r.runProps(node)
+	run.Style = "VerbatimChar"
+	io.WriteString(w, run.text(buf.String()))
+	return gast.WalkSkipChildren, nil
+}
+
+// docxFieldStart and docxFieldEnd wrap a link's runs in a HYPERLINK field,
+// which needs no relationship part, so a fragment stays self-contained.
+func docxFieldStart(url string) string {
+	return `<w:r><w:fldChar w:fldCharType="begin"/></w:r>` +
+		`<w:r><w:instrText xml:space="preserve"> HYPERLINK ` + tool.XMLEscape(docxFieldURL(url)) + ` </w:instrText></w:r>` +
+		`<w:r><w:fldChar w:fldCharType="separate"/></w:r>`
+}
+
+const docxFieldEnd = `<w:r><w:fldChar w:fldCharType="end"/></w:r>`
+
+// renderLink wraps the link's runs, which runProps styles as Hyperlink, in
+// a HYPERLINK field; the title is dropped.
+func (r *docxNodeRenderer) renderLink(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if entering {
+		io.WriteString(w, docxFieldStart(string(node.(*gast.Link).Destination)))
+	} else {
+		io.WriteString(w, docxFieldEnd)
+	}
+	return gast.WalkContinue, nil
+}
+
+// renderAutoLink writes the label as a HYPERLINK field, adding "mailto:"
+// to an email address as renderAutoLinkTypst does.
+func (r *docxNodeRenderer) renderAutoLink(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	n := node.(*gast.AutoLink)
+	url := string(n.URL(source))
+	if n.AutoLinkType == gast.AutoLinkEmail && !strings.HasPrefix(strings.ToLower(url), "mailto:") {
+		url = "mailto:" + url
+	}
+	run := r.runProps(node)
+	run.Style = "Hyperlink"
+	io.WriteString(w, docxFieldStart(url))
+	io.WriteString(w, run.text(string(n.Label(source))))
+	io.WriteString(w, docxFieldEnd)
+	return gast.WalkContinue, nil
+}
+
+// renderImage writes the image's alt text in brackets: embedding the
+// picture would need a relationship and a media part per image, which a
+// fragment cannot carry.
+func (r *docxNodeRenderer) renderImage(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	if alt := string(node.Text(source)); alt != "" {
+		run := r.runProps(node)
+		run.Italic = true
+		io.WriteString(w, run.text("["+alt+"]"))
+	}
+	return gast.WalkSkipChildren, nil
+}
+
+// renderCodeBlockDocx writes a fenced or indented code block as one
+// left-to-right SourceCode paragraph, its lines separated by line breaks.
+func renderCodeBlockDocx(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	code := strings.TrimSuffix(string(node.Text(source)), "\n")
+	io.WriteString(w, docxParagraph{Style: "SourceCode", Dir: "ltr"}.text(docxRun{}, code))
+	return gast.WalkSkipChildren, nil
+}
+
+// renderThematicBreakDocx writes an empty paragraph with a bottom border.
+func renderThematicBreakDocx(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if entering {
+		io.WriteString(w, docxParagraph{Rule: true}.open()+"</w:p>")
+	}
+	return gast.WalkContinue, nil
+}
+
+// docxTableStart returns the opening of a full-width table of cols equal
+// columns in style ("BlockTable" or "PlainTable"); rtl lays the columns
+// out right to left.
+func docxTableStart(style string, cols int, rtl bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<w:tbl><w:tblPr><w:tblStyle w:val="%s"/>`, style)
+	if rtl {
+		b.WriteString("<w:bidiVisual/>")
+	}
+	b.WriteString(`<w:tblW w:w="5000" w:type="pct"/></w:tblPr><w:tblGrid>`)
+	for range cols {
+		fmt.Fprintf(&b, `<w:gridCol w:w="%d"/>`, docxTextWidth/cols)
+	}
+	b.WriteString("</w:tblGrid>")
+	return b.String()
+}
+
+// docxTableEnd closes a table and adds an empty paragraph after it: Word
+// merges tables with nothing between them into one, and requires a table
+// cell (and the document body) to end with a paragraph.
+const docxTableEnd = "</w:tbl><w:p/>"
+
+// docxCell returns a table cell spanning span of the table's cols columns
+// and holding content, a body fragment, which ends with a paragraph
+// unless it is empty: Word requires one in every cell.
+func docxCell(span, cols int, content string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<w:tc><w:tcPr><w:tcW w:w="%d" w:type="pct"/>`, 5000*span/cols)
+	if span > 1 {
+		fmt.Fprintf(&b, `<w:gridSpan w:val="%d"/>`, span)
+	}
+	b.WriteString("</w:tcPr>")
+	if content == "" {
+		content = "<w:p/>"
+	}
+	b.WriteString(content)
+	b.WriteString("</w:tc>")
+	return b.String()
+}
+
+// docxRow returns a table row of one cell per content.
+func docxRow(contents ...string) string {
+	var b strings.Builder
+	b.WriteString("<w:tr>")
+	for _, content := range contents {
+		b.WriteString(docxCell(1, len(contents), content))
+	}
+	b.WriteString("</w:tr>")
+	return b.String()
+}
+
+// docxSpanRow returns a table row of one cell spanning all cols columns.
+func docxSpanRow(cols int, content string) string {
+	return "<w:tr>" + docxCell(cols, cols, content) + "</w:tr>"
+}
+
+// docxBlockItem returns a block's heading or note item as a BlockHeading
+// or BlockNote paragraph in the block's direction.
+func docxBlockItem(kind ItemKind, text, dir string) string {
+	style := "BlockHeading"
+	if kind == ItemNote {
+		style = "BlockNote"
+	}
+	return docxParagraph{Style: style, KeepNext: kind == ItemHeader, Dir: dir}.text(docxRun{RTL: dir == "rtl"}, text)
+}
+
+// renderTableRowDocx writes a GFM table's header row (repeated on every
+// page) or body row.
+func renderTableRowDocx(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	switch {
+	case !entering:
+		io.WriteString(w, "</w:tr>")
+	case node.Kind() == extast.KindTableHeader:
+		io.WriteString(w, "<w:tr><w:trPr><w:tblHeader/></w:trPr>")
+	default:
+		io.WriteString(w, "<w:tr>")
+	}
+	return gast.WalkContinue, nil
+}
+
+// renderTable writes a GFM table as a BlockTable of its columns.
+func (r *docxNodeRenderer) renderTable(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if entering {
+		io.WriteString(w, docxTableStart("BlockTable", len(node.(*extast.Table).Alignments), r.dir == "rtl"))
+	} else {
+		io.WriteString(w, docxTableEnd)
+	}
+	return gast.WalkContinue, nil
+}
+
+// renderTableCell writes a GFM table cell, its inline content in one
+// paragraph aligned as the column's delimiter row says.
+func (r *docxNodeRenderer) renderTableCell(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		io.WriteString(w, "</w:p></w:tc>")
+		return gast.WalkContinue, nil
+	}
+	n := node.(*extast.TableCell)
+	cols := len(n.Parent().Parent().(*extast.Table).Alignments)
+	align := ""
+	switch n.Alignment {
+	case extast.AlignLeft:
+		align = "left"
+	case extast.AlignRight:
+		align = "right"
+	case extast.AlignCenter:
+		align = "center"
+	}
+	fmt.Fprintf(w, `<w:tc><w:tcPr><w:tcW w:w="%d" w:type="pct"/></w:tcPr>`, 5000/cols)
+	io.WriteString(w, docxParagraph{Dir: r.dir, Align: align}.open())
+	return gast.WalkContinue, nil
+}
+
+// renderVocabulary writes a BlockTable with a row per item: the phrase in
+// the block's direction, then the grammar tag, transcription and
+// translation, pinned left-to-right; a column none of the items uses is
+// left out. Headings span the whole row.
+func (r *docxNodeRenderer) renderVocabulary(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	n := node.(*Vocabulary)
+	if n.Err != nil {
+		return gast.WalkStop, n.Err
+	}
+	if len(n.Items) == 0 {
+		return gast.WalkContinue, nil
+	}
+
+	dir := blockDirection(n.Script)
+	var hasGrammar, hasTranscription, hasTranslation bool
+	for _, item := range n.Items {
+		hasGrammar = hasGrammar || item.Grammar != ""
+		hasTranscription = hasTranscription || item.Transcription != ""
+		hasTranslation = hasTranslation || item.Translation != ""
+	}
+	cols := 1
+	for _, has := range []bool{hasGrammar, hasTranscription, hasTranslation} {
+		if has {
+			cols++
+		}
+	}
+
+	phrase := docxParagraph{Dir: dir}
+	pinned := docxParagraph{Dir: "ltr"}
+	io.WriteString(w, docxTableStart("BlockTable", cols, dir == "rtl"))
+	for _, item := range n.Items {
+		if item.Kind != ItemData {
+			io.WriteString(w, docxSpanRow(cols, docxBlockItem(item.Kind, item.Text, dir)))
+			continue
+		}
+		cells := []string{phrase.text(docxRun{RTL: dir == "rtl"}, item.Phrase)}
+		if hasGrammar {
+			cells = append(cells, pinned.text(docxRun{Italic: true}, item.Grammar))
+		}
+		if hasTranscription {
+			cells = append(cells, pinned.text(docxRun{}, item.Transcription))
+		}
+		if hasTranslation {
+			cells = append(cells, pinned.text(docxRun{}, item.Translation))
+		}
+		io.WriteString(w, docxRow(cells...))
+	}
+	io.WriteString(w, docxTableEnd)
+	return gast.WalkContinue, nil
+}
+
+// renderModels writes a BlockTable like renderVocabulary's, without the
+// grammar column; a phrase with neither transcription nor translation
+// spans the row, as it stands on its own line in the other formats.
+func (r *docxNodeRenderer) renderModels(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	n := node.(*Models)
+	if n.Err != nil {
+		return gast.WalkStop, n.Err
+	}
+	if len(n.Items) == 0 {
+		return gast.WalkContinue, nil
+	}
+
+	dir := blockDirection(n.Script)
+	var hasTranscription, hasTranslation bool
+	for _, item := range n.Items {
+		hasTranscription = hasTranscription || item.Transcription != ""
+		hasTranslation = hasTranslation || item.Translation != ""
+	}
+	cols := 1
+	for _, has := range []bool{hasTranscription, hasTranslation} {
+		if has {
+			cols++
+		}
+	}
+
+	phrase := docxParagraph{Dir: dir}
+	pinned := docxParagraph{Dir: "ltr"}
+	io.WriteString(w, docxTableStart("BlockTable", cols, dir == "rtl"))
+	for _, item := range n.Items {
+		switch {
+		case item.Kind != ItemData:
+			io.WriteString(w, docxSpanRow(cols, docxBlockItem(item.Kind, item.Text, dir)))
+		case item.Transcription == "" && item.Translation == "":
+			io.WriteString(w, docxSpanRow(cols, phrase.text(docxRun{RTL: dir == "rtl"}, item.Phrase)))
+		default:
+			cells := []string{phrase.text(docxRun{RTL: dir == "rtl"}, item.Phrase)}
+			if hasTranscription {
+				cells = append(cells, pinned.text(docxRun{}, item.Transcription))
+			}
+			if hasTranslation {
+				cells = append(cells, pinned.text(docxRun{}, item.Translation))
+			}
+			io.WriteString(w, docxRow(cells...))
+		}
+	}
+	io.WriteString(w, docxTableEnd)
+	return gast.WalkContinue, nil
+}
+
+// renderQuestions writes a BlockTable of question and answer, both in the
+// block's direction; a question without an answer spans the row.
+func (r *docxNodeRenderer) renderQuestions(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	n := node.(*Questions)
+	if n.Err != nil {
+		return gast.WalkStop, n.Err
+	}
+	if len(n.Items) == 0 {
+		return gast.WalkContinue, nil
+	}
+
+	dir := blockDirection(n.Script)
+	cols := 1
+	for _, item := range n.Items {
+		if item.Answer != "" {
+			cols = 2
+		}
+	}
+
+	p := docxParagraph{Dir: dir}
+	run := docxRun{RTL: dir == "rtl"}
+	io.WriteString(w, docxTableStart("BlockTable", cols, dir == "rtl"))
+	for _, item := range n.Items {
+		switch {
+		case item.Kind != ItemData:
+			io.WriteString(w, docxSpanRow(cols, docxBlockItem(item.Kind, item.Text, dir)))
+		case item.Answer == "":
+			io.WriteString(w, docxSpanRow(cols, p.text(run, item.Question)))
+		default:
+			io.WriteString(w, docxRow(p.text(run, item.Question), p.text(run, item.Answer)))
+		}
+	}
+	io.WriteString(w, docxTableEnd)
+	return gast.WalkContinue, nil
+}
+
+// docxTurn returns a dialog turn: the speaker in a DialogSpeaker paragraph,
+// kept with the turn's content, which recurses through toDOCX in the
+// DialogText style. Both take dir.
+func docxTurn(header, content string, line int, dir string) (string, error) {
+	body, err := toDOCX([]byte(content), dir, "DialogText")
+	if err != nil {
+		return "", nestedError(line, err)
+	}
+	speaker := docxParagraph{Style: "DialogSpeaker", KeepNext: true, Dir: dir}.text(docxRun{RTL: dir == "rtl"}, header)
+	return speaker + string(body), nil
+}
+
+// renderDialog writes the dialog as styled paragraphs in the block's
+// direction: each turn's speaker, then its content (docxTurn).
+func (r *docxNodeRenderer) renderDialog(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	n := node.(*Dialog)
+	if n.Err != nil {
+		return gast.WalkStop, n.Err
+	}
+
+	dir := blockDirection(n.Script)
+	for _, item := range n.Items {
+		if item.Kind != ItemData {
+			io.WriteString(w, docxBlockItem(item.Kind, item.Text, dir))
+			continue
+		}
+		turn, err := docxTurn(item.Header, item.Content, item.Line, dir)
+		if err != nil {
+			return gast.WalkStop, err
+		}
+		io.WriteString(w, turn)
+	}
+	return gast.WalkContinue, nil
+}
+
+// renderParallel writes a two-column BlockTable, a row per row: the source
+// in the block's direction with the transcription below it pinned
+// left-to-right, and the translation in the document's direction, each
+// cell's markdown recursed through toDOCX.
+func (r *docxNodeRenderer) renderParallel(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	n := node.(*Parallel)
+	if n.Err != nil {
+		return gast.WalkStop, n.Err
+	}
+	if len(n.Rows) == 0 {
+		return gast.WalkContinue, nil
+	}
+
+	dir := blockDirection(n.Script)
+	io.WriteString(w, docxTableStart("BlockTable", 2, false))
+	for _, row := range n.Rows {
+		main, err := toDOCX([]byte(row.SourceRaw), dir, "")
+		if err != nil {
+			return gast.WalkStop, nestedError(row.Line, err)
+		}
+		if row.TranscriptionRaw != "" {
+			transcription, err := toDOCX([]byte(row.TranscriptionRaw), "ltr", "")
+			if err != nil {
+				return gast.WalkStop, nestedError(row.Line, err)
+			}
+			main = append(main, transcription...)
+		}
+		translation, err := toDOCX([]byte(row.TranslationRaw), "", "")
+		if err != nil {
+			return gast.WalkStop, nestedError(row.Line, err)
+		}
+		io.WriteString(w, docxRow(string(main), string(translation)))
+	}
+	io.WriteString(w, docxTableEnd)
+	return gast.WalkContinue, nil
+}
+
+// renderParallelDialog writes renderParallel's two-column table with a
+// dialog turn (docxTurn) or a heading in each field.
+func (r *docxNodeRenderer) renderParallelDialog(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	n := node.(*ParallelDialog)
+	if n.Err != nil {
+		return gast.WalkStop, n.Err
+	}
+	if len(n.Rows) == 0 {
+		return gast.WalkContinue, nil
+	}
+
+	field := func(item ParallelDialogItem, dir string) (string, error) {
+		if item.Kind == ItemHeader {
+			return docxBlockItem(ItemHeader, item.Text, dir), nil
+		}
+		return docxTurn(item.Header, item.Content, item.Line, dir)
+	}
+
+	dir := blockDirection(n.Script)
+	io.WriteString(w, docxTableStart("BlockTable", 2, false))
+	for _, row := range n.Rows {
+		main, err := field(row.Source, dir)
+		if err != nil {
+			return gast.WalkStop, err
+		}
+		if row.HasTranscription {
+			transcription, err := field(row.Transcription, "ltr")
+			if err != nil {
+				return gast.WalkStop, err
+			}
+			main += transcription
+		}
+		translation, err := field(row.Translation, "")
+		if err != nil {
+			return gast.WalkStop, err
+		}
+		io.WriteString(w, docxRow(main, translation))
+	}
+	io.WriteString(w, docxTableEnd)
+	return gast.WalkContinue, nil
+}
+
+// renderInterlinear writes each example as a borderless PlainTable with a
+// column per word — the source line in the block's direction (its columns
+// running right to left for an RTL script), the transliteration and gloss
+// pinned left-to-right — and the free translation spanning the last row.
+// Gloss category labels are set in small caps (glossLabelRe), lowercased
+// so that Word's small caps show.
+func (r *docxNodeRenderer) renderInterlinear(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	n := node.(*Interlinear)
+	if n.Err != nil {
+		return gast.WalkStop, n.Err
+	}
+
+	dir := blockDirection(n.Script)
+	pinned := docxParagraph{Dir: "ltr"}
+	for _, item := range n.Items {
+		if item.Kind != ItemData {
+			io.WriteString(w, docxBlockItem(item.Kind, item.Text, dir))
+			continue
+		}
+		if len(item.Words) == 0 {
+			continue
+		}
+		cols := len(item.Words)
+		sources := make([]string, cols)
+		transliterations := make([]string, cols)
+		glosses := make([]string, cols)
+		for i, word := range item.Words {
+			sources[i] = docxParagraph{Dir: dir}.text(docxRun{RTL: dir == "rtl"}, word.Source)
+			transliterations[i] = pinned.text(docxRun{Italic: true}, word.Transliteration)
+			glosses[i] = pinned.open() + docxGlossRuns(word.Gloss) + "</w:p>"
+		}
+		io.WriteString(w, docxTableStart("PlainTable", cols, dir == "rtl"))
+		io.WriteString(w, docxRow(sources...))
+		if hasTransliteration(item) {
+			io.WriteString(w, docxRow(transliterations...))
+		}
+		io.WriteString(w, docxRow(glosses...))
+		io.WriteString(w, docxSpanRow(cols, pinned.text(docxRun{}, item.Translation)))
+		io.WriteString(w, docxTableEnd)
+	}
+	return gast.WalkContinue, nil
+}
+
+// docxGlossRuns returns a gloss as runs, its category labels in small caps.
+func docxGlossRuns(gloss string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range glossLabelRe.FindAllStringIndex(gloss, -1) {
+		b.WriteString(docxRun{}.text(gloss[last:m[0]]))
+		b.WriteString(docxRun{SmallCaps: true}.text(strings.ToLower(gloss[m[0]:m[1]])))
+		last = m[1]
+	}
+	b.WriteString(docxRun{}.text(gloss[last:]))
+	return b.String()
+}
+
+// renderTextblock writes the block's body, recursed through toDOCX in the
+// block's direction: its script's, except for as=transcription, which is
+// pinned left-to-right as in the other renderers.
+func (r *docxNodeRenderer) renderTextblock(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	n := node.(*Text)
+	if n.Err != nil {
+		return gast.WalkStop, n.Err
+	}
+	if n.Raw == "" {
+		return gast.WalkContinue, nil
+	}
+	dir := blockDirection(n.Script)
+	if n.As == "transcription" {
+		dir = "ltr"
+	}
+	body, err := toDOCX([]byte(n.Raw), dir, "")
+	if err != nil {
+		return gast.WalkStop, offsetError(n.RawLine, err)
+	}
+	w.Write(body)
+	return gast.WalkContinue, nil
+}
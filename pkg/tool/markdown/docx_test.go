@@ -0,0 +1,227 @@
+package markdown_test
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/dpurge/cli-tools/pkg/tool/markdown"
+)
+
+// docxKitchenSink exercises every node kind the DOCX renderer registers,
+// nested lists and tables inside custom blocks included.
+const docxKitchenSink = `# Title & "Quotes"
+
+Some *emphasis*, **strong**, ~~struck~~ and ` + "`code < span`" + ` with a [link](https://example.com/?a="b"&c=d)
+and <https://example.com> and <user@example.com>.
+Hard break, then ![a map](map.png).
+
+> Quoted *text*.
+
+1. one
+2. two
+   - nested
+   - bullets
+
+3) three
+
+Term
+: Definition
+
+| Left | Center | Right |
+|:-----|:------:|------:|
+| a    | b      | c     |
+
+` + "```go\nfunc main() {\n\tprintln(\"<hi>\")\n}\n```" + `
+
+---
+
+{start-vocabulary lang=arb script=arab}
+# Nouns
+بيت {n} [bayt] = house
+كتاب = book
+{end-vocabulary}
+
+{start-models}
+Phrase only
+Hello [həˈləʊ] = hi
+{end-models}
+
+{start-questions}
+What?
+Why? = Because.
+{end-questions}
+
+{start-dialog lang=heb script=hebr}
+@Ali:
+  Hello *there*.
+
+  - a list
+{end-dialog}
+
+{start-parallel lang=arb script=arab}
+مرحبا
+---
+Hello
+---
+marhaban
+{end-parallel}
+
+{start-parallel-dialog}
+@A:
+  Salut.
+---
+@A:
+  Hi.
+{end-parallel-dialog}
+
+{start-interlinear lang=lat script=latn}
+canis currit
+dog.NOM.SG run-3SG
+'The dog runs.'
+{end-interlinear}
+
+{start-text lang=arb script=arab}
+نص **عربي**
+{end-text}
+`
+
+// TestToDOCX_WellFormed asserts the kitchen-sink fragment, wrapped in a
+// <w:body>, is well-formed XML: every element the renderer opens it
+// closes, in order, and all text is escaped.
+func TestToDOCX_WellFormed(t *testing.T) {
+	got, err := markdown.ToDOCX([]byte(docxKitchenSink))
+	if err != nil {
+		t.Fatalf("ToDOCX unexpected error: %v", err)
+	}
+	doc := `<w:body xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` + string(got) + `</w:body>`
+	dec := xml.NewDecoder(strings.NewReader(doc))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("fragment is not well-formed: %v\n%s", err, got)
+		}
+	}
+}
+
+// TestToDOCX covers the mapping of standard and custom nodes onto
+// WordprocessingML: styles, run properties, list labels, table layout and
+// per-block direction.
+func TestToDOCX(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "heading",
+			input: "## Two\n",
+			want:  []string{`<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t xml:space="preserve">Two</w:t></w:r></w:p>`},
+		},
+		{
+			name:  "emphasis and strikethrough",
+			input: "*a* **b** ~~c~~\n",
+			want: []string{
+				`<w:r><w:rPr><w:i/><w:iCs/></w:rPr><w:t xml:space="preserve">a</w:t></w:r>`,
+				`<w:r><w:rPr><w:b/><w:bCs/></w:rPr><w:t xml:space="preserve">b</w:t></w:r>`,
+				`<w:r><w:rPr><w:strike/></w:rPr><w:t xml:space="preserve">c</w:t></w:r>`,
+			},
+		},
+		{
+			name:  "link",
+			input: "[here](https://example.com/)\n",
+			want: []string{
+				`<w:instrText xml:space="preserve"> HYPERLINK &#34;https://example.com/&#34; </w:instrText>`,
+				`<w:r><w:rPr><w:rStyle w:val="Hyperlink"/></w:rPr><w:t xml:space="preserve">here</w:t></w:r>`,
+			},
+		},
+		{
+			name:  "ordered list starts at its start number",
+			input: "3. three\n4. four\n",
+			want: []string{
+				`<w:pPr><w:pStyle w:val="ListParagraph"/><w:ind w:left="720" w:hanging="360"/></w:pPr><w:r><w:t xml:space="preserve">3.</w:t></w:r><w:r><w:tab/></w:r>`,
+				`<w:r><w:t xml:space="preserve">4.</w:t></w:r>`,
+			},
+		},
+		{
+			name:  "nested bullet list",
+			input: "- a\n  - b\n",
+			want: []string{
+				`<w:r><w:t xml:space="preserve">•</w:t></w:r>`,
+				`<w:ind w:left="1440" w:hanging="360"/></w:pPr><w:r><w:t xml:space="preserve">◦</w:t></w:r>`,
+			},
+		},
+		{
+			name:  "empty list item keeps its label",
+			input: "1. one\n2.\n3. three\n",
+			want: []string{
+				`<w:p><w:pPr><w:pStyle w:val="ListParagraph"/><w:ind w:left="720" w:hanging="360"/></w:pPr><w:r><w:t xml:space="preserve">2.</w:t></w:r><w:r><w:tab/></w:r></w:p>`,
+				`<w:r><w:t xml:space="preserve">3.</w:t></w:r>`,
+			},
+		},
+		{
+			name:  "list item opening with a code block",
+			input: "- ```\n  code\n  ```\n",
+			want: []string{
+				`<w:r><w:t xml:space="preserve">•</w:t></w:r><w:r><w:tab/></w:r></w:p>`,
+				`<w:t xml:space="preserve">code</w:t>`,
+			},
+		},
+		{
+			name:  "table",
+			input: "| a | b |\n|---|--:|\n| 1 | 2 |\n",
+			want: []string{
+				`<w:tblStyle w:val="BlockTable"/><w:tblW w:w="5000" w:type="pct"/></w:tblPr><w:tblGrid><w:gridCol w:w="4513"/><w:gridCol w:w="4513"/></w:tblGrid>`,
+				`<w:tr><w:trPr><w:tblHeader/></w:trPr>`,
+				`<w:r><w:rPr><w:b/><w:bCs/></w:rPr><w:t xml:space="preserve">a</w:t></w:r>`,
+				`<w:p><w:pPr><w:jc w:val="right"/></w:pPr><w:r><w:t xml:space="preserve">2</w:t></w:r></w:p></w:tc>`,
+			},
+		},
+		{
+			name:  "vocabulary in an RTL script",
+			input: "{start-vocabulary lang=arb script=arab}\n# Nouns\nبيت = house\n{end-vocabulary}\n",
+			want: []string{
+				`<w:tblStyle w:val="BlockTable"/><w:bidiVisual/>`,
+				`<w:gridSpan w:val="2"/></w:tcPr><w:p><w:pPr><w:pStyle w:val="BlockHeading"/><w:keepNext/><w:bidi/></w:pPr><w:r><w:rPr><w:rtl/></w:rPr><w:t xml:space="preserve">Nouns</w:t></w:r></w:p>`,
+				`<w:p><w:pPr><w:bidi/></w:pPr><w:r><w:rPr><w:rtl/></w:rPr><w:t xml:space="preserve">بيت</w:t></w:r></w:p>`,
+				`<w:p><w:pPr><w:bidi w:val="0"/></w:pPr><w:r><w:t xml:space="preserve">house</w:t></w:r></w:p>`,
+			},
+		},
+		{
+			name:  "dialog",
+			input: "{start-dialog lang=heb script=hebr}\n@Ali:\n  Shalom\n{end-dialog}\n",
+			want: []string{
+				`<w:p><w:pPr><w:pStyle w:val="DialogSpeaker"/><w:keepNext/><w:bidi/></w:pPr><w:r><w:rPr><w:rtl/></w:rPr><w:t xml:space="preserve">Ali:</w:t></w:r></w:p>`,
+				`<w:p><w:pPr><w:pStyle w:val="DialogText"/><w:bidi/></w:pPr><w:r><w:rPr><w:rtl/></w:rPr><w:t xml:space="preserve">Shalom</w:t></w:r></w:p>`,
+			},
+		},
+		{
+			name:  "interlinear gloss labels in small caps",
+			input: "{start-interlinear}\ncanis\ndog.NOM\n'A dog.'\n{end-interlinear}\n",
+			want: []string{
+				`<w:tblStyle w:val="PlainTable"/>`,
+				`<w:t xml:space="preserve">dog.</w:t></w:r><w:r><w:rPr><w:smallCaps/></w:rPr><w:t xml:space="preserve">nom</w:t></w:r>`,
+			},
+		},
+		{
+			name:  "transcription text is pinned left-to-right",
+			input: "{start-text as=transcription lang=arb script=arab}\nbayt\n{end-text}\n",
+			want:  []string{`<w:p><w:pPr><w:bidi w:val="0"/></w:pPr><w:r><w:t xml:space="preserve">bayt</w:t></w:r></w:p>`},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := markdown.ToDOCX([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("ToDOCX(%q) unexpected error: %v", tc.input, err)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(string(got), want) {
+					t.Errorf("ToDOCX(%q) missing %q\n got: %s", tc.input, want, got)
+				}
+			}
+		})
+	}
+}
@@ -46,24 +46,29 @@ func TestToHTML_BadDialogIndentation_ReturnsError(t *testing.T) {
 	}
 }
 
-// TestVocabulary_EmptyPhraseAfterEqualsSplit_DocumentsPreservedPanic
-// documents (does NOT fix) a pre-existing bug carried over from the ported
-// gomarkdown code (see parser.go parseVocabularyItems): a vocabulary line
-// that reduces to the empty string after the "= translation" split (e.g.
-// "= foo") causes an out-of-range slice index and panics. This is a
-// deliberately preserved divergence per the approved migration spec, not a
-// regression introduced by this test suite. Verified empirically: it
-// panics with "runtime error: slice bounds out of range [-1:]".
-func TestVocabulary_EmptyPhraseAfterEqualsSplit_DocumentsPreservedPanic(t *testing.T) {
-	defer func() {
-		r := recover()
-		if r == nil {
-			t.Fatalf("expected a panic (pre-existing, preserved behavior) but ToHTML did not panic")
-		}
-		t.Logf("documented preserved panic: %v", r)
-	}()
-
-	_, _ = markdown.ToHTML([]byte("{start-vocabulary}\n= foo\n{end-vocabulary}\n"))
+// TestVocabulary_EmptyPhraseAfterEqualsSplit_NoPanic asserts that a
+// vocabulary line which reduces to the empty string after the
+// "= translation" split (e.g. "= foo") renders with no phrase span instead
+// of panicking, and that an unmatched trailing `]` stays in the phrase —
+// the same guard parseModelsItems has.
+func TestVocabulary_EmptyPhraseAfterEqualsSplit_NoPanic(t *testing.T) {
+	got, err := markdown.ToHTML([]byte("{start-vocabulary}\n= foo\nbar] = baz\n{end-vocabulary}\n"))
+	if err != nil {
+		t.Fatalf("ToHTML() unexpected error: %v", err)
+	}
+	want := "<div class=\"block-marker\"><span class=\"ct-badge\">V</span></div>\n" +
+		"<div class=\"vocabulary\" dir=\"ltr\">\n" +
+		"<div class=\"vocabulary-item\">\n" +
+		"<span class=\"vocabulary-translation\" dir=\"ltr\">foo</span>\n" +
+		"</div>\n" +
+		"<div class=\"vocabulary-item\">\n" +
+		"<span class=\"vocabulary-phrase\">bar]</span>\n" +
+		"<span class=\"vocabulary-translation\" dir=\"ltr\">baz</span>\n" +
+		"</div>\n" +
+		"</div>\n"
+	if string(got) != want {
+		t.Fatalf("ToHTML() mismatch\n got: %q\nwant: %q", got, want)
+	}
 }
 
 // TestToHTML_UnterminatedModels_FallsThroughToParagraph mirrors
@@ -101,8 +106,7 @@ func TestToHTML_UnterminatedQuestions_FallsThroughToParagraph(t *testing.T) {
 }
 
 // TestModels_EmptyPhraseAfterBracketSplit_NoPanic covers the agreed
-// migration decision for FR-2A.5 (models diverges deliberately from
-// vocabulary's preserved empty-phrase panic): a models line that reduces
+// migration decision for FR-2A.5: a models line that reduces
 // to the empty string after the trailing "[...]" split (e.g. a
 // transcription-only line "[abc]", with no phrase text before the
 // bracket) MUST NOT panic, and the phrase span is simply omitted from the
@@ -0,0 +1,88 @@
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SourceError is an error at a position in a markdown source. Every block
+// error the converters return is one, carrying the 1-based Line of the
+// block's marker, or of the item at fault; FileToHTML, FileToTypst and
+// FileToMDX (and InFile) add the File.
+type SourceError struct {
+	File string
+	Line int // 0 when the error has no position
+	Err  error
+}
+
+func (e *SourceError) Error() string {
+	switch {
+	case e.File != "" && e.Line > 0:
+		return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+	case e.File != "":
+		return fmt.Sprintf("%s: %v", e.File, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return e.Err.Error()
+}
+
+func (e *SourceError) Unwrap() error { return e.Err }
+
+// InFile returns err as a *SourceError in filename, keeping its line, or
+// nil if err is nil. It is for callers that read a file themselves and
+// convert its bytes, as the FileTo* functions do.
+func InFile(filename string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var se *SourceError
+	if errors.As(err, &se) {
+		return &SourceError{File: filename, Line: se.Line, Err: se.Err}
+	}
+	return &SourceError{File: filename, Err: err}
+}
+
+// atLine returns err positioned at line, or nil if err is nil. An err that
+// is already positioned keeps its line.
+func atLine(line int, err error) error {
+	if err == nil {
+		return nil
+	}
+	var se *SourceError
+	if errors.As(err, &se) {
+		return err
+	}
+	return &SourceError{Line: line, Err: err}
+}
+
+// nestedError positions an error from converting a block's nested markdown
+// (a dialog turn, a parallel cell) at line, the line of the item holding
+// it; the nested conversion's own line counts from the start of that
+// content, not of the file, so it is dropped.
+func nestedError(line int, err error) error {
+	var se *SourceError
+	if errors.As(err, &se) {
+		err = se.Err
+	}
+	return &SourceError{Line: line, Err: err}
+}
+
+// leadingBlankLines returns the number of whole blank lines strings.TrimSpace
+// would remove from the start of s, to keep line numbers right for the
+// trimmed text.
+func leadingBlankLines(s string) int {
+	return strings.Count(s[:len(s)-len(strings.TrimLeft(s, " \t\n"))], "\n")
+}
+
+// offsetError positions an error from converting nested markdown that
+// starts at line first of the file and keeps its lines (a text block's
+// body): the nested line is shifted onto the file's.
+func offsetError(first int, err error) error {
+	var se *SourceError
+	if errors.As(err, &se) && se.Line > 0 {
+		return &SourceError{Line: first + se.Line - 1, Err: se.Err}
+	}
+	return nestedError(first, err)
+}
@@ -0,0 +1,178 @@
+package markdown_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gast "github.com/yuin/goldmark/ast"
+
+	"github.com/dpurge/cli-tools/pkg/tool/markdown"
+)
+
+// TestBlockErrorLines asserts the line every block error is reported at —
+// the marker, the item at fault, or for nested content the item holding it
+// (a text body keeps its own lines) — identically from all renderers.
+func TestBlockErrorLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "marker attribute",
+			input:   "# T\n\n\n{start-questions lang=tur colour=red}\nWho? = Me.\n{end-questions}\n",
+			wantErr: `line 4: unknown attribute "colour" on {start-questions}`,
+		},
+		{
+			name:    "dialog line after blank lines",
+			input:   "# T\n\n{start-dialog}\n\n\n@Ali:\n  Merhaba\n\n@Ayşe:\nMerhaba\n{end-dialog}\n",
+			wantErr: "line 10: Wrong line indentation for dialog item: Merhaba",
+		},
+		{
+			name:    "parallel-dialog field",
+			input:   "# T\n\n{start-parallel-dialog}\n@A:\n  Salut\n---\n@A:\n  Hi\n===\n@B:\n  Pa\n---\n@B:\nBye\n{end-parallel-dialog}\n",
+			wantErr: "line 13: parallel-dialog translation field: wrong line indentation for parallel-dialog item: Bye",
+		},
+		{
+			name:    "nested in a parallel cell",
+			input:   "# T\n\n{start-parallel}\nUnu\n---\nOne\n===\nDoi\n---\n{start-models as=source}\nx\n{end-models}\n{end-parallel}\n",
+			wantErr: "line 8: as= not applicable to {start-models}: its field languages are fixed",
+		},
+		{
+			name:    "nested in a text body",
+			input:   "# T\n\n{start-text as=translation}\n\nIntro.\n\n{start-vocabulary as=source}\nev = house\n{end-vocabulary}\n{end-text}\n",
+			wantErr: "line 7: as= not applicable to {start-vocabulary}: its field languages are fixed",
+		},
+	}
+	renderers := map[string]func([]byte) ([]byte, error){
+		"ToHTML":  markdown.ToHTML,
+		"ToTypst": markdown.ToTypst,
+		"ToDOCX":  markdown.ToDOCX,
+	}
+	for _, tc := range tests {
+		for name, render := range renderers {
+			t.Run(tc.name+"/"+name, func(t *testing.T) {
+				_, err := render([]byte(tc.input))
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("%s() error = %v, want %q", name, err, tc.wantErr)
+				}
+				var se *markdown.SourceError
+				if !errors.As(err, &se) || se.Line == 0 {
+					t.Fatalf("%s() error %v is not a positioned *SourceError", name, err)
+				}
+			})
+		}
+	}
+}
+
+// TestFileToErrorsNameFile: the FileTo* functions prefix the block error's
+// line with the file name.
+func TestFileToErrorsNameFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "chapter.md")
+	if err := os.WriteFile(file, []byte("# T\n\n{start-dialog as=grammar}\n@A:\n  hi\n{end-dialog}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want := file + `:3: as="grammar" is not valid on {start-dialog}: must be source|translation`
+
+	converters := map[string]func(string) (string, error){
+		"FileToHTML":  markdown.FileToHTML,
+		"FileToTypst": markdown.FileToTypst,
+		"FileToMDX":   func(f string) (string, error) { return markdown.FileToMDX(f, "tur", "latn") },
+	}
+	for name, convert := range converters {
+		if _, err := convert(file); err == nil || err.Error() != want {
+			t.Errorf("%s() error = %v, want %q", name, err, want)
+		}
+	}
+}
+
+// TestItemLines asserts the source line recorded on block nodes and their
+// items, across blank lines, headings and multi-line rows.
+func TestItemLines(t *testing.T) {
+	source := "# T\n\n" +
+		"{start-vocabulary}\n\nev = house\n## Doors\nkapı = door\n{end-vocabulary}\n" + // 3-8
+		"{start-dialog}\n@Ali:\n  Merhaba\n\n  Nasılsın?\n@Ayşe:\n  İyiyim\n{end-dialog}\n" + // 9-16
+		"{start-parallel}\nUnu\n---\nOne\n===\n\nDoi\n---\nTwo\n{end-parallel}\n" // 17-26
+
+	var got []int
+	gast.Walk(markdown.Parse([]byte(source)), func(n gast.Node, entering bool) (gast.WalkStatus, error) {
+		if !entering {
+			return gast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *markdown.Vocabulary:
+			got = append(got, n.Line)
+			for _, item := range n.Items {
+				got = append(got, item.Line)
+			}
+		case *markdown.Dialog:
+			got = append(got, n.Line)
+			for _, item := range n.Items {
+				got = append(got, item.Line)
+			}
+		case *markdown.Parallel:
+			got = append(got, n.Line)
+			for _, row := range n.Rows {
+				got = append(got, row.Line)
+			}
+		}
+		return gast.WalkContinue, nil
+	})
+
+	want := []int{3, 5, 6, 7, 9, 10, 14, 17, 18, 23}
+	if len(got) != len(want) {
+		t.Fatalf("lines = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("lines = %v, want %v", got, want)
+		}
+	}
+}
+
+// TestWalkBlocks: blocks in a {start-text} body are visited in document
+// order after the text block itself, and an error from a nested block is
+// positioned on the file's line.
+func TestWalkBlocks(t *testing.T) {
+	source := "# T\n\n" +
+		"{start-vocabulary}\nev = house\n{end-vocabulary}\n" + // 3-5
+		"{start-text as=translation}\n\nIntro.\n\n{start-vocabulary}\nkapı = door\n{end-vocabulary}\n{end-text}\n" + // 6-13
+		"{start-models}\nx\n{end-models}\n" // 14-16
+
+	var got []string
+	err := markdown.WalkBlocks([]byte(source), func(n gast.Node) error {
+		switch n := n.(type) {
+		case *markdown.Vocabulary:
+			got = append(got, "vocabulary:"+n.Items[0].Phrase)
+		case *markdown.Text:
+			got = append(got, "text")
+		case *markdown.Models:
+			got = append(got, "models")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkBlocks: %v", err)
+	}
+	want := []string{"vocabulary:ev", "text", "vocabulary:kapı", "models"}
+	if len(got) != len(want) {
+		t.Fatalf("visited %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("visited %v, want %v", got, want)
+		}
+	}
+
+	err = markdown.WalkBlocks([]byte(source), func(n gast.Node) error {
+		if v, ok := n.(*markdown.Vocabulary); ok && v.Items[0].Phrase == "kapı" {
+			return &markdown.SourceError{Line: v.Line, Err: errors.New("stop")}
+		}
+		return nil
+	})
+	if want := "line 10: stop"; err == nil || err.Error() != want {
+		t.Errorf("WalkBlocks error = %v, want %q", err, want)
+	}
+}
@@ -58,6 +58,19 @@ func (e *parallelDialogExtension) Extend(m goldmark.Markdown) {
 	))
 }
 
+// interlinearExtension registers the interlinear block parser and renderer.
+// Priority 127 — after parallel-dialog (125), before models (130).
+type interlinearExtension struct{}
+
+func (e *interlinearExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithBlockParsers(
+		util.Prioritized(newInterlinearParser(), 127),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&interlinearRenderer{}, 127),
+	))
+}
+
 // modelsExtension registers the models block parser and renderer.
 type modelsExtension struct{}
 
@@ -97,13 +110,13 @@ func (e *textExtension) Extend(m goldmark.Markdown) {
 	))
 }
 
-// Extenders wired into the shared converter (converter.go). Interlinear is
-// deliberately excluded — it remains an inactive stub (interlinear.go).
+// Extenders wired into the shared converter (converter.go).
 var (
 	vocabularyExtender     goldmark.Extender = &vocabularyExtension{}
 	dialogExtender         goldmark.Extender = &dialogExtension{}
 	parallelExtender       goldmark.Extender = &parallelExtension{}
 	parallelDialogExtender goldmark.Extender = &parallelDialogExtension{}
+	interlinearExtender    goldmark.Extender = &interlinearExtension{}
 	modelsExtender         goldmark.Extender = &modelsExtension{}
 	questionsExtender      goldmark.Extender = &questionsExtension{}
 	textExtender           goldmark.Extender = &textExtension{}
@@ -1,6 +1,7 @@
 package markdown_test
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/dpurge/cli-tools/pkg/tool/markdown"
@@ -33,3 +34,51 @@ func TestToHTML_Idempotent(t *testing.T) {
 		}
 	}
 }
+
+// TestConverters_Concurrent guards the same singleton from the other side:
+// ebook-cli converts a book's chapters on parallel goroutines, so ToHTML,
+// ToTypst and ToMDX running concurrently on the shared md instance — with
+// the recursive cell rendering in the mix — must each give the output a
+// serial call gives. Run with -race to also catch unsynchronised state.
+func TestConverters_Concurrent(t *testing.T) {
+	inputs := []string{
+		"# Title\n\nSome **bold** paragraph with a [link](https://x.com) and ~~strike~~.\n",
+		"{start-vocabulary lang=cmn script=hans}\n你好 {noun} [nǐ hǎo] = hello\n{end-vocabulary}\n",
+		"{start-dialog}\n--:\n  Hello there.\n@Bob:\n  Hi!\n{end-dialog}\n",
+		"{start-parallel}\nFirst para.\n\n---\n\nSecond para in main.\n---\nSecondary cell.\n{end-parallel}\n",
+		"{start-interlinear}\nCanes currunt\ndog-PL run.3PL\n'The dogs run.'\n{end-interlinear}\n",
+	}
+	converters := map[string]func([]byte) ([]byte, error){
+		"ToHTML":  markdown.ToHTML,
+		"ToTypst": markdown.ToTypst,
+		"ToMDX":   func(src []byte) ([]byte, error) { return markdown.ToMDX(src, "eng", "latn") },
+	}
+
+	for name, convert := range converters {
+		want := make([]string, len(inputs))
+		for i, input := range inputs {
+			out, err := convert([]byte(input))
+			if err != nil {
+				t.Fatalf("%s() unexpected error: %v", name, err)
+			}
+			want[i] = string(out)
+		}
+
+		var wg sync.WaitGroup
+		for range 8 {
+			for i, input := range inputs {
+				wg.Go(func() {
+					out, err := convert([]byte(input))
+					if err != nil {
+						t.Errorf("%s() concurrent call unexpected error: %v", name, err)
+						return
+					}
+					if string(out) != want[i] {
+						t.Errorf("%s() concurrent output differs for input %q:\n got: %q\nwant: %q", name, input, out, want[i])
+					}
+				})
+			}
+		}
+		wg.Wait()
+	}
+}
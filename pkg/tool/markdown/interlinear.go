@@ -1,29 +1,70 @@
 package markdown
 
 import (
-	gast "github.com/yuin/goldmark/ast"
+	"regexp"
+	"strings"
+	"unicode/utf8"
 )
 
-// Markers for a future `{start-interlinear}` ... `{end-interlinear}` block
-// (word-by-word glossed text). Ported from the unfinished
-// markdown-interlinear.go stub, which was never wired into parsing either.
-var (
-	startInterlinear = []byte("{start-interlinear}")
-	endInterlinear   = []byte("{end-interlinear}")
-)
+// glossLabelRe matches a Leipzig grammatical category label inside a gloss
+// token: an all-uppercase run such as PL, 3SG or ERG between morpheme
+// separators ("dog-PL", "go.PST"). Lexical glosses are lowercase, so they
+// never match. book.typ's interlinear() applies the same pattern as a show
+// rule, so EPUB and PDF agree on what renders in small caps.
+var glossLabelRe = regexp.MustCompile(`\b[0-9]*[A-Z][0-9A-Z]*\b`)
+
+// glossLabelsHTML wraps every category label in gloss in a
+// `<span class="gloss-label">`, for CSS to set in small caps. The gloss is
+// otherwise written raw, like every other block field (renderer.go).
+func glossLabelsHTML(gloss string) string {
+	return glossLabelRe.ReplaceAllString(gloss, `<span class="gloss-label">$0</span>`)
+}
 
-// Interlinear is an inactive stub node type. No BlockParser or NodeRenderer
-// is registered for KindInterlinear (see extension.go / converter.go), so
-// it has no effect on conversion — it exists only so the eventual feature
-// has a place to grow into.
-type Interlinear struct {
-	gast.BaseBlock
+// hasTransliteration reports whether an interlinear example was written with
+// a transliteration line. The parser fills every word's Transliteration or
+// none of them, so the first word decides.
+func hasTransliteration(item InterlinearItem) bool {
+	return len(item.Words) > 0 && item.Words[0].Transliteration != ""
 }
 
-// Kind implements ast.Node.
-func (n *Interlinear) Kind() gast.NodeKind { return KindInterlinear }
+// interlinearLines returns an example's word lines — source, the optional
+// transliteration, gloss — with each token padded to its column's width, so
+// the columns line up in a monospace fence. Padding only adds spaces, so
+// parseInterlinearItems reads the lines back unchanged. Width is counted in
+// runes, which aligns alphabetic scripts; wide (CJK) characters are not
+// compensated for.
+func interlinearLines(item InterlinearItem) []string {
+	rows := [][]string{make([]string, len(item.Words))}
+	if hasTransliteration(item) {
+		rows = append(rows, make([]string, len(item.Words)))
+	}
+	rows = append(rows, make([]string, len(item.Words)))
+	for i, word := range item.Words {
+		rows[0][i] = word.Source
+		if len(rows) == 3 {
+			rows[1][i] = word.Transliteration
+		}
+		rows[len(rows)-1][i] = word.Gloss
+	}
 
-// Dump implements ast.Node.
-func (n *Interlinear) Dump(source []byte, level int) {
-	gast.DumpHelper(n, source, level, nil, nil)
+	lines := make([]string, len(rows))
+	for r, row := range rows {
+		var line strings.Builder
+		for i, token := range row {
+			if i > 0 {
+				line.WriteString(" ")
+			}
+			line.WriteString(token)
+			if i == len(row)-1 {
+				break
+			}
+			width := 0
+			for _, other := range rows {
+				width = max(width, utf8.RuneCountInString(other[i]))
+			}
+			line.WriteString(strings.Repeat(" ", width-utf8.RuneCountInString(token)))
+		}
+		lines[r] = line.String()
+	}
+	return lines
 }
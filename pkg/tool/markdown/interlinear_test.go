@@ -0,0 +1,211 @@
+package markdown_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dpurge/cli-tools/pkg/tool/markdown"
+)
+
+// interlinearExample is a two-word Leipzig example: source, gloss and free
+// translation, with category labels in the gloss.
+const interlinearExample = "{start-interlinear}\nCanes currunt\ndog-PL run.3PL\n'The dogs run.'\n{end-interlinear}\n"
+
+// TestToHTML_Interlinear_Golden asserts the exact wrapper for
+// {start-interlinear}: one column per word (source over the optional
+// transliteration over the gloss), category labels wrapped for small caps,
+// the free translation below, and headers/notes between examples.
+func TestToHTML_Interlinear_Golden(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "source, gloss, translation",
+			input: interlinearExample,
+			want: "<div class=\"block-marker\"><span class=\"ct-badge\">I</span></div>\n" +
+				"<div class=\"interlinear\" dir=\"ltr\">\n" +
+				"<div class=\"interlinear-example\">\n" +
+				"<div class=\"interlinear-words\">\n" +
+				"<div class=\"interlinear-word\">\n" +
+				"<span class=\"interlinear-source\">Canes</span>\n" +
+				"<span class=\"interlinear-gloss\" dir=\"ltr\">dog-<span class=\"gloss-label\">PL</span></span>\n" +
+				"</div>\n" +
+				"<div class=\"interlinear-word\">\n" +
+				"<span class=\"interlinear-source\">currunt</span>\n" +
+				"<span class=\"interlinear-gloss\" dir=\"ltr\">run.<span class=\"gloss-label\">3PL</span></span>\n" +
+				"</div>\n" +
+				"</div>\n" +
+				"<p class=\"interlinear-translation\" dir=\"ltr\">'The dogs run.'</p>\n" +
+				"</div>\n" +
+				"</div>\n",
+		},
+		{
+			name:  "script=arab with transliteration, header and note",
+			input: "{start-interlinear script=arab}\n# Greetings\nكتب\nkataba\nwrite.PFV.3SG.M\n'He wrote.'\n\n(Past tense.)\n{end-interlinear}\n",
+			want: "<div class=\"block-marker\"><span class=\"ct-badge\">I</span></div>\n" +
+				"<div class=\"interlinear s-arab\" dir=\"rtl\">\n" +
+				"<h1>Greetings</h1>\n" +
+				"<div class=\"interlinear-example\">\n" +
+				"<div class=\"interlinear-words\">\n" +
+				"<div class=\"interlinear-word\">\n" +
+				"<span class=\"interlinear-source\">كتب</span>\n" +
+				"<span class=\"interlinear-transliteration\" dir=\"ltr\">kataba</span>\n" +
+				"<span class=\"interlinear-gloss\" dir=\"ltr\">write.<span class=\"gloss-label\">PFV</span>.<span class=\"gloss-label\">3SG</span>.<span class=\"gloss-label\">M</span></span>\n" +
+				"</div>\n" +
+				"</div>\n" +
+				"<p class=\"interlinear-translation\" dir=\"ltr\">'He wrote.'</p>\n" +
+				"</div>\n" +
+				"<p class=\"block-note\">Past tense.</p>\n" +
+				"</div>\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := markdown.ToHTML([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("ToHTML() unexpected error: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Fatalf("ToHTML() mismatch\n got: %q\nwant: %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestToHTML_Interlinear_ParenthesizedTranslation asserts that a `(...)`
+// line inside an example is its free translation, not a note: notes are
+// only recognized where an example would start.
+func TestToHTML_Interlinear_ParenthesizedTranslation(t *testing.T) {
+	input := "{start-interlinear}\nCanes currunt\ndog-PL run.3PL\n(The dogs run.)\n{end-interlinear}\n"
+
+	got, err := markdown.ToHTML([]byte(input))
+	if err != nil {
+		t.Fatalf("ToHTML() unexpected error: %v", err)
+	}
+	if !strings.Contains(string(got), "<p class=\"interlinear-translation\" dir=\"ltr\">(The dogs run.)</p>") {
+		t.Fatalf("expected the parenthesized line as the free translation, got: %q", got)
+	}
+}
+
+// TestToTypst_Interlinear_Golden covers the #interlinear(...) mapping: the
+// transliteration key appears only on words of an example that has that
+// line, and gloss labels are passed through for book.typ to style.
+func TestToTypst_Interlinear_Golden(t *testing.T) {
+	input := "{start-interlinear script=grek}\n# Verbs\nεἶδον τὸν\neidon ton\nsee.AOR.1SG the.ACC\n'I saw the'\n\nCanes currunt\ndog-PL run.3PL\n'The dogs run.'\n{end-interlinear}\n"
+	want := "#block(above: 1.2em, below: 0.5em)[#_ctbadge(\"I\")]\n\n" +
+		"#interlinear(dir: ltr, script: \"grek\",\n" +
+		"  (kind: \"header\", level: 1, text: \"Verbs\"),\n" +
+		"  (words: ((source: \"εἶδον\", transliteration: \"eidon\", gloss: \"see.AOR.1SG\"), (source: \"τὸν\", transliteration: \"ton\", gloss: \"the.ACC\"), ), translation: \"'I saw the'\"),\n" +
+		"  (words: ((source: \"Canes\", gloss: \"dog-PL\"), (source: \"currunt\", gloss: \"run.3PL\"), ), translation: \"'The dogs run.'\"),\n" +
+		")\n\n"
+
+	got, err := markdown.ToTypst([]byte(input))
+	if err != nil {
+		t.Fatalf("ToTypst() unexpected error: %v", err)
+	}
+	if string(got) != want {
+		t.Fatalf("ToTypst() mismatch\n got: %q\nwant: %q", got, want)
+	}
+}
+
+// TestToMDX_Interlinear_Golden asserts the `interlinear` fence pads the word
+// lines into aligned columns, and that the fence body parses back to the
+// same block.
+func TestToMDX_Interlinear_Golden(t *testing.T) {
+	input := "{start-interlinear}\n(Two examples.)\nCanes currunt\ndog-PL run.3PL\n'The dogs run.'\n\nεἶδον τὸν\neidon ton\nsee.AOR.1SG the.ACC\n'I saw the'\n{end-interlinear}\n"
+	runMdxGolden(t, []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "aligned columns",
+			input: input,
+			want: "```interlinear lang=lat script=latn\n" +
+				"(Two examples.)\n" +
+				"\n" +
+				"Canes  currunt\n" +
+				"dog-PL run.3PL\n" +
+				"'The dogs run.'\n" +
+				"\n" +
+				"εἶδον       τὸν\n" +
+				"eidon       ton\n" +
+				"see.AOR.1SG the.ACC\n" +
+				"'I saw the'\n" +
+				"```\n",
+		},
+	})
+
+	mdx, err := markdown.ToMDX([]byte(input), "lat", "latn")
+	if err != nil {
+		t.Fatalf("ToMDX() unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(mdx), "\n"), "\n")
+	body := strings.Join(lines[1:len(lines)-1], "\n")
+	roundTrip, err := markdown.ToHTML([]byte("{start-interlinear}\n" + body + "\n{end-interlinear}\n"))
+	if err != nil {
+		t.Fatalf("ToHTML(fence body) unexpected error: %v", err)
+	}
+	original, err := markdown.ToHTML([]byte(input))
+	if err != nil {
+		t.Fatalf("ToHTML() unexpected error: %v", err)
+	}
+	if string(roundTrip) != string(original) {
+		t.Fatalf("fence body does not round-trip\n got: %q\nwant: %q", roundTrip, original)
+	}
+}
+
+// TestInterlinear_Err asserts that a malformed example surfaces as a block
+// error out of all three renderers, the same way Dialog.Err does, with no
+// partial output, at the line of the example (or of the marker).
+func TestInterlinear_Err(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "gloss token count",
+			input:   "{start-interlinear}\nCanes currunt\ndog-PL\n'The dogs run.'\n{end-interlinear}\n",
+			wantErr: "line 2: interlinear gloss line has 1 tokens but source line has 2: Canes currunt",
+		},
+		{
+			name:    "transliteration token count",
+			input:   "{start-interlinear}\nεἶδον τὸν\neidon\nsee.AOR.1SG the.ACC\n'I saw the'\n{end-interlinear}\n",
+			wantErr: "line 2: interlinear transliteration line has 1 tokens but source line has 2: εἶδον τὸν",
+		},
+		{
+			name:    "line count",
+			input:   "{start-interlinear}\nCanes currunt\ndog-PL run.3PL\n{end-interlinear}\n",
+			wantErr: "line 2: interlinear example must have 3 lines (source, gloss, translation) or 4 (source, transliteration, gloss, translation), got 2: Canes currunt",
+		},
+		{
+			name:    "as= rejected",
+			input:   "{start-interlinear as=translation}\nCanes currunt\ndog-PL run.3PL\n'The dogs run.'\n{end-interlinear}\n",
+			wantErr: "line 1: as= not applicable to {start-interlinear}: its line languages are fixed",
+		},
+	}
+	renderers := map[string]func([]byte) ([]byte, error){
+		"ToHTML":  markdown.ToHTML,
+		"ToTypst": markdown.ToTypst,
+		"ToMDX":   func(src []byte) ([]byte, error) { return markdown.ToMDX(src, "lat", "latn") },
+	}
+	for _, tc := range tests {
+		for name, render := range renderers {
+			t.Run(tc.name+"/"+name, func(t *testing.T) {
+				got, err := render([]byte(tc.input))
+				if err == nil {
+					t.Fatalf("%s() expected error %q, got nil (output: %q)", name, tc.wantErr, got)
+				}
+				if err.Error() != tc.wantErr {
+					t.Fatalf("%s() error = %q, want %q", name, err.Error(), tc.wantErr)
+				}
+				if len(got) != 0 {
+					t.Fatalf("%s() expected no output alongside the error, got: %q", name, got)
+				}
+			})
+		}
+	}
+}
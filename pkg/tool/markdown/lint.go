@@ -0,0 +1,245 @@
+package markdown
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	gast "github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Problem is one mistake Lint found in a markdown source. Line and Column
+// are 1-based; Column counts bytes, as Go's own tools do.
+type Problem struct {
+	Line, Column int
+	Message      string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%d:%d: %s", p.Line, p.Column, p.Message)
+}
+
+// blockNames are the custom block names, as written in their markers.
+var blockNames = []string{"vocabulary", "dialog", "parallel", "parallel-dialog", "interlinear", "models", "questions", "text"}
+
+// Lint reports, in source order, every mistake in source that the
+// converters would otherwise report one at a time (a block's Err) or not at
+// all:
+//
+//   - a malformed marker: unknown or malformed attributes, an as= value the
+//     block does not take, or a block body that does not parse;
+//   - an unterminated {start-…} (opensRawBlock lets it fall through to a
+//     paragraph) or one whose block swallows the next block of the same
+//     kind, and an {end-…} that closes nothing;
+//   - a script= value that is not an ISO 15924 code (it would silently fall
+//     back to LTR);
+//   - a vocabulary line with no phrase;
+//   - a missing H1, which the EPUB exporter needs for the chapter title.
+//
+// Blocks nested in a {start-text} body are linted too; those nested in
+// parallel cells are only checked when rendered.
+func Lint(source []byte) []Problem {
+	source = normalizeNewlines(source)
+	doc := md.Parser().Parse(text.NewReader(source))
+	problems := lintBlocks(doc, source)
+
+	hasTitle := false
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		if h, ok := n.(*gast.Heading); ok && h.Level == 1 {
+			hasTitle = true
+			break
+		}
+	}
+	if !hasTitle {
+		problems = append(problems, Problem{1, 1, "no H1 title: the chapter title is taken from the first '# ' heading"})
+	}
+
+	slices.SortStableFunc(problems, func(a, b Problem) int {
+		return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+	})
+	return problems
+}
+
+// lintBlocks checks doc's custom blocks, then scans the lines no block or
+// code block claimed for stray {start-…} and {end-…} markers.
+func lintBlocks(doc gast.Node, source []byte) []Problem {
+	var problems []Problem
+	lines := strings.Split(string(source), "\n")
+	claimed := map[int]bool{}
+
+	gast.Walk(doc, func(n gast.Node, entering bool) (gast.WalkStatus, error) {
+		if !entering {
+			return gast.WalkContinue, nil
+		}
+		switch n.(type) {
+		case *gast.FencedCodeBlock, *gast.CodeBlock, *gast.HTMLBlock:
+			for i := 0; i < n.Lines().Len(); i++ {
+				claimed[sourceLine(source, n.Lines().At(i).Start)] = true
+			}
+			return gast.WalkSkipChildren, nil
+		}
+		name, line, script, err, ok := blockInfo(n)
+		if !ok {
+			return gast.WalkContinue, nil
+		}
+
+		// The block runs from its marker to the line after its body, which
+		// must be the end marker: opensRawBlock only checks that the end
+		// marker appears somewhere later, not that it starts a line. In a
+		// list item or blockquote both markers follow the container's
+		// prefix, so the end line is read from the start marker's column on,
+		// past indentation and '>' only.
+		last := line
+		if body := n.Lines(); body.Len() > 0 {
+			last = sourceLine(source, body.At(body.Len()-1).Start)
+		}
+		for l := line; l <= last+1; l++ {
+			claimed[l] = true
+		}
+		if !endsBlock(lines, line, last+1, name) {
+			problems = append(problems, Problem{line, 1, fmt.Sprintf("unterminated {start-%s}: no {end-%s} line follows", name, name)})
+		}
+
+		if err != nil {
+			// Block errors are *SourceErrors at the marker or the item at
+			// fault.
+			p := Problem{line, 1, err.Error()}
+			var se *SourceError
+			if errors.As(err, &se) {
+				p.Line, p.Message = se.Line, se.Err.Error()
+			}
+			problems = append(problems, p)
+		}
+		if script != "" && !iso15924[script] {
+			column := strings.Index(lines[line-1], "script=") + 1
+			problems = append(problems, Problem{line, max(column, 1), fmt.Sprintf("unknown script=%q: not an ISO 15924 code", script)})
+		}
+
+		switch n := n.(type) {
+		case *Vocabulary:
+			problems = append(problems, lintVocabulary(n, lines)...)
+		case *Text:
+			// The body is linted as a document of its own, shifted to
+			// where it starts.
+			if body := n.Lines(); body.Len() > 0 {
+				inner := body.Value(source)
+				offset := sourceLine(source, body.At(0).Start) - 1
+				for _, p := range lintBlocks(md.Parser().Parse(text.NewReader(inner)), inner) {
+					p.Line += offset
+					problems = append(problems, p)
+				}
+			}
+		}
+		// A marker inside the body means this block's end marker is missing
+		// and a later block's end closed it. Dialog, parallel and
+		// parallel-dialog content is rendered as markdown, so other kinds of
+		// block may nest there; the itemized blocks take no markers at all.
+		itemized := name == "vocabulary" || name == "models" || name == "questions" || name == "interlinear"
+		for i := 0; i < n.Lines().Len() && name != "text"; i++ {
+			segment := n.Lines().At(i)
+			marker := ""
+			if k := blockMarker(segment.Value(source), "{start-"); k == name || (k != "" && itemized) {
+				marker = "{start-" + k + "}"
+			} else if k := blockMarker(segment.Value(source), "{end-"); k != "" && itemized {
+				marker = "{end-" + k + "}"
+			}
+			if marker != "" {
+				problems = append(problems, Problem{sourceLine(source, segment.Start), 1,
+					fmt.Sprintf("%s inside the {start-%s} block opened at line %d: is its {end-%s} missing?", marker, name, line, name)})
+				break
+			}
+		}
+		return gast.WalkSkipChildren, nil
+	})
+
+	for i, line := range lines {
+		if claimed[i+1] {
+			continue
+		}
+		if name := blockMarker([]byte(line), "{start-"); name != "" {
+			problems = append(problems, Problem{i + 1, 1, fmt.Sprintf("unterminated {start-%s}: no {end-%s} line follows", name, name)})
+		} else if name := blockMarker([]byte(line), "{end-"); name != "" {
+			problems = append(problems, Problem{i + 1, 1, fmt.Sprintf("{end-%s} without a matching {start-%s}", name, name)})
+		}
+	}
+	return problems
+}
+
+// endsBlock reports whether line end (1-based) is the {end-name} marker
+// closing the block whose {start-name} marker is on line start: the marker
+// at the start marker's column, preceded only by container prefix
+// (indentation and '>').
+func endsBlock(lines []string, start, end int, name string) bool {
+	if end > len(lines) {
+		return false
+	}
+	column := max(strings.Index(lines[start-1], "{start-"+name), 0)
+	prefix, _, ok := strings.Cut(lines[end-1], "{end-"+name+"}")
+	return ok && len(prefix) == column && strings.Trim(prefix, " \t>") == ""
+}
+
+// lintVocabulary reports the items of a vocabulary block that have no
+// phrase, such as "= house" or "[ev] = house".
+func lintVocabulary(n *Vocabulary, lines []string) []Problem {
+	var problems []Problem
+	for _, item := range n.Items {
+		line := lines[item.Line-1]
+		if item.Kind != ItemData || item.Phrase != "" || blockMarker([]byte(line), "{start-") != "" {
+			continue // a swallowed marker is reported as a missing end marker
+		}
+		column := len(line) - len(strings.TrimLeft(line, " \t")) + 1
+		problems = append(problems, Problem{item.Line, column, "vocabulary item has no phrase"})
+	}
+	return problems
+}
+
+// blockInfo returns a custom block node's name, marker line, script and
+// Err; ok is false for any other node.
+func blockInfo(n gast.Node) (name string, line int, script string, err error, ok bool) {
+	switch n := n.(type) {
+	case *Vocabulary:
+		return "vocabulary", n.Line, n.Script, n.Err, true
+	case *Dialog:
+		return "dialog", n.Line, n.Script, n.Err, true
+	case *Parallel:
+		return "parallel", n.Line, n.Script, n.Err, true
+	case *ParallelDialog:
+		return "parallel-dialog", n.Line, n.Script, n.Err, true
+	case *Interlinear:
+		return "interlinear", n.Line, n.Script, n.Err, true
+	case *Models:
+		return "models", n.Line, n.Script, n.Err, true
+	case *Questions:
+		return "questions", n.Line, n.Script, n.Err, true
+	case *Text:
+		return "text", n.Line, n.Script, n.Err, true
+	}
+	return "", 0, "", nil, false
+}
+
+// blockMarker returns the block name of a line that starts with prefix
+// ("{start-" or "{end-") followed by a custom block's name, under the same
+// boundary rule as opensRawBlock, or "" if it does not.
+func blockMarker(line []byte, prefix string) string {
+	rest, ok := bytes.CutPrefix(line, []byte(prefix))
+	if !ok {
+		return ""
+	}
+	for _, name := range blockNames {
+		after, ok := bytes.CutPrefix(rest, []byte(name))
+		if !ok {
+			continue
+		}
+		if prefix == "{end-" && bytes.HasPrefix(after, []byte("}")) {
+			return name
+		}
+		if prefix == "{start-" && (len(after) == 0 || strings.IndexByte("} \t\r\n", after[0]) != -1) {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,129 @@
+package markdown_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/dpurge/cli-tools/pkg/tool/markdown"
+)
+
+// TestLint asserts the exact problems, in source order, reported for each
+// kind of mistake; a clean chapter reports none.
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "clean chapter",
+			input: "# Lesson\n\n{start-vocabulary lang=ara script=arab}\nبيت = house\n{end-vocabulary}\n\n```\n{start-dialog}\n```\n",
+			want:  nil,
+		},
+		{
+			name:  "missing H1",
+			input: "## Only a subheading\n\ntext\n",
+			want:  []string{"1:1: no H1 title: the chapter title is taken from the first '# ' heading"},
+		},
+		{
+			name:  "unknown attribute",
+			input: "# T\n\n{start-dialog speaker=Ali}\n@Ali:\n  Merhaba\n{end-dialog}\n",
+			want:  []string{`3:1: unknown attribute "speaker" on {start-dialog}`},
+		},
+		{
+			name:  "unknown script",
+			input: "# T\n\n{start-vocabulary lang=arb script=arb}\nبيت = house\n{end-vocabulary}\n",
+			want:  []string{`3:28: unknown script="arb": not an ISO 15924 code`},
+		},
+		{
+			name:  "empty vocabulary phrases",
+			input: "# T\n\n{start-vocabulary}\nev = house\n  = door\n[kapı] = door\n{end-vocabulary}\n",
+			want:  []string{"5:3: vocabulary item has no phrase", "6:1: vocabulary item has no phrase"},
+		},
+		{
+			name:  "unterminated start",
+			input: "# T\n\n{start-questions}\nWho? = Me.\n",
+			want:  []string{"3:1: unterminated {start-questions}: no {end-questions} line follows"},
+		},
+		{
+			name:  "end marker not at line start",
+			input: "# T\n\n{start-models}\na\n\nsee {end-models}\n",
+			want:  []string{"3:1: unterminated {start-models}: no {end-models} line follows"},
+		},
+		{
+			name:  "orphaned end",
+			input: "# T\n\ntext\n{end-parallel}\n",
+			want:  []string{"4:1: {end-parallel} without a matching {start-parallel}"},
+		},
+		{
+			name:  "missing end swallows the next block",
+			input: "# T\n\n{start-vocabulary}\nev = house\n\n{start-vocabulary}\nkapı = door\n{end-vocabulary}\n",
+			want:  []string{"6:1: {start-vocabulary} inside the {start-vocabulary} block opened at line 3: is its {end-vocabulary} missing?"},
+		},
+		{
+			name:  "other blocks nest in a text block",
+			input: "# T\n\n{start-text as=translation}\n{start-vocabulary}\nev = house\n{end-vocabulary}\n{end-text}\n",
+			want:  nil,
+		},
+		{
+			name:  "problems inside a text block keep their lines",
+			input: "# T\n\n{start-text}\nintro\n{start-vocabulary script=xyzw}\n= house\n{end-vocabulary}\n{end-text}\n",
+			want: []string{
+				`5:19: unknown script="xyzw": not an ISO 15924 code`,
+				"6:1: vocabulary item has no phrase",
+			},
+		},
+		{
+			name:  "block in a list item",
+			input: "# T\n\n- item\n\n  {start-vocabulary}\n  ev = house\n  {end-vocabulary}\n",
+			want:  nil,
+		},
+		{
+			name:  "block opening a list item",
+			input: "# T\n\n1. {start-questions}\n   Who? = Me.\n   {end-questions}\n",
+			want:  nil,
+		},
+		{
+			name:  "block in a blockquote",
+			input: "# T\n\n> {start-dialog}\n> @Ali:\n>   Merhaba\n> {end-dialog}\n",
+			want:  nil,
+		},
+		{
+			name:  "block in a list in a blockquote",
+			input: "# T\n\n> - {start-vocabulary}\n>   ev = house\n>   {end-vocabulary}\n",
+			want:  nil,
+		},
+		{
+			name:  "problems in a nested block keep their columns",
+			input: "# T\n\n- {start-vocabulary script=xyzw}\n  = house\n  {end-vocabulary}\n",
+			want: []string{
+				`3:21: unknown script="xyzw": not an ISO 15924 code`,
+				"4:3: vocabulary item has no phrase",
+			},
+		},
+		{
+			name:  "nested end marker outside the container",
+			input: "# T\n\n> {start-models}\n> a\n\n{end-models}\n",
+			want: []string{
+				"3:1: unterminated {start-models}: no {end-models} line follows",
+				"6:1: {end-models} without a matching {start-models}",
+			},
+		},
+		{
+			name:  "CRLF",
+			input: "# T\r\n\r\n{start-dialog as=grammar}\r\n@A:\r\n  hi\r\n{end-dialog}\r\n",
+			want:  []string{`3:1: as="grammar" is not valid on {start-dialog}: must be source|translation`},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, p := range markdown.Lint([]byte(tc.input)) {
+				got = append(got, p.String())
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("Lint =\n%q\nwant\n%q", got, tc.want)
+			}
+		})
+	}
+}
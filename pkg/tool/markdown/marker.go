@@ -5,7 +5,8 @@ package markdown
 // block's visual marker. The badge is ALWAYS emitted as a standalone element
 // (never injected into a heading), at the fixed _ctbadge size, on the LEFT
 // regardless of the block's own text direction (FR-2: Latin convention).
-// Letters: T=text, V=vocabulary, D=dialog, M=models, Q=questions, P=parallel.
+// Letters: T=text, V=vocabulary, D=dialog, M=models, Q=questions, P=parallel,
+// R=parallel-dialog, I=interlinear.
 // No new AST NodeKind is introduced — these are rendering-time string
 // additions only, so the 3-renderer panic-gate (ast.go) is untouched.
 
@@ -52,7 +52,7 @@ func newMdxRenderer(lang, script string) renderer.Renderer {
 //
 //	Heading                        -> flush prose, emit "#"xLevel standalone
 //	Vocabulary/Dialog/Parallel/
-//	Models/Questions                -> flush prose, emit its fence (§4.2-4.4,
+//	Interlinear/Models/Questions    -> flush prose, emit its fence (§4.2-4.4,
 //	                                    models/questions mirror the same pattern)
 //	ThematicBreak                   -> flush prose, DROP (D3: the ebook's
 //	                                    own vocab/reading separator; no
@@ -112,7 +112,7 @@ func ToMDX(source []byte, lang, script string) ([]byte, error) {
 			if err := r.Render(&out, source, n); err != nil {
 				return nil, err
 			}
-		case KindVocabulary, KindDialog, KindParallel, KindModels, KindQuestions, KindText:
+		case KindVocabulary, KindDialog, KindParallel, KindInterlinear, KindModels, KindQuestions, KindText:
 			if err := flush(); err != nil {
 				return nil, err
 			}
@@ -141,7 +141,8 @@ func ToMDX(source []byte, lang, script string) ([]byte, error) {
 }
 
 // FileToMDX reads filename and converts its content into an MDX body via
-// ToMDX (mirrors FileToTypst/FileToHTML, typst.go/converter.go).
+// ToMDX (mirrors FileToTypst/FileToHTML, typst.go/converter.go), naming
+// filename and the line in a conversion error.
 func FileToMDX(filename, lang, script string) (string, error) {
 	source, err := os.ReadFile(filename)
 	if err != nil {
@@ -149,7 +150,7 @@ func FileToMDX(filename, lang, script string) (string, error) {
 	}
 	body, err := ToMDX(source, lang, script)
 	if err != nil {
-		return "", err
+		return "", InFile(filename, err)
 	}
 	return string(body), nil
 }
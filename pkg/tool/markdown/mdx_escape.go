@@ -104,7 +104,7 @@ func escapeMdxUrl(dest string) string {
 // escapeMdxAttr escapes s for emission inside a double-quoted MDX JSX
 // attribute value (`<Text lang="..." script="...">`, SPECS §7.5) — the
 // same minimal two-character escape SPECS §5.4 specifies for YAML
-// frontmatter double-quoted scalars (mirrors typstStringLiteral,
+// frontmatter double-quoted scalars (mirrors TypstStringLiteral,
 // pkg/ebook/typst.go): only `\` and `"` are meaningful to escape in a
 // double-quoted string/attribute context.
 func escapeMdxAttr(s string) string {
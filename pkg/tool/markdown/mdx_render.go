@@ -108,6 +108,7 @@ func (r *mdxNodeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer)
 	reg.Register(KindModels, r.renderModels)
 	reg.Register(KindQuestions, r.renderQuestions)
 	reg.Register(KindParallelDialog, r.renderParallelDialog)
+	reg.Register(KindInterlinear, r.renderInterlinear)
 	// KindText MUST be registered last (highest ordinal, ASR-1 panic-gate).
 	reg.Register(KindText, r.renderTextblock)
 }
@@ -1008,6 +1009,74 @@ func (r *mdxNodeRenderer) renderParallelDialog(w util.BufWriter, source []byte,
 	return gast.WalkContinue, nil
 }
 
+// renderInterlinear emits the `interlinear` fence: per example, its word
+// lines (source, the optional transliteration, gloss) padded into aligned
+// columns by interlinearLines, then the free translation; examples are
+// separated by a blank line, and headers/notes re-serialize as literal
+// lines (mirrors renderModels). This round-trips parseInterlinearItems,
+// which splits the word lines on whitespace, so the padding is invisible to
+// it. Fence content is LITERAL — never escaped — only the fence delimiter
+// itself is widened (mdxFence). A parse-time error stops rendering,
+// mirroring renderDialog.
+func (r *mdxNodeRenderer) renderInterlinear(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	n := node.(*Interlinear)
+	if n.Err != nil {
+		return gast.WalkStop, n.Err
+	}
+
+	var body strings.Builder
+	for i, item := range n.Items {
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		switch item.Kind {
+		case ItemHeader:
+			body.WriteString(strings.Repeat("#", item.Level))
+			body.WriteString(" ")
+			body.WriteString(item.Text)
+			body.WriteString("\n")
+		case ItemNote:
+			body.WriteString("(")
+			body.WriteString(item.Text)
+			body.WriteString(")\n")
+		default:
+			for _, line := range interlinearLines(item) {
+				body.WriteString(line)
+				body.WriteString("\n")
+			}
+			body.WriteString(item.Translation)
+			body.WriteString("\n")
+		}
+	}
+	content := strings.TrimSuffix(body.String(), "\n")
+	fence := mdxFence(content)
+
+	lang := r.lang
+	if n.Lang != "" {
+		lang = n.Lang
+	}
+	script := r.script
+	if n.Script != "" {
+		script = n.Script
+	}
+	io.WriteString(w, fence)
+	io.WriteString(w, "interlinear lang=")
+	io.WriteString(w, lang)
+	io.WriteString(w, " script=")
+	io.WriteString(w, script)
+	io.WriteString(w, "\n")
+	io.WriteString(w, content)
+	io.WriteString(w, "\n")
+	io.WriteString(w, fence)
+	io.WriteString(w, "\n\n")
+	r.atLineStart = true
+
+	return gast.WalkContinue, nil
+}
+
 // renderModels emits the `models` fence: one line per ModelsItem,
 // "phrase[ [transcription]][ = translation]" with each bracketed part
 // omitted when its field is empty (mirrors renderVocabulary, minus the
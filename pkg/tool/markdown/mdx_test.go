@@ -553,7 +553,7 @@ func TestToMDX_Dialog_Err(t *testing.T) {
 	if err == nil {
 		t.Fatalf("ToMDX(%q) expected a non-nil error for a badly indented dialog line, got nil (output: %q)", input, got)
 	}
-	wantErr := "Wrong line indentation for dialog item: Badly indented line"
+	wantErr := "line 3: Wrong line indentation for dialog item: Badly indented line"
 	if err.Error() != wantErr {
 		t.Fatalf("ToMDX(%q) error = %q, want %q", input, err.Error(), wantErr)
 	}
@@ -931,7 +931,7 @@ func TestToMDX_Dialog_Err_PropagatesThroughBlockquote(t *testing.T) {
 	if err == nil {
 		t.Fatalf("ToMDX(%q) expected a non-nil error, got nil (output: %q)", input, got)
 	}
-	wantErr := "Wrong line indentation for dialog item: Badly indented"
+	wantErr := "line 3: Wrong line indentation for dialog item: Badly indented"
 	if err.Error() != wantErr {
 		t.Fatalf("ToMDX(%q) error = %q, want %q", input, err.Error(), wantErr)
 	}
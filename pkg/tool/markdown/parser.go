@@ -32,6 +32,9 @@ var (
 	startParallelDialog = []byte("{start-parallel-dialog")
 	endParallelDialog   = []byte("{end-parallel-dialog}")
 
+	startInterlinear = []byte("{start-interlinear")
+	endInterlinear   = []byte("{end-interlinear}")
+
 	startModels = []byte("{start-models")
 	endModels   = []byte("{end-models}")
 
@@ -45,9 +48,10 @@ var (
 // opensRawBlock reports whether the reader is positioned at a line that
 // starts with start AND a matching end marker exists later in the source.
 // When both conditions hold it consumes the start-marker line and returns
-// (markerLine, true); markerLine is the raw line slice (including any
-// trailing \r\n) valid for attribute parsing via parseMarkerAttrs (attr.go).
-// When either condition fails it returns (nil, false) leaving the reader
+// (markerLine, line, true); markerLine is the raw line slice (including any
+// trailing \r\n) valid for attribute parsing via parseMarkerAttrs (attr.go),
+// line its 1-based line number, recorded on the node for Lint (lint.go).
+// When either condition fails it returns (nil, 0, false) leaving the reader
 // position unchanged so the line falls through to ordinary paragraph text.
 //
 // The start slice must NOT include the closing '}' (the start vars above
@@ -59,23 +63,28 @@ var (
 // The end-marker presence check is parity-critical: an unterminated block
 // must fall through rather than swallow the remainder of the document,
 // mirroring the previous gomarkdown hook's `return nil, data, 0` fallback.
-func opensRawBlock(reader text.Reader, start, end []byte) ([]byte, bool) {
+func opensRawBlock(reader text.Reader, start, end []byte) ([]byte, int, bool) {
 	line, segment := reader.PeekLine()
 	if !bytes.HasPrefix(line, start) {
-		return nil, false
+		return nil, 0, false
 	}
 	// Boundary guard: the byte immediately after the prefix must be '}',
 	// whitespace, or a line terminator — not another name character.
 	if rest := line[len(start):]; len(rest) > 0 &&
 		rest[0] != '}' && rest[0] != ' ' && rest[0] != '\t' &&
 		rest[0] != '\r' && rest[0] != '\n' {
-		return nil, false
+		return nil, 0, false
 	}
 	if !bytes.Contains(reader.Source()[segment.Stop:], end) {
-		return nil, false
+		return nil, 0, false
 	}
 	reader.AdvanceToEOL()
-	return line, true
+	return line, sourceLine(reader.Source(), segment.Start), true
+}
+
+// sourceLine returns the 1-based line number of byte offset in source.
+func sourceLine(source []byte, offset int) int {
+	return bytes.Count(source[:offset], []byte("\n")) + 1
 }
 
 // continueRawBlock accumulates the current line into node's body lines
@@ -93,9 +102,16 @@ func continueRawBlock(node gast.Node, reader text.Reader, end []byte) parser.Sta
 }
 
 // rawBlockText returns node's accumulated body lines, trimmed, ready for
-// structural parsing.
-func rawBlockText(node gast.Node, reader text.Reader) string {
-	return strings.TrimSpace(string(node.Lines().Value(reader.Source())))
+// structural parsing, and the source line the trimmed text starts at, from
+// which the parse functions number their items.
+func rawBlockText(node gast.Node, reader text.Reader) (string, int) {
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		if segment := lines.At(i); len(bytes.TrimSpace(segment.Value(reader.Source()))) > 0 {
+			return strings.TrimSpace(string(lines.Value(reader.Source()))), sourceLine(reader.Source(), segment.Start)
+		}
+	}
+	return "", 0
 }
 
 // ---------------------------------------------------------------------
@@ -109,11 +125,11 @@ func newVocabularyParser() parser.BlockParser { return &vocabularyParser{} }
 func (b *vocabularyParser) Trigger() []byte { return []byte{'{'} }
 
 func (b *vocabularyParser) Open(parent gast.Node, reader text.Reader, pc parser.Context) (gast.Node, parser.State) {
-	markerLine, ok := opensRawBlock(reader, startVocabulary, endVocabulary)
+	markerLine, line, ok := opensRawBlock(reader, startVocabulary, endVocabulary)
 	if !ok {
 		return nil, parser.NoChildren
 	}
-	n := &Vocabulary{}
+	n := &Vocabulary{Line: line}
 	attrs, err := parseMarkerAttrs(markerLine, "vocabulary")
 	if err != nil {
 		n.Err = err
@@ -127,6 +143,7 @@ func (b *vocabularyParser) Open(parent gast.Node, reader text.Reader, pc parser.
 		n.Lang = attrs.Lang
 		n.Script = attrs.Script
 	}
+	n.Err = atLine(n.Line, n.Err)
 	return n, parser.NoChildren
 }
 
@@ -147,41 +164,42 @@ func (b *vocabularyParser) CanAcceptIndentedLine() bool { return false }
 // trailing `= translation`, then trailing `[transcription]`, then trailing
 // `{grammar}`; whatever remains is the phrase.
 //
-// NOTE: a line that becomes the empty string after the `=` split makes the
-// trailing-`]`/`}` checks below index s[len(s)-1:] on an empty string,
-// which panics. This mirrors a pre-existing bug in the ported gomarkdown
-// code (markdown-vocabulary.go) and is deliberately left as-is per the
-// approved migration plan.
-func parseVocabularyItems(inner string) []VocabularyItem {
+// A line that is empty after the `=` split (e.g. "= foo") has no phrase:
+// the trailing-`]`/`}` checks are skipped rather than indexing an empty
+// string, and an unmatched `]` or `}` stays part of the phrase, the same
+// guard parseModelsItems uses.
+func parseVocabularyItems(inner string, line int) []VocabularyItem {
 	var items []VocabularyItem
 
-	for _, line := range strings.Split(inner, "\n") {
-		s := strings.TrimSpace(line)
+	for i, l := range strings.Split(inner, "\n") {
+		s := strings.TrimSpace(l)
 		if s == "" {
 			continue
 		}
 
 		// Header recognition (SPECS §3.1/§5); no note support for vocabulary (D1).
 		if level, text, ok := isBlockHeader(s); ok {
-			items = append(items, VocabularyItem{BlockAnnotation: BlockAnnotation{Kind: ItemHeader, Level: level, Text: text}})
+			items = append(items, VocabularyItem{BlockAnnotation: BlockAnnotation{Kind: ItemHeader, Level: level, Text: text, Line: line + i}})
 			continue
 		}
 
-		var item VocabularyItem
+		item := VocabularyItem{BlockAnnotation: BlockAnnotation{Line: line + i}}
 
-		if i := strings.LastIndex(s, "="); i != -1 {
-			item.Translation = strings.TrimSpace(s[i+1:])
-			s = strings.TrimSpace(s[:i])
+		if j := strings.LastIndex(s, "="); j != -1 {
+			item.Translation = strings.TrimSpace(s[j+1:])
+			s = strings.TrimSpace(s[:j])
 		}
-		if s[len(s)-1:] == "]" {
-			i := strings.LastIndex(s, "[")
-			item.Transcription = strings.TrimSpace(s[i+1 : len(s)-1])
-			s = strings.TrimSpace(s[:i])
+		if s != "" && s[len(s)-1:] == "]" {
+			if j := strings.LastIndex(s, "["); j != -1 {
+				item.Transcription = strings.TrimSpace(s[j+1 : len(s)-1])
+				s = strings.TrimSpace(s[:j])
+			}
 		}
-		if s[len(s)-1:] == "}" {
-			i := strings.LastIndex(s, "{")
-			item.Grammar = strings.TrimSpace(s[i+1 : len(s)-1])
-			s = strings.TrimSpace(s[:i])
+		if s != "" && s[len(s)-1:] == "}" {
+			if j := strings.LastIndex(s, "{"); j != -1 {
+				item.Grammar = strings.TrimSpace(s[j+1 : len(s)-1])
+				s = strings.TrimSpace(s[:j])
+			}
 		}
 		item.Phrase = s
 
@@ -202,11 +220,11 @@ func newDialogParser() parser.BlockParser { return &dialogParser{} }
 func (b *dialogParser) Trigger() []byte { return []byte{'{'} }
 
 func (b *dialogParser) Open(parent gast.Node, reader text.Reader, pc parser.Context) (gast.Node, parser.State) {
-	markerLine, ok := opensRawBlock(reader, startDialog, endDialog)
+	markerLine, line, ok := opensRawBlock(reader, startDialog, endDialog)
 	if !ok {
 		return nil, parser.NoChildren
 	}
-	n := &Dialog{}
+	n := &Dialog{Line: line}
 	attrs, err := parseMarkerAttrs(markerLine, "dialog")
 	if err != nil {
 		n.Err = err
@@ -227,6 +245,7 @@ func (b *dialogParser) Open(parent gast.Node, reader text.Reader, pc parser.Cont
 		n.Lang = attrs.Lang
 		n.Script = attrs.Script
 	}
+	n.Err = atLine(n.Line, n.Err)
 	return n, parser.NoChildren
 }
 
@@ -259,23 +278,26 @@ func (b *dialogParser) CanAcceptIndentedLine() bool { return false }
 // original's hard stop as closely as an error-based design allows); the
 // Dialog NodeRenderer surfaces it out of ToHTML instead of exiting the
 // program.
-func parseDialogItems(inner string) ([]DialogItem, error) {
+func parseDialogItems(inner string, line int) ([]DialogItem, error) {
 	var items []DialogItem
 	var buf []string
 	header := ""
+	turnLine := 0 // the turn's header line, or its first content line
 
 	flush := func() {
 		if len(buf) > 0 {
 			items = append(items, DialogItem{
-				Header:  header,
-				Content: strings.TrimSpace(strings.Join(buf, "\n")),
+				BlockAnnotation: BlockAnnotation{Line: turnLine},
+				Header:          header,
+				Content:         strings.TrimSpace(strings.Join(buf, "\n")),
 			})
 			buf = nil
 		}
+		turnLine = 0
 	}
 
-	for _, line := range strings.Split(inner, "\n") {
-		s := strings.TrimRight(line, " *")
+	for i, l := range strings.Split(inner, "\n") {
+		s := strings.TrimRight(l, " *")
 
 		// Block-level header/note recognition on un-indented lines (SPECS §3.3/§5).
 		// Indented lines (starting with spaces) are preserved as turn content by
@@ -285,13 +307,13 @@ func parseDialogItems(inner string) ([]DialogItem, error) {
 		// speaker label from producing a spurious empty DialogItem (SPECS §5 F6).
 		if level, text, ok := isBlockHeader(s); ok {
 			flush()
-			items = append(items, DialogItem{BlockAnnotation: BlockAnnotation{Kind: ItemHeader, Level: level, Text: text}})
+			items = append(items, DialogItem{BlockAnnotation: BlockAnnotation{Kind: ItemHeader, Level: level, Text: text, Line: line + i}})
 			header = ""
 			continue
 		}
 		if text, ok := isBlockNote(s); ok {
 			flush()
-			items = append(items, DialogItem{BlockAnnotation: BlockAnnotation{Kind: ItemNote, Text: text}})
+			items = append(items, DialogItem{BlockAnnotation: BlockAnnotation{Kind: ItemNote, Text: text, Line: line + i}})
 			header = ""
 			continue
 		}
@@ -299,8 +321,12 @@ func parseDialogItems(inner string) ([]DialogItem, error) {
 		if isDialogItemHeader(s) {
 			flush()
 			header = getDialogItemHeader(s)
+			turnLine = line + i
 			continue
 		}
+		if turnLine == 0 {
+			turnLine = line + i
+		}
 		if s == "" {
 			buf = append(buf, s)
 			continue
@@ -310,7 +336,7 @@ func parseDialogItems(inner string) ([]DialogItem, error) {
 			continue
 		}
 
-		return items, fmt.Errorf("Wrong line indentation for dialog item: %s", s)
+		return items, atLine(line+i, fmt.Errorf("Wrong line indentation for dialog item: %s", s))
 	}
 	flush()
 
@@ -398,11 +424,11 @@ func newParallelParser() parser.BlockParser { return &parallelParser{} }
 func (b *parallelParser) Trigger() []byte { return []byte{'{'} }
 
 func (b *parallelParser) Open(parent gast.Node, reader text.Reader, pc parser.Context) (gast.Node, parser.State) {
-	markerLine, ok := opensRawBlock(reader, startParallel, endParallel)
+	markerLine, line, ok := opensRawBlock(reader, startParallel, endParallel)
 	if !ok {
 		return nil, parser.NoChildren
 	}
-	n := &Parallel{}
+	n := &Parallel{Line: line}
 	attrs, err := parseMarkerAttrs(markerLine, "parallel")
 	if err != nil {
 		n.Err = err
@@ -415,6 +441,7 @@ func (b *parallelParser) Open(parent gast.Node, reader text.Reader, pc parser.Co
 		n.Lang = attrs.Lang
 		n.Script = attrs.Script
 	}
+	n.Err = atLine(n.Line, n.Err)
 	return n, parser.NoChildren
 }
 
@@ -448,17 +475,19 @@ func (b *parallelParser) CanAcceptIndentedLine() bool { return false }
 // the block line-by-line instead, see opensRawBlock/continueRawBlock
 // above), so the old "used vocabulary's marker length instead of
 // parallel's" bug class cannot recur here.
-func parseParallelRows(inner string) []ParallelRow {
+func parseParallelRows(inner string, line int) []ParallelRow {
 	var rows []ParallelRow
 
 	for _, chunk := range strings.Split(inner, "\n===\n") {
+		rowLine := line + leadingBlankLines(chunk)
+		line += strings.Count(chunk, "\n") + 2
 		s := strings.TrimSpace(chunk)
 		if s == "" {
 			continue
 		}
 
 		fields := strings.SplitN(s, "\n---\n", 3)
-		var row ParallelRow
+		row := ParallelRow{Line: rowLine}
 		row.SourceRaw = strings.TrimSpace(fields[0])
 		if len(fields) >= 2 {
 			row.TranslationRaw = strings.TrimSpace(fields[1])
@@ -484,11 +513,11 @@ func newParallelDialogParser() parser.BlockParser { return &parallelDialogParser
 func (b *parallelDialogParser) Trigger() []byte { return []byte{'{'} }
 
 func (b *parallelDialogParser) Open(parent gast.Node, reader text.Reader, pc parser.Context) (gast.Node, parser.State) {
-	markerLine, ok := opensRawBlock(reader, startParallelDialog, endParallelDialog)
+	markerLine, line, ok := opensRawBlock(reader, startParallelDialog, endParallelDialog)
 	if !ok {
 		return nil, parser.NoChildren
 	}
-	n := &ParallelDialog{}
+	n := &ParallelDialog{Line: line}
 	attrs, err := parseMarkerAttrs(markerLine, "parallel-dialog")
 	if err != nil {
 		n.Err = err
@@ -501,6 +530,7 @@ func (b *parallelDialogParser) Open(parent gast.Node, reader text.Reader, pc par
 		n.Lang = attrs.Lang
 		n.Script = attrs.Script
 	}
+	n.Err = atLine(n.Line, n.Err)
 	return n, parser.NoChildren
 }
 
@@ -532,10 +562,12 @@ func (b *parallelDialogParser) CanAcceptIndentedLine() bool { return false }
 // parse as exactly one dialog turn or heading (parseParallelDialogField);
 // translation is mandatory (a row with no "---" errors, unlike plain
 // {start-parallel} where the translation field is optional prose).
-func parseParallelDialogRows(inner string) ([]ParallelDialogRow, error) {
+func parseParallelDialogRows(inner string, line int) ([]ParallelDialogRow, error) {
 	var rows []ParallelDialogRow
 
 	for _, chunk := range strings.Split(inner, "\n===\n") {
+		rowLine := line + leadingBlankLines(chunk)
+		line += strings.Count(chunk, "\n") + 2
 		s := strings.TrimSpace(chunk)
 		if s == "" {
 			continue
@@ -543,20 +575,26 @@ func parseParallelDialogRows(inner string) ([]ParallelDialogRow, error) {
 
 		fields := strings.SplitN(s, "\n---\n", 3)
 		if len(fields) < 2 {
-			return nil, fmt.Errorf("parallel-dialog row is missing its translation field: %q", s)
+			return nil, atLine(rowLine, fmt.Errorf("parallel-dialog row is missing its translation field: %q", s))
+		}
+		// fieldLines[k] is the line field k's turn or heading starts at.
+		fieldLines := make([]int, len(fields))
+		for k, fieldLine := 0, rowLine; k < len(fields); k++ {
+			fieldLines[k] = fieldLine + leadingBlankLines(fields[k])
+			fieldLine += strings.Count(fields[k], "\n") + 2
 		}
 
-		var row ParallelDialogRow
+		row := ParallelDialogRow{Line: rowLine}
 		var err error
-		if row.Source, err = parseParallelDialogField(fields[0]); err != nil {
-			return nil, fmt.Errorf("parallel-dialog source field: %w", err)
+		if row.Source, err = parseParallelDialogField(fields[0], fieldLines[0]); err != nil {
+			return nil, atLine(fieldLines[0], fmt.Errorf("parallel-dialog source field: %w", err))
 		}
-		if row.Translation, err = parseParallelDialogField(fields[1]); err != nil {
-			return nil, fmt.Errorf("parallel-dialog translation field: %w", err)
+		if row.Translation, err = parseParallelDialogField(fields[1], fieldLines[1]); err != nil {
+			return nil, atLine(fieldLines[1], fmt.Errorf("parallel-dialog translation field: %w", err))
 		}
 		if len(fields) == 3 {
-			if row.Transcription, err = parseParallelDialogField(fields[2]); err != nil {
-				return nil, fmt.Errorf("parallel-dialog transcription field: %w", err)
+			if row.Transcription, err = parseParallelDialogField(fields[2], fieldLines[2]); err != nil {
+				return nil, atLine(fieldLines[2], fmt.Errorf("parallel-dialog transcription field: %w", err))
 			}
 			row.HasTranscription = true
 		}
@@ -574,7 +612,7 @@ func parseParallelDialogRows(inner string) ([]ParallelDialogRow, error) {
 // single field instead of a whole block. Zero or more-than-one resulting
 // item is an error: unlike a {start-dialog} block, a parallel-dialog field
 // never holds a run of several turns.
-func parseParallelDialogField(field string) (ParallelDialogItem, error) {
+func parseParallelDialogField(field string, line int) (ParallelDialogItem, error) {
 	// Trim leading/trailing blank lines first, mirroring parseParallelRows's
 	// per-field strings.TrimSpace. Without this, a field that isn't the row's
 	// first (i.e. one following a "---"/"===" separator that itself has a
@@ -631,9 +669,141 @@ func parseParallelDialogField(field string) (ParallelDialogItem, error) {
 	if len(items) != 1 {
 		return ParallelDialogItem{}, fmt.Errorf("field must contain exactly one turn or heading, got %d item(s): %q", len(items), field)
 	}
+	items[0].Line = line
 	return items[0], nil
 }
 
+// ---------------------------------------------------------------------
+// Interlinear
+// ---------------------------------------------------------------------
+
+type interlinearParser struct{}
+
+func newInterlinearParser() parser.BlockParser { return &interlinearParser{} }
+
+func (b *interlinearParser) Trigger() []byte { return []byte{'{'} }
+
+func (b *interlinearParser) Open(parent gast.Node, reader text.Reader, pc parser.Context) (gast.Node, parser.State) {
+	markerLine, line, ok := opensRawBlock(reader, startInterlinear, endInterlinear)
+	if !ok {
+		return nil, parser.NoChildren
+	}
+	n := &Interlinear{Line: line}
+	attrs, err := parseMarkerAttrs(markerLine, "interlinear")
+	if err != nil {
+		n.Err = err
+	} else {
+		// The line roles are fixed by position (source, transliteration,
+		// gloss, translation), so as= is rejected, as for vocabulary/models.
+		if attrs.As != "" {
+			n.Err = fmt.Errorf("as= not applicable to {start-interlinear}: its line languages are fixed")
+		}
+		n.Lang = attrs.Lang
+		n.Script = attrs.Script
+	}
+	n.Err = atLine(n.Line, n.Err)
+	return n, parser.NoChildren
+}
+
+func (b *interlinearParser) Continue(node gast.Node, reader text.Reader, pc parser.Context) parser.State {
+	return continueRawBlock(node, reader, endInterlinear)
+}
+
+func (b *interlinearParser) Close(node gast.Node, reader text.Reader, pc parser.Context) {
+	n := node.(*Interlinear)
+	items, err := parseInterlinearItems(rawBlockText(node, reader))
+	n.Items = items
+	// Keep an Open-time attribute error; see dialogParser.Close.
+	if n.Err == nil {
+		n.Err = err
+	}
+}
+
+func (b *interlinearParser) CanInterruptParagraph() bool { return true }
+func (b *interlinearParser) CanAcceptIndentedLine() bool { return false }
+
+// parseInterlinearItems parses the dedented `{start-interlinear}` body into
+// examples. Examples are separated by blank lines; each is three lines —
+// source, morpheme gloss, free translation — or four, with a
+// transliteration line after the source. The source, transliteration and
+// gloss lines are split on whitespace into aligned tokens, so a word that
+// glosses as several morphemes joins them with Leipzig separators
+// ("dog-PL", "go.PST") rather than spaces. A `#` heading or `(...)` note
+// line is recognized only where an example would start, so a free
+// translation written in parentheses is never mistaken for a note.
+//
+// A malformed example (wrong line count, or token counts that differ
+// between its lines) stops parsing with an error naming the source line,
+// which the renderers surface, mirroring parseDialogItems.
+func parseInterlinearItems(inner string, line int) ([]InterlinearItem, error) {
+	var items []InterlinearItem
+	var example []string
+	exampleLine := 0
+
+	flush := func() error {
+		if len(example) == 0 {
+			return nil
+		}
+		lines := example
+		example = nil
+		if len(lines) != 3 && len(lines) != 4 {
+			return atLine(exampleLine, fmt.Errorf("interlinear example must have 3 lines (source, gloss, translation) or 4 (source, transliteration, gloss, translation), got %d: %s", len(lines), lines[0]))
+		}
+
+		source := strings.Fields(lines[0])
+		gloss := strings.Fields(lines[len(lines)-2])
+		if len(gloss) != len(source) {
+			return atLine(exampleLine, fmt.Errorf("interlinear gloss line has %d tokens but source line has %d: %s", len(gloss), len(source), lines[0]))
+		}
+		var transliteration []string
+		if len(lines) == 4 {
+			transliteration = strings.Fields(lines[1])
+			if len(transliteration) != len(source) {
+				return atLine(exampleLine, fmt.Errorf("interlinear transliteration line has %d tokens but source line has %d: %s", len(transliteration), len(source), lines[0]))
+			}
+		}
+
+		item := InterlinearItem{BlockAnnotation: BlockAnnotation{Line: exampleLine}, Translation: lines[len(lines)-1]}
+		for i := range source {
+			word := InterlinearWord{Source: source[i], Gloss: gloss[i]}
+			if transliteration != nil {
+				word.Transliteration = transliteration[i]
+			}
+			item.Words = append(item.Words, word)
+		}
+		items = append(items, item)
+		return nil
+	}
+
+	for i, l := range strings.Split(inner, "\n") {
+		s := strings.TrimSpace(l)
+		if s == "" {
+			if err := flush(); err != nil {
+				return items, err
+			}
+			continue
+		}
+
+		if len(example) == 0 {
+			if level, text, ok := isBlockHeader(s); ok {
+				items = append(items, InterlinearItem{BlockAnnotation: BlockAnnotation{Kind: ItemHeader, Level: level, Text: text, Line: line + i}})
+				continue
+			}
+			if text, ok := isBlockNote(s); ok {
+				items = append(items, InterlinearItem{BlockAnnotation: BlockAnnotation{Kind: ItemNote, Text: text, Line: line + i}})
+				continue
+			}
+			exampleLine = line + i
+		}
+		example = append(example, s)
+	}
+	if err := flush(); err != nil {
+		return items, err
+	}
+
+	return items, nil
+}
+
 // ---------------------------------------------------------------------
 // Models
 // ---------------------------------------------------------------------
@@ -645,11 +815,11 @@ func newModelsParser() parser.BlockParser { return &modelsParser{} }
 func (b *modelsParser) Trigger() []byte { return []byte{'{'} }
 
 func (b *modelsParser) Open(parent gast.Node, reader text.Reader, pc parser.Context) (gast.Node, parser.State) {
-	markerLine, ok := opensRawBlock(reader, startModels, endModels)
+	markerLine, line, ok := opensRawBlock(reader, startModels, endModels)
 	if !ok {
 		return nil, parser.NoChildren
 	}
-	n := &Models{}
+	n := &Models{Line: line}
 	attrs, err := parseMarkerAttrs(markerLine, "models")
 	if err != nil {
 		n.Err = err
@@ -662,6 +832,7 @@ func (b *modelsParser) Open(parent gast.Node, reader text.Reader, pc parser.Cont
 		n.Lang = attrs.Lang
 		n.Script = attrs.Script
 	}
+	n.Err = atLine(n.Line, n.Err)
 	return n, parser.NoChildren
 }
 
@@ -685,40 +856,39 @@ func (b *modelsParser) CanAcceptIndentedLine() bool { return false }
 // migration decision, mirroring parseQuestionsItems below for the same
 // reason).
 //
-// Guard (agreed migration decision, deliberately diverging from
-// vocabulary's preserved empty-phrase panic): if the `=` split leaves s
+// Guard (shared with parseVocabularyItems): if the `=` split leaves s
 // empty, the trailing-`]` check is skipped instead of indexing s[len(s)-1:]
 // on an empty string, and the `[` lookup is skipped entirely when no
 // matching `[` is found, so a malformed line never panics.
-func parseModelsItems(inner string) []ModelsItem {
+func parseModelsItems(inner string, line int) []ModelsItem {
 	var items []ModelsItem
 
-	for _, line := range strings.Split(inner, "\n") {
-		s := strings.TrimSpace(line)
+	for i, l := range strings.Split(inner, "\n") {
+		s := strings.TrimSpace(l)
 		if s == "" {
 			continue
 		}
 
 		// Header then note recognition (SPECS §3.1/§3.2/§5).
 		if level, text, ok := isBlockHeader(s); ok {
-			items = append(items, ModelsItem{BlockAnnotation: BlockAnnotation{Kind: ItemHeader, Level: level, Text: text}})
+			items = append(items, ModelsItem{BlockAnnotation: BlockAnnotation{Kind: ItemHeader, Level: level, Text: text, Line: line + i}})
 			continue
 		}
 		if text, ok := isBlockNote(s); ok {
-			items = append(items, ModelsItem{BlockAnnotation: BlockAnnotation{Kind: ItemNote, Text: text}})
+			items = append(items, ModelsItem{BlockAnnotation: BlockAnnotation{Kind: ItemNote, Text: text, Line: line + i}})
 			continue
 		}
 
-		var item ModelsItem
+		item := ModelsItem{BlockAnnotation: BlockAnnotation{Line: line + i}}
 
-		if i := strings.Index(s, " = "); i != -1 {
-			item.Translation = strings.TrimSpace(s[i+len(" = "):])
-			s = strings.TrimSpace(s[:i])
+		if j := strings.Index(s, " = "); j != -1 {
+			item.Translation = strings.TrimSpace(s[j+len(" = "):])
+			s = strings.TrimSpace(s[:j])
 		}
 		if s != "" && s[len(s)-1:] == "]" {
-			if i := strings.LastIndex(s, "["); i != -1 {
-				item.Transcription = strings.TrimSpace(s[i+1 : len(s)-1])
-				s = strings.TrimSpace(s[:i])
+			if j := strings.LastIndex(s, "["); j != -1 {
+				item.Transcription = strings.TrimSpace(s[j+1 : len(s)-1])
+				s = strings.TrimSpace(s[:j])
 			}
 		}
 		item.Phrase = s
@@ -740,11 +910,11 @@ func newQuestionsParser() parser.BlockParser { return &questionsParser{} }
 func (b *questionsParser) Trigger() []byte { return []byte{'{'} }
 
 func (b *questionsParser) Open(parent gast.Node, reader text.Reader, pc parser.Context) (gast.Node, parser.State) {
-	markerLine, ok := opensRawBlock(reader, startQuestions, endQuestions)
+	markerLine, line, ok := opensRawBlock(reader, startQuestions, endQuestions)
 	if !ok {
 		return nil, parser.NoChildren
 	}
-	n := &Questions{}
+	n := &Questions{Line: line}
 	attrs, err := parseMarkerAttrs(markerLine, "questions")
 	if err != nil {
 		n.Err = err
@@ -763,6 +933,7 @@ func (b *questionsParser) Open(parent gast.Node, reader text.Reader, pc parser.C
 		n.Lang = attrs.Lang
 		n.Script = attrs.Script
 	}
+	n.Err = atLine(n.Line, n.Err)
 	return n, parser.NoChildren
 }
 
@@ -794,11 +965,11 @@ func (b *textParser) Trigger() []byte { return []byte{'{'} }
 // (OI-9). An attribute-parse error is stored on Text.Err and surfaced at
 // render time, mirroring Dialog.Err.
 func (b *textParser) Open(parent gast.Node, reader text.Reader, pc parser.Context) (gast.Node, parser.State) {
-	markerLine, ok := opensRawBlock(reader, startText, endText)
+	markerLine, line, ok := opensRawBlock(reader, startText, endText)
 	if !ok {
 		return nil, parser.NoChildren
 	}
-	n := &Text{}
+	n := &Text{Line: line}
 	attrs, err := parseMarkerAttrs(markerLine, "text")
 	if err != nil {
 		n.Err = err
@@ -811,6 +982,7 @@ func (b *textParser) Open(parent gast.Node, reader text.Reader, pc parser.Contex
 		n.Script = attrs.Script
 		n.System = attrs.System
 	}
+	n.Err = atLine(n.Line, n.Err)
 	return n, parser.NoChildren
 }
 
@@ -823,7 +995,7 @@ func (b *textParser) Continue(node gast.Node, reader text.Reader, pc parser.Cont
 // Dialog/Parallel's rawBlockText pattern, SPECS §3.2).
 func (b *textParser) Close(node gast.Node, reader text.Reader, pc parser.Context) {
 	n := node.(*Text)
-	n.Raw = rawBlockText(node, reader)
+	n.Raw, n.RawLine = rawBlockText(node, reader)
 }
 
 func (b *textParser) CanInterruptParagraph() bool { return true }
@@ -834,29 +1006,29 @@ func (b *textParser) CanAcceptIndentedLine() bool { return false }
 // is prose and may itself contain "=", so splitting at the LAST occurrence
 // (vocabulary's convention) would mis-split it. A line with no " = " is a
 // question-only line (Answer stays "").
-func parseQuestionsItems(inner string) []QuestionItem {
+func parseQuestionsItems(inner string, line int) []QuestionItem {
 	var items []QuestionItem
 
-	for _, line := range strings.Split(inner, "\n") {
-		s := strings.TrimSpace(line)
+	for i, l := range strings.Split(inner, "\n") {
+		s := strings.TrimSpace(l)
 		if s == "" {
 			continue
 		}
 
 		// Header then note recognition (SPECS §3.1/§3.2/§5).
 		if level, text, ok := isBlockHeader(s); ok {
-			items = append(items, QuestionItem{BlockAnnotation: BlockAnnotation{Kind: ItemHeader, Level: level, Text: text}})
+			items = append(items, QuestionItem{BlockAnnotation: BlockAnnotation{Kind: ItemHeader, Level: level, Text: text, Line: line + i}})
 			continue
 		}
 		if text, ok := isBlockNote(s); ok {
-			items = append(items, QuestionItem{BlockAnnotation: BlockAnnotation{Kind: ItemNote, Text: text}})
+			items = append(items, QuestionItem{BlockAnnotation: BlockAnnotation{Kind: ItemNote, Text: text, Line: line + i}})
 			continue
 		}
 
-		var item QuestionItem
-		if i := strings.Index(s, " = "); i != -1 {
-			item.Question = strings.TrimSpace(s[:i])
-			item.Answer = strings.TrimSpace(s[i+len(" = "):])
+		item := QuestionItem{BlockAnnotation: BlockAnnotation{Line: line + i}}
+		if j := strings.Index(s, " = "); j != -1 {
+			item.Question = strings.TrimSpace(s[:j])
+			item.Answer = strings.TrimSpace(s[j+len(" = "):])
 		} else {
 			item.Question = s
 		}
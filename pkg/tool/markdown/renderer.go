@@ -137,7 +137,7 @@ func renderDialog(w util.BufWriter, source []byte, node gast.Node, entering bool
 		// ItemData: existing dialog-item emission.
 		content, err := ToHTML([]byte(item.Content))
 		if err != nil {
-			return gast.WalkStop, err
+			return gast.WalkStop, nestedError(item.Line, err)
 		}
 		io.WriteString(w, "<div class=\"dialog-item\">\n<div class=\"dialog-header\">")
 		io.WriteString(w, item.Header)
@@ -179,7 +179,7 @@ func renderParallel(w util.BufWriter, source []byte, node gast.Node, entering bo
 		// Primary column: source always; transcription stacked below when present.
 		sourceContent, err := ToHTML([]byte(row.SourceRaw))
 		if err != nil {
-			return gast.WalkStop, err
+			return gast.WalkStop, nestedError(row.Line, err)
 		}
 		io.WriteString(w, "<div class=\"parallel-cell main\">\n")
 		io.WriteString(w, "<div class=\"parallel-source\" dir=\"")
@@ -190,7 +190,7 @@ func renderParallel(w util.BufWriter, source []byte, node gast.Node, entering bo
 		if row.TranscriptionRaw != "" {
 			transcriptionContent, err := ToHTML([]byte(row.TranscriptionRaw))
 			if err != nil {
-				return gast.WalkStop, err
+				return gast.WalkStop, nestedError(row.Line, err)
 			}
 			io.WriteString(w, "<div class=\"parallel-transcription\" dir=\"ltr\">\n")
 			w.Write(transcriptionContent)
@@ -202,7 +202,7 @@ func renderParallel(w util.BufWriter, source []byte, node gast.Node, entering bo
 		if row.TranslationRaw != "" {
 			translationContent, err := ToHTML([]byte(row.TranslationRaw))
 			if err != nil {
-				return gast.WalkStop, err
+				return gast.WalkStop, nestedError(row.Line, err)
 			}
 			io.WriteString(w, "<div class=\"parallel-cell secondary\">\n")
 			w.Write(translationContent)
@@ -283,7 +283,7 @@ func renderParallelDialogItem(w util.BufWriter, item ParallelDialogItem) error {
 	}
 	content, err := ToHTML([]byte(item.Content))
 	if err != nil {
-		return err
+		return nestedError(item.Line, err)
 	}
 	io.WriteString(w, "<div class=\"parallel-dialog-item\">\n<div class=\"parallel-dialog-header\">")
 	io.WriteString(w, item.Header)
@@ -293,6 +293,71 @@ func renderParallelDialogItem(w util.BufWriter, item ParallelDialogItem) error {
 	return nil
 }
 
+// renderInterlinear emits the `<div class="interlinear">` wrapper: one
+// "interlinear-example" per example, holding an "interlinear-words" row of
+// "interlinear-word" columns (source over the optional transliteration over
+// the gloss), then the free translation. Each column is its own element so
+// CSS can set them inline-block and every gloss stays under its word at any
+// line wrap; the wrapper's dir orders the columns for an RTL source, while
+// the transliteration, gloss and translation are pinned ltr. Category
+// labels in the gloss are wrapped for small caps (glossLabelsHTML). A
+// token-count mismatch recorded at parse time stops rendering and surfaces
+// the error, mirroring renderDialog.
+func renderInterlinear(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	n := node.(*Interlinear)
+	if n.Err != nil {
+		return gast.WalkStop, n.Err
+	}
+
+	dir := blockDirection(n.Script)
+	io.WriteString(w, badgeOnlyHTML("I"))
+	io.WriteString(w, "<div class=\"interlinear")
+	io.WriteString(w, scriptClass(n.Script))
+	io.WriteString(w, "\" dir=\"")
+	io.WriteString(w, dir)
+	io.WriteString(w, "\">\n")
+	for _, item := range n.Items {
+		switch item.Kind {
+		case ItemHeader:
+			fmt.Fprintf(w, "<h%d>%s</h%d>\n", item.Level, item.Text, item.Level)
+			continue
+		case ItemNote:
+			io.WriteString(w, "<p class=\"block-note\">")
+			io.WriteString(w, item.Text)
+			io.WriteString(w, "</p>\n")
+			continue
+		}
+		io.WriteString(w, "<div class=\"interlinear-example\">\n")
+		io.WriteString(w, "<div class=\"interlinear-words\">\n")
+		for _, word := range item.Words {
+			io.WriteString(w, "<div class=\"interlinear-word\">\n")
+			io.WriteString(w, "<span class=\"interlinear-source\">")
+			io.WriteString(w, word.Source)
+			io.WriteString(w, "</span>\n")
+			if word.Transliteration != "" {
+				io.WriteString(w, "<span class=\"interlinear-transliteration\" dir=\"ltr\">")
+				io.WriteString(w, word.Transliteration)
+				io.WriteString(w, "</span>\n")
+			}
+			io.WriteString(w, "<span class=\"interlinear-gloss\" dir=\"ltr\">")
+			io.WriteString(w, glossLabelsHTML(word.Gloss))
+			io.WriteString(w, "</span>\n")
+			io.WriteString(w, "</div>\n")
+		}
+		io.WriteString(w, "</div>\n")
+		io.WriteString(w, "<p class=\"interlinear-translation\" dir=\"ltr\">")
+		io.WriteString(w, item.Translation)
+		io.WriteString(w, "</p>\n")
+		io.WriteString(w, "</div>\n")
+	}
+	io.WriteString(w, "</div>\n")
+
+	return gast.WalkContinue, nil
+}
+
 // renderModels emits the `<div class="models">` wrapper (SPECS decision:
 // like vocabulary minus grammar/notes). Per item:
 //   - phrase only (no transcription, no translation) renders as a plain,
@@ -520,7 +585,7 @@ func renderTextblock(w util.BufWriter, source []byte, node gast.Node, entering b
 	if n.Raw != "" {
 		content, err := ToHTML([]byte(n.Raw))
 		if err != nil {
-			return gast.WalkStop, err
+			return gast.WalkStop, offsetError(n.RawLine, err)
 		}
 		body = string(content)
 	}
@@ -537,7 +602,8 @@ func renderTextblock(w util.BufWriter, source []byte, node gast.Node, entering b
 	return gast.WalkContinue, nil
 }
 
-// vocabularyRenderer, dialogRenderer, parallelRenderer, modelsRenderer,
+// vocabularyRenderer, dialogRenderer, parallelRenderer,
+// parallelDialogRenderer, interlinearRenderer, modelsRenderer,
 // questionsRenderer and textHTMLRenderer are thin renderer.NodeRenderer
 // adapters that register the render funcs above.
 
@@ -565,6 +631,12 @@ func (r *parallelDialogRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegi
 	reg.Register(KindParallelDialog, renderParallelDialog)
 }
 
+type interlinearRenderer struct{}
+
+func (r *interlinearRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindInterlinear, renderInterlinear)
+}
+
 type modelsRenderer struct{}
 
 func (r *modelsRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
@@ -57,6 +57,7 @@ func ToTypst(source []byte) ([]byte, error) {
 }
 
 // FileToTypst reads filename and converts its content into Typst markup.
+// A conversion error names filename and the line (InFile).
 func FileToTypst(filename string) (string, error) {
 	source, err := os.ReadFile(filename)
 	if err != nil {
@@ -64,7 +65,7 @@ func FileToTypst(filename string) (string, error) {
 	}
 	body, err := ToTypst(source)
 	if err != nil {
-		return "", err
+		return "", InFile(filename, err)
 	}
 	return string(body), nil
 }
@@ -60,6 +60,7 @@ func (r *typstNodeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegistere
 	reg.Register(KindModels, renderModelsTypst)
 	reg.Register(KindQuestions, renderQuestionsTypst)
 	reg.Register(KindParallelDialog, renderParallelDialogTypst)
+	reg.Register(KindInterlinear, renderInterlinearTypst)
 	// KindText MUST be registered last (highest ordinal, ASR-1 panic-gate).
 	reg.Register(KindText, renderTextblockTypst)
 }
@@ -545,7 +546,7 @@ func renderDialogTypst(w util.BufWriter, source []byte, node gast.Node, entering
 		default: // ItemData — unchanged dict shape (ASR-3)
 			content, err := ToTypst([]byte(item.Content))
 			if err != nil {
-				return gast.WalkStop, err
+				return gast.WalkStop, nestedError(item.Line, err)
 			}
 			io.WriteString(w, `  (header: "`)
 			io.WriteString(w, escapeTypstString(item.Header))
@@ -584,7 +585,7 @@ func renderParallelTypst(w util.BufWriter, source []byte, node gast.Node, enteri
 	for _, row := range n.Rows {
 		sourceContent, err := ToTypst([]byte(row.SourceRaw))
 		if err != nil {
-			return gast.WalkStop, err
+			return gast.WalkStop, nestedError(row.Line, err)
 		}
 		io.WriteString(w, "  (source: [")
 		w.Write(sourceContent)
@@ -592,7 +593,7 @@ func renderParallelTypst(w util.BufWriter, source []byte, node gast.Node, enteri
 		if row.TranslationRaw != "" {
 			translationContent, err := ToTypst([]byte(row.TranslationRaw))
 			if err != nil {
-				return gast.WalkStop, err
+				return gast.WalkStop, nestedError(row.Line, err)
 			}
 			w.Write(translationContent)
 		}
@@ -600,7 +601,7 @@ func renderParallelTypst(w util.BufWriter, source []byte, node gast.Node, enteri
 		if row.TranscriptionRaw != "" {
 			transcriptionContent, err := ToTypst([]byte(row.TranscriptionRaw))
 			if err != nil {
-				return gast.WalkStop, err
+				return gast.WalkStop, nestedError(row.Line, err)
 			}
 			io.WriteString(w, ", transcription: [")
 			w.Write(transcriptionContent)
@@ -676,7 +677,7 @@ func writeParallelDialogItemDictTypst(w util.BufWriter, item ParallelDialogItem)
 	}
 	content, err := ToTypst([]byte(item.Content))
 	if err != nil {
-		return err
+		return nestedError(item.Line, err)
 	}
 	io.WriteString(w, `(header: "`)
 	io.WriteString(w, escapeTypstString(item.Header))
@@ -686,6 +687,65 @@ func writeParallelDialogItemDictTypst(w util.BufWriter, item ParallelDialogItem)
 	return nil
 }
 
+// renderInterlinearTypst emits `#interlinear(dir: <dir>, script: "..",
+// (words: ((source: "..", gloss: ".."), ...), translation: ".."), ...)`,
+// string-escaping every field. `transliteration:` is emitted on a word ONLY
+// when the example has a transliteration line (key omission, mirroring
+// renderParallelTypst's "transcription" in r idiom). Gloss category labels
+// are left as written: book.typ's interlinear() sets them in small caps with
+// the same pattern as glossLabelRe. A parse-time error stops rendering
+// immediately, mirroring renderDialogTypst.
+func renderInterlinearTypst(w util.BufWriter, source []byte, node gast.Node, entering bool) (gast.WalkStatus, error) {
+	if !entering {
+		return gast.WalkContinue, nil
+	}
+	n := node.(*Interlinear)
+	if n.Err != nil {
+		return gast.WalkStop, n.Err
+	}
+
+	dir := blockDirection(n.Script)
+	io.WriteString(w, badgeOnlyTypst("I"))
+	io.WriteString(w, "#interlinear(dir: ")
+	io.WriteString(w, dir)
+	io.WriteString(w, `, script: "`)
+	io.WriteString(w, escapeTypstString(n.Script))
+	io.WriteString(w, "\",\n")
+	for _, item := range n.Items {
+		switch item.Kind {
+		case ItemHeader:
+			io.WriteString(w, `  (kind: "header", level: `)
+			io.WriteString(w, strconv.Itoa(item.Level))
+			io.WriteString(w, `, text: "`)
+			io.WriteString(w, escapeTypstString(item.Text))
+			io.WriteString(w, "\"),\n")
+		case ItemNote:
+			io.WriteString(w, `  (kind: "note", text: "`)
+			io.WriteString(w, escapeTypstString(item.Text))
+			io.WriteString(w, "\"),\n")
+		default:
+			io.WriteString(w, "  (words: (")
+			for _, word := range item.Words {
+				io.WriteString(w, `(source: "`)
+				io.WriteString(w, escapeTypstString(word.Source))
+				if word.Transliteration != "" {
+					io.WriteString(w, `", transliteration: "`)
+					io.WriteString(w, escapeTypstString(word.Transliteration))
+				}
+				io.WriteString(w, `", gloss: "`)
+				io.WriteString(w, escapeTypstString(word.Gloss))
+				io.WriteString(w, `"), `)
+			}
+			io.WriteString(w, `), translation: "`)
+			io.WriteString(w, escapeTypstString(item.Translation))
+			io.WriteString(w, "\"),\n")
+		}
+	}
+	io.WriteString(w, ")\n\n")
+
+	return gast.WalkContinue, nil
+}
+
 // renderModelsTypst emits `#models((phrase:"..", transcription:"..",
 // translation:".."), ...)`, string-escaping every field (mirrors
 // renderVocabularyTypst, minus the `grammar` field). Models has no
@@ -764,7 +824,7 @@ func renderTextblockTypst(w util.BufWriter, source []byte, node gast.Node, enter
 	if n.Raw != "" {
 		content, err := ToTypst([]byte(n.Raw))
 		if err != nil {
-			return gast.WalkStop, err
+			return gast.WalkStop, offsetError(n.RawLine, err)
 		}
 		body = string(content)
 	}
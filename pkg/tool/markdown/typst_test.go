@@ -407,7 +407,7 @@ func TestToTypst_Dialog_Err(t *testing.T) {
 	if err == nil {
 		t.Fatalf("ToTypst(%q) expected a non-nil error for a badly indented dialog line, got nil (output: %q)", input, got)
 	}
-	wantErr := "Wrong line indentation for dialog item: Badly indented line"
+	wantErr := "line 3: Wrong line indentation for dialog item: Badly indented line"
 	if err.Error() != wantErr {
 		t.Fatalf("ToTypst(%q) error = %q, want %q", input, err.Error(), wantErr)
 	}
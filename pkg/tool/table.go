@@ -0,0 +1,57 @@
+package tool
+
+import (
+	"bufio"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BaseOutputName strips the extension from a project's output filename,
+// giving the base path every derived export (".pdf", ".csv", "-mdx", ...)
+// appends its own suffix to. Shared by ebook-cli and flashcard-cli.
+func BaseOutputName(filename string) string {
+	return strings.TrimSuffix(filename, filepath.Ext(filename))
+}
+
+// FlattenValue puts a value on one line for a tab-separated export: tabs,
+// line breaks and runs of spaces become single spaces.
+func FlattenValue(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// WriteTable writes rows (the first being the header) to filename as CSV
+// when comma is ',' or as TSV when it is '\t'. CSV quotes values as needed;
+// TSV has no quoting, so each value is flattened (FlattenValue).
+// The vocabulary export (ebook-cli) and the flashcard table exports share
+// it, so both formats behave the same in both tools.
+func WriteTable(filename string, rows [][]string, comma rune) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if comma == '\t' {
+		w := bufio.NewWriter(f)
+		for _, row := range rows {
+			values := make([]string, len(row))
+			for i, value := range row {
+				values[i] = FlattenValue(value)
+			}
+			w.WriteString(strings.Join(values, "\t") + "\n")
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	} else {
+		w := csv.NewWriter(f)
+		w.Comma = comma
+		if err := w.WriteAll(rows); err != nil {
+			return err
+		}
+	}
+
+	return f.Close()
+}
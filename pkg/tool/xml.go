@@ -0,0 +1,25 @@
+package tool
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// OOXMLHeader is the XML declaration that starts every part of an Office
+// Open XML package, shared by the XLSX (pkg/ebook) and DOCX (pkg/ebook,
+// pkg/tool/markdown) writers.
+const OOXMLHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+// XMLEscape escapes s for XML text and attribute values, dropping the
+// control characters XML 1.0 cannot carry (tabs and line breaks are kept).
+func XMLEscape(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, s)
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,22 @@
+package tool
+
+import "testing"
+
+// TestXMLEscape covers the markup characters and the control characters
+// XML 1.0 cannot carry.
+func TestXMLEscape(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain", "plain"},
+		{"", ""},
+		{`a<b>&"c'`, "a&lt;b&gt;&amp;&#34;c&#39;"},
+		{"a\x00b\x07c\x1fd", "abcd"},
+		{"a\tb", "a&#x9;b"},
+		{"a\nb\rc", "a&#xA;b&#xD;c"},
+		{"café 你好", "café 你好"},
+	}
+	for _, tc := range tests {
+		if got := XMLEscape(tc.in); got != tc.want {
+			t.Errorf("XMLEscape(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
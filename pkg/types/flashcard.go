@@ -1,8 +1,8 @@
 package types
 
 type FlashcardFont struct {
-	Name string `yaml:"name"`
-	Size uint32 `yaml:"size"`
+	Name string `yaml:"name,omitempty"`
+	Size uint32 `yaml:"size,omitempty"`
 }
 
 type FlashcardLatex struct {
@@ -11,8 +11,9 @@ type FlashcardLatex struct {
 }
 
 type FlashcardStyle struct {
-	CSS   string         `yaml:"css"`
-	Latex FlashcardLatex `yaml:"latex"`
+	CSS     string         `yaml:"css"`
+	Latex   FlashcardLatex `yaml:"latex"`
+	MathJax bool           `yaml:"mathjax,omitempty"`
 }
 
 type FlashcardTemplate struct {
@@ -25,15 +26,16 @@ type FlashcardField struct {
 	Name        string        `yaml:"name"`
 	Template    string        `yaml:"template"`
 	Format      string        `yaml:"format"`
-	Index       bool          `yaml:"index"`
-	RTL         bool          `yaml:"rtl"`
-	Font        FlashcardFont `yaml:"font"`
-	Description string        `yaml:"description"`
+	Index       bool          `yaml:"index,omitempty"`
+	RTL         bool          `yaml:"rtl,omitempty"`
+	Font        FlashcardFont `yaml:"font,omitempty"`
+	Description string        `yaml:"description,omitempty"`
 }
 
 type FlashcardData struct {
 	Filename string   `yaml:"filename"`
-	Tags     []string `yaml:"tags"`
+	Source   string   `yaml:"source,omitempty"`
+	Tags     []string `yaml:"tags,omitempty"`
 }
 
 type FlashcardDeck struct {
@@ -50,9 +52,28 @@ type FlashcardModel struct {
 	Fields     []FlashcardField    `yaml:"fields"`
 }
 
+type FlashcardCardSize struct {
+	Width  string `yaml:"width,omitempty"`
+	Height string `yaml:"height,omitempty"`
+}
+
+type FlashcardPrint struct {
+	Paper  string            `yaml:"paper,omitempty"`
+	Card   FlashcardCardSize `yaml:"card,omitempty"`
+	Margin string            `yaml:"margin,omitempty"`
+	Marks  string            `yaml:"marks,omitempty"`
+	Front  []string          `yaml:"front,omitempty"`
+	Back   []string          `yaml:"back,omitempty"`
+}
+
 type FlashcardProject struct {
 	Filename string          `yaml:"filename"`
 	Deck     FlashcardDeck   `yaml:"deck"`
 	Model    FlashcardModel  `yaml:"model"`
 	Data     []FlashcardData `yaml:"data"`
+	Print    FlashcardPrint  `yaml:"print,omitempty"`
+
+	// Directory is the directory holding the project file, set on load;
+	// media references in fields are resolved against it.
+	Directory string `yaml:"-"`
 }